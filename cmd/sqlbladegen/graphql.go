@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// graphQLField is a field of a model exposed to GraphQL
+type graphQLField struct {
+	field
+	goName   string // name of the filter input field
+	gqlName  string // name of the GraphQL field
+	goType   string // scalar Go type, without pointer
+	scalar   string // GraphQL scalar
+	nullable bool
+}
+
+// graphQLFields returns the fields of a model with a GraphQL scalar type;
+// JSON documents and other types are left out
+func graphQLFields(m model) []graphQLField {
+	var fields []graphQLField
+	for _, f := range m.fields {
+		if f.json {
+			continue
+		}
+		goType, scalar, nullable := graphQLScalar(f.typ)
+		if scalar == "" {
+			continue
+		}
+		fields = append(fields, graphQLField{
+			field:    f,
+			goName:   strings.ReplaceAll(f.name, ".", ""),
+			gqlName:  lowerCamel(f.column),
+			goType:   goType,
+			scalar:   scalar,
+			nullable: nullable,
+		})
+	}
+	return fields
+}
+
+// graphQLScalar returns the Go and GraphQL types of a scalar field type, or
+// empty strings for other types
+func graphQLScalar(expr ast.Expr) (goType, scalar string, nullable bool) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		goType, scalar, _ = graphQLScalar(t.X)
+		return goType, scalar, true
+	case *ast.Ident:
+		switch t.Name {
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+			return t.Name, "Int", false
+		case "float32", "float64":
+			return t.Name, "Float", false
+		case "string":
+			return t.Name, "String", false
+		case "bool":
+			return t.Name, "Boolean", false
+		}
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && pkg.Name == "time" && t.Sel.Name == "Time" {
+			return "time.Time", "Time", false
+		}
+	}
+	return "", "", false
+}
+
+// primaryKey returns the column ordering the connections of a model: its pk
+// column, or id
+func primaryKey(m model) (string, error) {
+	for _, f := range m.fields {
+		if f.pk {
+			return f.column, nil
+		}
+	}
+	for _, f := range m.fields {
+		if f.column == "id" {
+			return f.column, nil
+		}
+	}
+	return "", fmt.Errorf("%s: GraphQL connections need a pk or id column", m.name)
+}
+
+// generateGraphQL returns the formatted source of the GraphQL helpers of the
+// models, with their schema in the constant <schema>GraphQLSchema
+func generateGraphQL(pkg, schema string, models []model) ([]byte, error) {
+	var buf, sdl bytes.Buffer
+	scalars := make(map[string]bool)
+	usesTime := false
+
+	for _, m := range models {
+		pk, err := primaryKey(m)
+		if err != nil {
+			return nil, err
+		}
+		fields := graphQLFields(m)
+
+		fmt.Fprintf(&buf, "\n// %sFilter is the GraphQL filter input of %s; the conditions of its fields\n// are combined with AND\n", m.name, m.name)
+		fmt.Fprintf(&buf, "type %sFilter struct {\n", m.name)
+		for _, f := range fields {
+			fmt.Fprintf(&buf, "\t%s *sqlblade.FieldFilter[%s] `json:\"%s,omitempty\"`\n", f.goName, f.goType, f.gqlName)
+			scalars[f.scalar] = true
+			usesTime = usesTime || f.goType == "time.Time"
+		}
+		buf.WriteString("}\n")
+
+		fmt.Fprintf(&buf, "\n// Apply adds the conditions of the filter to q\n")
+		fmt.Fprintf(&buf, "func (f *%sFilter) Apply(q *sqlblade.QueryBuilder[%s]) *sqlblade.QueryBuilder[%s] {\n", m.name, m.name, m.name)
+		buf.WriteString("\tif f == nil {\n\t\treturn q\n\t}\n")
+		for _, f := range fields {
+			fmt.Fprintf(&buf, "\tsqlblade.FilterWhere(q, %s, f.%s)\n", strconv.Quote(f.column), f.goName)
+		}
+		buf.WriteString("\treturn q\n}\n")
+
+		fmt.Fprintf(&buf, "\n// Resolve%sConnection resolves a connection of the %s rows matching filter,\n// ordered by %s, in pages of 20 rows by default\n", m.name, m.name, pk)
+		fmt.Fprintf(&buf, "func Resolve%sConnection[C sqlblade.Conn](ctx context.Context, db C, filter *%sFilter, args sqlblade.ConnectionArgs) (*sqlblade.Connection[%s], error) {\n", m.name, m.name, m.name)
+		fmt.Fprintf(&buf, "\tq := sqlblade.Query[%s](db).OrderBy(%s, dialect.ASC)\n", m.name, strconv.Quote(pk))
+		buf.WriteString("\treturn sqlblade.Connect(ctx, filter.Apply(q), args, 20)\n}\n")
+
+		fmt.Fprintf(&sdl, "type %s {\n", m.name)
+		for _, f := range fields {
+			nonNull := "!"
+			if f.nullable {
+				nonNull = ""
+			}
+			fmt.Fprintf(&sdl, "  %s: %s%s\n", f.gqlName, f.scalar, nonNull)
+		}
+		sdl.WriteString("}\n\n")
+		fmt.Fprintf(&sdl, "input %sFilter {\n", m.name)
+		for _, f := range fields {
+			fmt.Fprintf(&sdl, "  %s: %sFilter\n", f.gqlName, f.scalar)
+		}
+		sdl.WriteString("}\n\n")
+		fmt.Fprintf(&sdl, "type %sEdge {\n  node: %s!\n  cursor: String!\n}\n\n", m.name, m.name)
+		fmt.Fprintf(&sdl, "type %sConnection {\n  edges: [%sEdge!]!\n  pageInfo: PageInfo!\n}\n\n", m.name, m.name)
+	}
+
+	names := make([]string, 0, len(scalars))
+	for scalar := range scalars {
+		names = append(names, scalar)
+	}
+	sort.Strings(names)
+	if scalars["Time"] {
+		sdl.WriteString("scalar Time\n\n")
+	}
+	for _, scalar := range names {
+		fmt.Fprintf(&sdl, "input %sFilter {\n", scalar)
+		for _, op := range []string{"eq", "ne", "lt", "lte", "gt", "gte"} {
+			fmt.Fprintf(&sdl, "  %s: %s\n", op, scalar)
+		}
+		fmt.Fprintf(&sdl, "  in: [%s!]\n  notIn: [%s!]\n", scalar, scalar)
+		if scalar == "String" {
+			sdl.WriteString("  like: String\n")
+		}
+		sdl.WriteString("  isNull: Boolean\n}\n\n")
+	}
+	sdl.WriteString("type PageInfo {\n  hasNextPage: Boolean!\n  hasPreviousPage: Boolean!\n  startCursor: String\n  endCursor: String\n}\n")
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by sqlbladegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", pkg)
+	out.WriteString("import (\n\t\"context\"\n")
+	if usesTime {
+		out.WriteString("\t\"time\"\n")
+	}
+	out.WriteString("\n\t\"github.com/alicanli1995/sqlblade/sqlblade\"\n\t\"github.com/alicanli1995/sqlblade/sqlblade/dialect\"\n)\n")
+	fmt.Fprintf(&out, "\n// %sGraphQLSchema is the GraphQL schema of the generated types and filter inputs\n", schema)
+	fmt.Fprintf(&out, "const %sGraphQLSchema = %s\n", schema, "`"+sdl.String()+"`")
+	out.Write(buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+// lowerCamel converts a snake_case column name into a lowerCamelCase
+// GraphQL field name
+func lowerCamel(column string) string {
+	parts := strings.Split(column, "_")
+	var buf strings.Builder
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		if i > 0 && buf.Len() > 0 {
+			part = strings.ToUpper(part[:1]) + part[1:]
+		}
+		buf.WriteString(part)
+	}
+	return buf.String()
+}
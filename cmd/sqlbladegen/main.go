@@ -0,0 +1,385 @@
+// Command sqlbladegen generates reflection-free scanning functions for
+// sqlblade models. Run it from go:generate in the package declaring them:
+//
+//	//go:generate go run github.com/alicanli1995/sqlblade/cmd/sqlbladegen -type User,Order
+//
+// For each type it emits Columns, Values and ScanRow functions and registers
+// them with sqlblade.RegisterGenerated, so rows are scanned without reflection.
+// It also emits a <Type>Columns variable holding the column name of each
+// field, for queries checked by the compiler: Where(UserColumns.Email, "=", v).
+// With -graphql it also emits GraphQL resolver helpers: a filter input struct
+// applied to queries, a Relay connection resolver and the GraphQL schema.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// model is a struct type to generate functions for
+type model struct {
+	name   string
+	fields []field
+}
+
+// field is a struct field mapped to a column by its db tag
+type field struct {
+	name   string
+	column string
+	json   bool // stored as a JSON document
+	pk     bool
+	typ    ast.Expr
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("sqlbladegen: ")
+
+	types := flag.String("type", "", "comma-separated list of struct types; required")
+	output := flag.String("output", "", "output file name; default <first type>_sqlblade.go")
+	dir := flag.String("dir", ".", "directory of the package declaring the types")
+	graphql := flag.Bool("graphql", false, "also generate GraphQL filter inputs, connection resolvers and schema into <first type>_graphql_sqlblade.go")
+	flag.Parse()
+
+	if *types == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	names := strings.Split(*types, ",")
+
+	pkg, models, err := parseModels(*dir, names)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := generate(pkg, models)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	name := *output
+	if name == "" {
+		name = strings.ToLower(names[0]) + "_sqlblade.go"
+	}
+	if err := os.WriteFile(filepath.Join(*dir, name), src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+
+	if *graphql {
+		src, err := generateGraphQL(pkg, strings.TrimSpace(names[0]), models)
+		if err != nil {
+			log.Fatal(err)
+		}
+		name := strings.ToLower(names[0]) + "_graphql_sqlblade.go"
+		if err := os.WriteFile(filepath.Join(*dir, name), src, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// parseModels finds the named struct types among the package's Go files
+func parseModels(dir string, names []string) (string, []model, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", nil, err
+	}
+
+	fset := token.NewFileSet()
+	pkg := ""
+	structs := make(map[string]*ast.StructType)
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") || strings.HasSuffix(path, "_sqlblade.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return "", nil, err
+		}
+		pkg = file.Name.Name
+		ast.Inspect(file, func(n ast.Node) bool {
+			if spec, ok := n.(*ast.TypeSpec); ok {
+				if st, ok := spec.Type.(*ast.StructType); ok {
+					structs[spec.Name.Name] = st
+				}
+			}
+			return true
+		})
+	}
+
+	models := make([]model, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		st, ok := structs[name]
+		if !ok {
+			return "", nil, fmt.Errorf("struct type %s not found in %s", name, dir)
+		}
+		fields, err := structFields(st, structs, "", "", map[string]bool{name: true})
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %w", name, err)
+		}
+		models = append(models, model{name: name, fields: fields})
+	}
+	return pkg, models, nil
+}
+
+// structFields returns the fields mapped to columns, following the rules of
+// sqlblade's reflection: exported fields with a db tag other than "-", the
+// column being the lower-cased first tag element. Embedded structs without a
+// column name and struct fields with the prefix option are traversed; their
+// types must be declared in the same package and not be pointers.
+func structFields(st *ast.StructType, structs map[string]*ast.StructType, prefix, path string, seen map[string]bool) ([]field, error) {
+	var fields []field
+	for _, f := range st.Fields.List {
+		dbTag, tagged := "", false
+		if f.Tag != nil {
+			tag, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				continue
+			}
+			dbTag, tagged = reflect.StructTag(tag).Lookup("db")
+		}
+		if dbTag == "-" {
+			continue
+		}
+		parts := strings.Split(dbTag, ",")
+		isJSON := hasOption(parts[1:], "json")
+		isPrefix := hasOption(parts[1:], "prefix")
+
+		if len(f.Names) == 0 && parts[0] == "" && !isJSON || isPrefix {
+			name := embeddedName(f.Type)
+			nested, ok := structs[typeName(f.Type)]
+			if !ok || name == nil {
+				if isPrefix || f.Tag != nil {
+					return nil, fmt.Errorf("field %s%s: struct type not declared in the package", path, exprName(f))
+				}
+				continue
+			}
+			if _, isPtr := f.Type.(*ast.StarExpr); isPtr {
+				return nil, fmt.Errorf("field %s%s: struct pointers are not supported; use a struct value", path, exprName(f))
+			}
+			if seen[name.Name] {
+				continue
+			}
+			seen[name.Name] = true
+			names := f.Names
+			if len(names) == 0 {
+				names = []*ast.Ident{name}
+			}
+			for _, n := range names {
+				nestedFields, err := structFields(nested, structs, prefix+parts[0], path+n.Name+".", seen)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, nestedFields...)
+			}
+			delete(seen, name.Name)
+			continue
+		}
+
+		if !tagged || dbTag == "" {
+			continue
+		}
+		column := strings.ToLower(prefix + parts[0])
+
+		names := f.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{embeddedName(f.Type)}
+		}
+		for _, name := range names {
+			if name == nil || !ast.IsExported(name.Name) {
+				continue
+			}
+			fields = append(fields, field{name: path + name.Name, column: column, json: isJSON, pk: hasOption(parts[1:], "pk"), typ: f.Type})
+		}
+	}
+	return dedupeFields(fields), nil
+}
+
+// dedupeFields drops fields whose column is also mapped by a less deeply
+// nested field, keeping the first of equally nested fields, as sqlblade does
+func dedupeFields(fields []field) []field {
+	depth := make(map[string]int, len(fields))
+	for _, f := range fields {
+		if d, ok := depth[f.column]; !ok || strings.Count(f.name, ".") < d {
+			depth[f.column] = strings.Count(f.name, ".")
+		}
+	}
+
+	result := fields[:0]
+	for _, f := range fields {
+		if d, ok := depth[f.column]; ok && strings.Count(f.name, ".") == d {
+			result = append(result, f)
+			delete(depth, f.column)
+		}
+	}
+	return result
+}
+
+// hasOption reports whether the options of a db tag include option
+func hasOption(options []string, option string) bool {
+	for _, opt := range options {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// typeName returns the name of a struct type declared in the package, or ""
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return typeName(t.X)
+	default:
+		return ""
+	}
+}
+
+// exprName returns the name of a field for error messages
+func exprName(f *ast.Field) string {
+	if len(f.Names) > 0 {
+		return f.Names[0].Name
+	}
+	if name := embeddedName(f.Type); name != nil {
+		return name.Name
+	}
+	return "?"
+}
+
+// embeddedName returns the field name of an embedded type
+func embeddedName(expr ast.Expr) *ast.Ident {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel
+	default:
+		return nil
+	}
+}
+
+// generate returns the formatted source of the generated file
+func generate(pkg string, models []model) ([]byte, error) {
+	sort.SliceStable(models, func(i, j int) bool { return models[i].name < models[j].name })
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by sqlbladegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintf(&buf, "import \"github.com/alicanli1995/sqlblade/sqlblade\"\n\n")
+
+	fmt.Fprintf(&buf, "func init() {\n")
+	for _, m := range models {
+		prefix := lowerFirst(m.name)
+		fmt.Fprintf(&buf, "\tsqlblade.RegisterGenerated(sqlblade.Generated[%s]{\n", m.name)
+		fmt.Fprintf(&buf, "\t\tColumns: %sColumns,\n", prefix)
+		fmt.Fprintf(&buf, "\t\tValues: %sValues,\n", prefix)
+		fmt.Fprintf(&buf, "\t\tScanRow: %sScanRow,\n", prefix)
+		fmt.Fprintf(&buf, "\t})\n")
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	for _, m := range models {
+		prefix := lowerFirst(m.name)
+
+		fmt.Fprintf(&buf, "\n// %sColumns returns the columns of %s in field order\n", prefix, m.name)
+		fmt.Fprintf(&buf, "func %sColumns() []string {\n\treturn []string{", prefix)
+		for i, f := range m.fields {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(strconv.Quote(f.column))
+		}
+		buf.WriteString("}\n}\n")
+
+		fmt.Fprintf(&buf, "\n// %sValues returns the field values of v in column order\n", prefix)
+		fmt.Fprintf(&buf, "func %sValues(v *%s) []interface{} {\n\treturn []interface{}{", prefix, m.name)
+		for i, f := range m.fields {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if f.json {
+				buf.WriteString("sqlblade.JSON(v." + f.name + ")")
+			} else {
+				buf.WriteString("v." + f.name)
+			}
+		}
+		buf.WriteString("}\n}\n")
+
+		fmt.Fprintf(&buf, "\n// %sScanRow stores pointers to the fields of v in dest, in column order\n", prefix)
+		fmt.Fprintf(&buf, "func %sScanRow(v *%s, dest []interface{}) {\n", prefix, m.name)
+		for i, f := range m.fields {
+			if f.json {
+				fmt.Fprintf(&buf, "\tdest[%d] = sqlblade.JSONDest(&v.%s)\n", i, f.name)
+			} else {
+				fmt.Fprintf(&buf, "\tdest[%d] = &v.%s\n", i, f.name)
+			}
+		}
+		buf.WriteString("}\n")
+
+		names := columnFieldNames(m.fields)
+		fmt.Fprintf(&buf, "\n// %sColumns holds the column names of the fields of %s, checked by the compiler\n", m.name, m.name)
+		fmt.Fprintf(&buf, "var %sColumns = struct {\n", m.name)
+		for _, name := range names {
+			fmt.Fprintf(&buf, "\t%s string\n", name)
+		}
+		buf.WriteString("}{\n")
+		for i, f := range m.fields {
+			fmt.Fprintf(&buf, "\t%s: %s,\n", names[i], strconv.Quote(f.column))
+		}
+		buf.WriteString("}\n")
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+// columnFieldNames returns the names of the fields of a generated column set:
+// the Go field names, prefixed with their parent fields when nested fields of
+// different structs share a name
+func columnFieldNames(fields []field) []string {
+	count := make(map[string]int, len(fields))
+	for _, f := range fields {
+		count[lastName(f.name)]++
+	}
+
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = lastName(f.name)
+		if count[names[i]] > 1 {
+			names[i] = strings.ReplaceAll(f.name, ".", "")
+		}
+	}
+	return names
+}
+
+// lastName returns the last element of a field path
+func lastName(path string) string {
+	return path[strings.LastIndex(path, ".")+1:]
+}
+
+// lowerFirst lower-cases the first letter of a type name
+func lowerFirst(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
@@ -142,8 +142,16 @@ func main() {
 	fmt.Println("\n=== 6. Custom Logging Hook ===")
 
 	// Add a custom hook for query logging
-	sqlblade.DefaultHooks.BeforeQuery(func(ctx context.Context, query string, args []interface{}) error {
-		fmt.Printf("🔍 Executing query: %s\n", query)
+	sqlblade.DefaultHooks.BeforeQuery(func(ctx context.Context, event *sqlblade.HookEvent) error {
+		fmt.Printf("🔍 Executing query: %s\n", event.SQL)
+		return nil
+	})
+
+	// Failed queries reach AfterQueryWithError hooks too, with timing for metrics
+	sqlblade.DefaultHooks.AfterQueryWithError(func(ctx context.Context, event *sqlblade.HookEvent) error {
+		if event.Err != nil {
+			fmt.Printf("❌ %s on %s failed after %v: %v\n", event.Operation, event.Table, event.Duration, event.Err)
+		}
 		return nil
 	})
 
@@ -78,15 +78,14 @@ func main() {
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	result, err := sqlblade.Insert(db, newPost).
+	inserted, err := sqlblade.Insert(db, newPost).
 		Returning("id").
-		Execute(ctx)
+		ExecuteReturning(ctx)
 	if err != nil {
 		log.Printf("Error: %v", err)
 	} else {
-		// In PostgreSQL, we can get the ID from the result
-		rowsAffected, _ := result.RowsAffected()
-		fmt.Printf("Inserted post, rows affected: %d\n", rowsAffected)
+		// The RETURNING columns are scanned back into the model
+		fmt.Printf("Inserted post with ID: %d\n", inserted[0].ID)
 	}
 
 	// Example 3: Batch INSERT
@@ -105,16 +104,18 @@ func main() {
 
 	// Example 4: UPDATE with RETURNING
 	fmt.Println("\n=== Example 4: UPDATE with RETURNING ===")
-	_, err = sqlblade.Update[Post](db).
+	updated, err := sqlblade.Update[Post](db).
 		Set("views", 0).
 		Set("updated_at", time.Now()).
 		Where("published", "=", false).
 		Returning("id", "title").
-		Execute(ctx)
+		ExecuteReturning(ctx)
 	if err != nil {
 		log.Printf("Error: %v", err)
 	} else {
-		fmt.Println("Updated posts successfully")
+		for _, post := range updated {
+			fmt.Printf("  - updated #%d %s\n", post.ID, post.Title)
+		}
 	}
 
 	// Example 5: JOIN with multiple tables
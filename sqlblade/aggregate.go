@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -30,13 +32,28 @@ func (qb *QueryBuilder[T]) Count(ctx context.Context) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
+	return countValue(val), nil
+}
+
+// countValue converts a scanned count to int64
+func countValue(val interface{}) int64 {
 	if i, ok := val.(int64); ok {
-		return i, nil
+		return i
 	}
 	if f, ok := val.(float64); ok {
-		return int64(f), nil
+		return int64(f)
 	}
-	return 0, nil
+	return 0
+}
+
+// CountDistinct executes a COUNT(DISTINCT column) query, counting the distinct
+// non-NULL values of a column
+func (qb *QueryBuilder[T]) CountDistinct(ctx context.Context, column string) (int64, error) {
+	val, err := qb.aggregateExpr(ctx, "COUNT(DISTINCT "+qb.dialect.QuoteIdentifier(column)+")")
+	if err != nil {
+		return 0, err
+	}
+	return countValue(val), nil
 }
 
 // Sum executes a SUM query
@@ -76,33 +93,214 @@ func (qb *QueryBuilder[T]) Max(ctx context.Context, column string) (interface{},
 	return qb.aggregate(ctx, Max, column)
 }
 
+// AggregateExpr is an aggregate computed by QueryBuilder.Aggregate, built
+// with Agg
+type AggregateExpr struct {
+	fn       AggregateFunc
+	column   string
+	alias    string
+	distinct bool
+}
+
+// Agg builds the aggregates of QueryBuilder.Aggregate, e.g. Agg.Count("*").As("n")
+var Agg aggregates
+
+type aggregates struct{}
+
+// Count counts the rows, with "*", or the non-NULL values of a column
+func (aggregates) Count(column string) AggregateExpr {
+	return AggregateExpr{fn: Count, column: column}
+}
+
+// Sum sums the values of a column
+func (aggregates) Sum(column string) AggregateExpr {
+	return AggregateExpr{fn: Sum, column: column}
+}
+
+// Avg averages the values of a column
+func (aggregates) Avg(column string) AggregateExpr {
+	return AggregateExpr{fn: Avg, column: column}
+}
+
+// Min returns the smallest value of a column
+func (aggregates) Min(column string) AggregateExpr {
+	return AggregateExpr{fn: Min, column: column}
+}
+
+// Max returns the largest value of a column
+func (aggregates) Max(column string) AggregateExpr {
+	return AggregateExpr{fn: Max, column: column}
+}
+
+// As names the result of the aggregate. The default name is the lowercased
+// function and column, e.g. "max_views" or "sum_distinct_amount", or "count"
+// for Count("*").
+func (a AggregateExpr) As(alias string) AggregateExpr {
+	a.alias = alias
+	return a
+}
+
+// Distinct applies the aggregate to the distinct values of the column, e.g.
+// Agg.Sum("amount").Distinct() for SUM(DISTINCT amount)
+func (a AggregateExpr) Distinct() AggregateExpr {
+	a.distinct = true
+	return a
+}
+
+// name returns the name of the result of the aggregate
+func (a AggregateExpr) name() string {
+	if a.alias != "" {
+		return a.alias
+	}
+	name := strings.ToLower(string(a.fn))
+	if a.distinct {
+		name += "_distinct"
+	}
+	if a.column == "*" {
+		return name
+	}
+	return name + "_" + strings.ReplaceAll(a.column, ".", "_")
+}
+
+// Aggregate computes several aggregates of the rows in one query and returns
+// them by name, e.g.
+//
+//	stats, err := sqlblade.Query[Post](db).Where("published", "=", true).Aggregate(ctx,
+//		sqlblade.Agg.Count("*").As("n"), sqlblade.Agg.Avg("views"), sqlblade.Agg.Max("views"))
+//
+// COUNT results are int64, SUM and AVG results float64 as with Sum and Avg,
+// and MIN and MAX results are normalized as by ExecuteMaps. SUM, AVG, MIN and
+// MAX of no rows are nil. Queries grouped with GroupBy give the aggregates of
+// the first group.
+func (qb *QueryBuilder[T]) Aggregate(ctx context.Context, aggs ...AggregateExpr) (map[string]interface{}, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+	if len(aggs) == 0 {
+		return nil, errNoAggregates
+	}
+
+	var row map[string]interface{}
+	sqlStr, args := qb.aggregateSQL(qb.aggregateList(aggs))
+	err := qb.query(ctx, sqlStr, args, func(rows *sql.Rows) error {
+		maps, err := scanMaps(rows, qb.client.timeLocation(ctx))
+		if err != nil {
+			return err
+		}
+		if len(maps) == 0 {
+			return fmt.Errorf("%w (table: %s)", ErrNoRows, qb.tableName)
+		}
+		row = maps[0]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, agg := range aggs {
+		name := agg.name()
+		switch agg.fn {
+		case Count:
+			if text, ok := row[name].(string); ok {
+				row[name], _ = strconv.ParseInt(text, 10, 64)
+			} else {
+				row[name] = countValue(row[name])
+			}
+		case Sum, Avg:
+			row[name] = floatValue(row[name])
+		}
+	}
+	return row, nil
+}
+
+// AggregateInto computes several aggregates of the rows in one query, see
+// Aggregate, and scans them into the fields of the struct pointed to by dest
+// whose db tags match their names
+func (qb *QueryBuilder[T]) AggregateInto(ctx context.Context, dest interface{}, aggs ...AggregateExpr) error {
+	if ctx == nil {
+		return ErrNilContext
+	}
+	if len(aggs) == 0 {
+		return errNoAggregates
+	}
+
+	sqlStr, args := qb.aggregateSQL(qb.aggregateList(aggs))
+	return qb.query(ctx, sqlStr, args, func(rows *sql.Rows) error {
+		return scanRowsInto(rows, dest, qb.client.timeLocation(ctx))
+	})
+}
+
+var errNoAggregates = errors.New("sqlblade: no aggregates to compute")
+
+// aggregateList returns the SQL selecting the aggregates under their names
+func (qb *QueryBuilder[T]) aggregateList(aggs []AggregateExpr) string {
+	exprs := make([]string, len(aggs))
+	for i, agg := range aggs {
+		column := agg.column
+		if column != "*" {
+			column = qb.dialect.QuoteIdentifier(column)
+		}
+		if agg.distinct {
+			column = "DISTINCT " + column
+		}
+		exprs[i] = string(agg.fn) + "(" + column + ") AS " + qb.dialect.QuoteIdentifier(agg.name())
+	}
+	return strings.Join(exprs, ", ")
+}
+
+// floatValue converts a normalized SUM or AVG result to float64, keeping nil
+func floatValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case int64:
+		return float64(v)
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return val
+}
+
 // aggregate executes an aggregate function
 func (qb *QueryBuilder[T]) aggregate(ctx context.Context, fn AggregateFunc, column string) (interface{}, error) {
+	if column != "*" {
+		column = qb.dialect.QuoteIdentifier(column)
+	}
+	return qb.aggregateExpr(ctx, string(fn)+"("+column+")")
+}
+
+// aggregateExpr executes a query selecting the aggregate expression expr
+func (qb *QueryBuilder[T]) aggregateExpr(ctx context.Context, expr string) (interface{}, error) {
 	if ctx == nil {
 		return nil, ErrNilContext
 	}
+	sqlStr, args := qb.aggregateSQL(expr)
+	return qb.runAggregate(ctx, sqlStr, args)
+}
 
+// aggregateSQL builds a query selecting the aggregate expressions exprs over
+// the rows of the query. DISTINCT queries are aggregated over their distinct
+// rows, e.g. Select("user_id").Distinct().Count(ctx) counts distinct users.
+func (qb *QueryBuilder[T]) aggregateSQL(exprs string) (string, []interface{}) {
 	var buf strings.Builder
 	paramIndex := 0
 	var args []interface{}
 
 	buf.WriteString("SELECT ")
-	buf.WriteString(string(fn))
-	buf.WriteString("(")
-	if column == "*" {
-		buf.WriteString("*")
-	} else {
-		buf.WriteString(qb.dialect.QuoteIdentifier(column))
-	}
-	buf.WriteString(")")
+	buf.WriteString(exprs)
 
 	buf.WriteString(" FROM ")
-	buf.WriteString(qb.dialect.QuoteIdentifier(qb.tableName))
-
-	for _, join := range qb.joins {
-		buf.WriteString(" ")
-		buf.WriteString(qb.dialect.BuildJoin(join))
+	if len(qb.setOps) > 0 || qb.distinct {
+		// Aggregate over the combined or distinct rows
+		buf.WriteString("(")
+		qb.writeSelect(&buf, "", &paramIndex, &args)
+		buf.WriteString(") AS ")
+		buf.WriteString(setOpAlias)
+		return buf.String(), args
 	}
+	buf.WriteString(qb.fromSQL())
+
+	qb.writeJoins(&buf, &paramIndex, &args)
 
 	whereSQL, whereArgs := buildWhereClause(qb.dialect, qb.whereClauses, &paramIndex)
 	if whereSQL != "" {
@@ -113,11 +311,7 @@ func (qb *QueryBuilder[T]) aggregate(ctx context.Context, fn AggregateFunc, colu
 
 	if len(qb.groupBy) > 0 {
 		buf.WriteString(" GROUP BY ")
-		quotedCols := make([]string, len(qb.groupBy))
-		for i, col := range qb.groupBy {
-			quotedCols[i] = qb.dialect.QuoteIdentifier(col)
-		}
-		buf.WriteString(strings.Join(quotedCols, ", "))
+		buf.WriteString(buildGroupBy(qb.dialect, qb.groupBy))
 	}
 
 	if len(qb.having) > 0 {
@@ -129,23 +323,136 @@ func (qb *QueryBuilder[T]) aggregate(ctx context.Context, fn AggregateFunc, colu
 		}
 	}
 
-	sqlStr := buf.String()
+	return buf.String(), args
+}
 
-	var row *sql.Row
-	if qb.tx != nil {
-		row = qb.tx.QueryRowContext(ctx, sqlStr, args...)
-	} else {
-		row = qb.db.QueryRowContext(ctx, sqlStr, args...)
+// runAggregate runs a statement selecting a single aggregate value
+func (qb *QueryBuilder[T]) runAggregate(ctx context.Context, sqlStr string, args []interface{}) (interface{}, error) {
+	if err := qb.validate(); err != nil {
+		return nil, err
 	}
-
 	var result interface{}
-	err := row.Scan(&result)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("%w (table: %s)", ErrNoRows, qb.tableName)
+	stmt := qb.statement(sqlStr, args)
+	err := qb.client.queryHooked(ctx, qb.tx, stmt, func(rows *sql.Rows) error {
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return wrapQueryError(err, stmt.SQL, stmt.Args)
+			}
+			return fmt.Errorf("%w (table: %s)", ErrNoRows, qb.tableName)
 		}
-		return nil, wrapQueryError(err, sqlStr, args)
+		if err := rows.Scan(&result); err != nil {
+			return wrapQueryError(err, stmt.SQL, stmt.Args)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return result, nil
 }
+
+// StringAgg executes a query concatenating the non-NULL values of a column
+// with sep: STRING_AGG on PostgreSQL, GROUP_CONCAT on MySQL and SQLite. The
+// order of the values is unspecified, and no rows give "". MySQL truncates
+// the result to group_concat_max_len bytes, 1024 by default.
+func (qb *QueryBuilder[T]) StringAgg(ctx context.Context, column, sep string) (string, error) {
+	col, literal := qb.dialect.QuoteIdentifier(column), qb.dialect.EscapeString(sep)
+	var expr string
+	switch qb.dialect.Name() {
+	case dialectPostgres:
+		expr = "STRING_AGG(CAST(" + col + " AS text), " + literal + ")"
+	case dialectMySQL:
+		expr = "GROUP_CONCAT(" + col + " SEPARATOR " + literal + ")"
+	default:
+		expr = "GROUP_CONCAT(" + col + ", " + literal + ")"
+	}
+
+	val, err := qb.aggregateExpr(ctx, expr)
+	if err != nil || val == nil {
+		return "", err
+	}
+	if text, ok := textValue(reflect.ValueOf(val)); ok {
+		return text, nil
+	}
+	return fmt.Sprint(val), nil
+}
+
+// ArrayAgg executes a query collecting the values of a column with
+// PostgreSQL's ARRAY_AGG and returns them as V, e.g.
+// ArrayAgg[int64](ctx, sqlblade.Query[Order](db).Where("user_id", "=", id), "id").
+// NULL values need a pointer V. Other dialects give ErrDialectUnsupported.
+func ArrayAgg[V any, T any](ctx context.Context, qb *QueryBuilder[T], column string) ([]V, error) {
+	if qb.dialect.Name() != dialectPostgres {
+		return nil, fmt.Errorf("%w: ARRAY_AGG needs PostgreSQL", ErrDialectUnsupported)
+	}
+	val, err := qb.aggregateExpr(ctx, "ARRAY_AGG("+qb.dialect.QuoteIdentifier(column)+")")
+	if err != nil || val == nil {
+		return nil, err
+	}
+	text, ok := textValue(reflect.ValueOf(val))
+	if !ok {
+		return nil, fmt.Errorf("sqlblade: cannot convert %T to an array", val)
+	}
+	elems, err := parsePostgresArray(text)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]V, len(elems))
+	for i, elem := range elems {
+		field := reflect.ValueOf(&values[i]).Elem()
+		var src interface{}
+		if elem != nil {
+			src = *elem
+		}
+		if err := setFieldValue(field, src, baseType(field.Type())); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// parsePostgresArray parses the text of a one-dimensional PostgreSQL array,
+// e.g. {1,NULL,"a b"}, returning nil for NULL elements
+func parsePostgresArray(text string) ([]*string, error) {
+	if len(text) < 2 || text[0] != '{' || text[len(text)-1] != '}' {
+		return nil, fmt.Errorf("sqlblade: invalid array %q", text)
+	}
+	body := text[1 : len(text)-1]
+	if body == "" {
+		return []*string{}, nil
+	}
+
+	var elems []*string
+	for i := 0; i <= len(body); i++ {
+		var elem strings.Builder
+		quoted := i < len(body) && body[i] == '"'
+		if quoted {
+			for i++; i < len(body) && body[i] != '"'; i++ {
+				if body[i] == '\\' && i+1 < len(body) {
+					i++
+				}
+				elem.WriteByte(body[i])
+			}
+			i++
+		} else {
+			for ; i < len(body) && body[i] != ','; i++ {
+				if body[i] == '{' {
+					return nil, fmt.Errorf("sqlblade: multidimensional array %q", text)
+				}
+				elem.WriteByte(body[i])
+			}
+		}
+		if i < len(body) && body[i] != ',' {
+			return nil, fmt.Errorf("sqlblade: invalid array %q", text)
+		}
+		value := elem.String()
+		if !quoted && strings.EqualFold(value, "NULL") {
+			elems = append(elems, nil)
+		} else {
+			elems = append(elems, &value)
+		}
+	}
+	return elems, nil
+}
@@ -0,0 +1,345 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// columnAlias is a column of a model being renamed by a migration
+type columnAlias struct {
+	oldName string
+	newName string
+	renamed atomic.Bool // the database already has the new name
+}
+
+// current returns the name of the column in the database
+func (a *columnAlias) current() string {
+	if a.renamed.Load() {
+		return a.newName
+	}
+	return a.oldName
+}
+
+// matches reports whether name is either name of the column
+func (a *columnAlias) matches(name string) bool {
+	return strings.EqualFold(name, a.oldName) || strings.EqualFold(name, a.newName)
+}
+
+// WithColumnAlias declares that a migration renames the column oldName of the
+// model to newName, so the application keeps working whether it is deployed
+// before or after the migration runs. The struct tag may use either name.
+//
+// The builders write the name the database currently has wherever the model's
+// table refers to the column, unqualified or qualified with the table name,
+// and both names are scanned into the field. Until SetColumnRenamed or
+// Client.ResolveColumnAliases report otherwise, the database is assumed to
+// have the old name. Raw SQL is not rewritten. Remove the option once the
+// migration has run everywhere.
+func WithColumnAlias(oldName, newName string) ModelOption {
+	return func(m *ModelMeta) {
+		m.aliases = append(m.aliases, &columnAlias{oldName: oldName, newName: newName})
+	}
+}
+
+// ColumnAliases returns the columns of the model being renamed, old names to new names
+func (m *ModelMeta) ColumnAliases() map[string]string {
+	aliases := make(map[string]string, len(m.aliases))
+	for _, alias := range m.aliases {
+		aliases[alias.oldName] = alias.newName
+	}
+	return aliases
+}
+
+// SetColumnRenamed records whether the migration renaming the column oldName
+// has run. It takes effect for the statements built afterwards.
+func (m *ModelMeta) SetColumnRenamed(oldName string, renamed bool) error {
+	for _, alias := range m.aliases {
+		if alias.oldName == oldName {
+			alias.renamed.Store(renamed)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no alias for %s on %s", ErrInvalidColumn, oldName, m.Table)
+}
+
+// ResolveColumnAliases looks up which name the database has for the columns
+// of the registered models declared with WithColumnAlias, and records it as
+// SetColumnRenamed does. Call it at startup and after running migrations.
+func (c *Client) ResolveColumnAliases(ctx context.Context) error {
+	for _, meta := range RegisteredModels() {
+		if len(meta.aliases) == 0 {
+			continue
+		}
+
+		columns, err := c.tableColumns(ctx, meta.Table)
+		if err != nil {
+			return err
+		}
+		for _, alias := range meta.aliases {
+			switch {
+			case containsFold(columns, alias.newName):
+				alias.renamed.Store(true)
+			case containsFold(columns, alias.oldName):
+				alias.renamed.Store(false)
+			default:
+				return fmt.Errorf("%w: neither %s nor %s exists on %s", ErrInvalidColumn, alias.oldName, alias.newName, meta.Table)
+			}
+		}
+	}
+	return nil
+}
+
+// AliasFallback describes a SELECT that failed on a missing column and
+// succeeded when retried with the other names of the model's aliased columns
+type AliasFallback struct {
+	Table   string
+	SQL     string          // the failed statement
+	Retry   string          // the statement that succeeded
+	Err     error           // the error of the failed statement
+	Renamed map[string]bool // old names of the aliased columns, true when the database has the new name
+}
+
+// WithAliasFallback retries SELECTs of models declared with WithColumnAlias
+// that fail because a column doesn't exist, swapping the old and new names of
+// their aliased columns, for the window between a migration and the next
+// ResolveColumnAliases. When the retry succeeds the swapped names are
+// recorded for the statements built afterwards, and report, if not nil, is
+// called. Statements in transactions are not retried, as the failure aborts
+// PostgreSQL transactions.
+func WithAliasFallback(report func(ctx context.Context, event AliasFallback)) ClientOption {
+	return func(c *Client) {
+		if report == nil {
+			report = func(context.Context, AliasFallback) {}
+		}
+		c.aliasFallback = report
+	}
+}
+
+// retryAliased runs a SELECT that failed with err again with the names of
+// the aliased columns swapped, when the client falls back on aliases. It
+// returns err unchanged when the statement is not retried.
+func (c *Client) retryAliased(ctx context.Context, d dialect.Dialect, table string, stmt *Statement, err error) (*sql.Rows, error) {
+	aliased, ok := unwrapAliasDialect(d)
+	if c.aliasFallback == nil || !ok || !isUndefinedColumnError(err) {
+		return nil, err
+	}
+
+	retry := aliased.swap(stmt.SQL)
+	if retry == stmt.SQL {
+		return nil, err
+	}
+	rows, retryErr := c.queryContext(ctx, nil, true, retry, stmt.Args)
+	if retryErr != nil {
+		return nil, err
+	}
+
+	renamed := aliased.record(retry)
+	c.aliasFallback(ctx, AliasFallback{Table: table, SQL: stmt.SQL, Retry: retry, Err: err, Renamed: renamed})
+	stmt.SQL = retry
+	return rows, nil
+}
+
+// isUndefinedColumnError reports whether err is the error of PostgreSQL,
+// MySQL or SQLite for a column that doesn't exist
+func isUndefinedColumnError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return (strings.Contains(msg, "column") && strings.Contains(msg, "does not exist")) ||
+		strings.Contains(msg, "unknown column") ||
+		strings.Contains(msg, "no such column")
+}
+
+// tableColumns returns the column names of a table, from an empty result
+func (c *Client) tableColumns(ctx context.Context, table string) ([]string, error) {
+	sqlStr := "SELECT * FROM " + c.dialect.QuoteIdentifier(table) + " WHERE 1 = 0"
+	rows, err := c.db.QueryContext(ctx, sqlStr)
+	if err != nil {
+		return nil, wrapQueryError(err, sqlStr, nil)
+	}
+	defer rows.Close()
+	return rows.Columns()
+}
+
+// containsFold reports whether names contains name, ignoring case
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// modelAliases returns the column aliases of a registered model
+func modelAliases(typ reflect.Type) []*columnAlias {
+	if meta := registeredModel(typ); meta != nil {
+		return meta.aliases
+	}
+	return nil
+}
+
+// aliasedColumns returns the columns of a result set with the names of
+// aliased columns replaced by the name the model maps
+func aliasedColumns(typ reflect.Type, info *structInfo, columns []string) []string {
+	aliases := modelAliases(typ)
+	if len(aliases) == 0 {
+		return columns
+	}
+
+	var renamed []string
+	for i, col := range columns {
+		for _, alias := range aliases {
+			if !alias.matches(col) {
+				continue
+			}
+			for _, field := range info.fields {
+				if alias.matches(field.dbColumn) && !strings.EqualFold(field.dbColumn, col) {
+					if renamed == nil {
+						renamed = append([]string(nil), columns...)
+					}
+					renamed[i] = field.dbColumn
+				}
+			}
+		}
+	}
+	if renamed == nil {
+		return columns
+	}
+	return renamed
+}
+
+// withColumnAliases returns d translating the aliased columns of the model
+// typ into their current names, or d itself for models without aliases
+func withColumnAliases(d dialect.Dialect, typ reflect.Type, table string) dialect.Dialect {
+	aliases := modelAliases(typ)
+	if len(aliases) == 0 {
+		return d
+	}
+	aliased := aliasDialect{Dialect: d, table: table, aliases: aliases}
+	if binder, ok := d.(dialect.LimitOffsetBinder); ok {
+		return aliasBinderDialect{aliasDialect: aliased, binder: binder}
+	}
+	return aliased
+}
+
+// aliasDialect is a dialect quoting the aliased columns of a table under
+// their current names
+type aliasDialect struct {
+	dialect.Dialect
+	table   string
+	aliases []*columnAlias
+}
+
+// QuoteIdentifier quotes an identifier, renaming aliased columns
+func (d aliasDialect) QuoteIdentifier(identifier string) string {
+	return d.Dialect.QuoteIdentifier(d.column(identifier))
+}
+
+// BuildOrderBy builds ORDER BY clause, renaming aliased columns
+func (d aliasDialect) BuildOrderBy(orderBy []dialect.OrderBy) string {
+	renamed := make([]dialect.OrderBy, len(orderBy))
+	for i, ob := range orderBy {
+		if !ob.Raw {
+			ob.Column = d.column(ob.Column)
+		}
+		renamed[i] = ob
+	}
+	return d.Dialect.BuildOrderBy(renamed)
+}
+
+// Capabilities returns the capabilities of the wrapped dialect
+func (d aliasDialect) Capabilities() dialect.Capabilities {
+	return dialect.CapabilitiesOf(d.Dialect)
+}
+
+// column returns the current name of a column, unqualified or qualified with
+// the table name
+func (d aliasDialect) column(identifier string) string {
+	qualifier, name := "", identifier
+	if i := strings.LastIndexByte(identifier, '.'); i >= 0 {
+		qualifier, name = identifier[:i+1], identifier[i+1:]
+		if qualifier != d.table+"." {
+			return identifier
+		}
+	}
+	for _, alias := range d.aliases {
+		if alias.matches(name) {
+			return qualifier + alias.current()
+		}
+	}
+	return identifier
+}
+
+// key returns the current names of the aliased columns, so that cached SQL is
+// not reused across a rename
+func (d aliasDialect) key() string {
+	var buf strings.Builder
+	for _, alias := range d.aliases {
+		buf.WriteString(alias.current())
+		buf.WriteByte(',')
+	}
+	return buf.String()
+}
+
+// swap returns sqlStr with the quoted names of the aliased columns swapped
+func (d aliasDialect) swap(sqlStr string) string {
+	pairs := make([]string, 0, 4*len(d.aliases))
+	for _, alias := range d.aliases {
+		quotedOld := d.Dialect.QuoteIdentifier(alias.oldName)
+		quotedNew := d.Dialect.QuoteIdentifier(alias.newName)
+		pairs = append(pairs, quotedOld, quotedNew, quotedNew, quotedOld)
+	}
+	return strings.NewReplacer(pairs...).Replace(sqlStr)
+}
+
+// record records the names of the aliased columns used by sqlStr as their
+// current names, returning them as AliasFallback.Renamed
+func (d aliasDialect) record(sqlStr string) map[string]bool {
+	renamed := make(map[string]bool, len(d.aliases))
+	for _, alias := range d.aliases {
+		switch {
+		case strings.Contains(sqlStr, d.Dialect.QuoteIdentifier(alias.newName)):
+			alias.renamed.Store(true)
+		case strings.Contains(sqlStr, d.Dialect.QuoteIdentifier(alias.oldName)):
+			alias.renamed.Store(false)
+		}
+		renamed[alias.oldName] = alias.renamed.Load()
+	}
+	return renamed
+}
+
+// aliasBinderDialect is an aliasDialect for dialects binding LIMIT and OFFSET
+type aliasBinderDialect struct {
+	aliasDialect
+	binder dialect.LimitOffsetBinder
+}
+
+// BuildLimitOffsetParams builds LIMIT and OFFSET clauses with placeholders
+func (d aliasBinderDialect) BuildLimitOffsetParams(limit, offset *int, paramIndex *int) (string, []interface{}) {
+	return d.binder.BuildLimitOffsetParams(limit, offset, paramIndex)
+}
+
+// unwrapAliasDialect returns the aliasDialect of a builder's dialect, if any
+func unwrapAliasDialect(d dialect.Dialect) (aliasDialect, bool) {
+	switch d := d.(type) {
+	case aliasDialect:
+		return d, true
+	case aliasBinderDialect:
+		return d.aliasDialect, true
+	default:
+		return aliasDialect{}, false
+	}
+}
+
+// aliasKey returns the part of the SQL cache key depending on column aliases
+func aliasKey(d dialect.Dialect) string {
+	if aliased, ok := unwrapAliasDialect(d); ok {
+		return aliased.key()
+	}
+	return ""
+}
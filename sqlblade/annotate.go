@@ -0,0 +1,94 @@
+package sqlblade
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// AnnotateFunc returns key/value pairs describing who issued a statement, e.g.
+// the user ID or job name. They are appended to the SQL as a comment so DBAs
+// can attribute load in server logs.
+type AnnotateFunc func(ctx context.Context) map[string]string
+
+type annotationsKey struct{}
+
+// WithAnnotation returns a context carrying an annotation for statements
+// executed with it
+func WithAnnotation(ctx context.Context, key, value string) context.Context {
+	existing := AnnotationsFromContext(ctx)
+	annotations := make(map[string]string, len(existing)+1)
+	for k, v := range existing {
+		annotations[k] = v
+	}
+	annotations[key] = value
+	return context.WithValue(ctx, annotationsKey{}, annotations)
+}
+
+// AnnotationsFromContext returns the annotations stored with WithAnnotation.
+// It is the default AnnotateFunc.
+func AnnotationsFromContext(ctx context.Context) map[string]string {
+	annotations, _ := ctx.Value(annotationsKey{}).(map[string]string)
+	return annotations
+}
+
+// SetAnnotator enables SQL comment annotations for the client's statements.
+// A nil fn uses AnnotationsFromContext. Statements served from the prepared
+// statement cache are not annotated, since the cache key is the bare SQL.
+func (c *Client) SetAnnotator(fn AnnotateFunc) {
+	if fn == nil {
+		fn = AnnotationsFromContext
+	}
+	c.annotator = fn
+}
+
+// annotate appends the annotation comment for ctx to the SQL, if enabled
+func (c *Client) annotate(ctx context.Context, sqlStr string) string {
+	if c == nil || c.annotator == nil {
+		return sqlStr
+	}
+
+	comment := formatAnnotations(c.annotator(ctx))
+	if comment == "" {
+		return sqlStr
+	}
+	return sqlStr + " " + comment
+}
+
+// formatAnnotations formats annotations as a sqlcommenter style comment. Keys
+// are restricted to a safe character set and values are URL-encoded, so the
+// comment can't be terminated early.
+func formatAnnotations(annotations map[string]string) string {
+	if len(annotations) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		if sanitized := sanitizeAnnotationKey(k); sanitized != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = sanitizeAnnotationKey(k) + "='" + url.QueryEscape(annotations[k]) + "'"
+	}
+	return "/*" + strings.Join(parts, ",") + "*/"
+}
+
+func sanitizeAnnotationKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		default:
+			return -1
+		}
+	}, key)
+}
@@ -0,0 +1,62 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"sync"
+)
+
+// hllSupport caches per database whether the PostgreSQL hll extension is installed
+var hllSupport sync.Map // map[*sql.DB]bool
+
+// ApproxCountDistinct estimates the number of distinct non-NULL values of a
+// column, for analytics on tables too large for an exact count. It uses a
+// HyperLogLog sketch where the PostgreSQL hll extension is installed, and
+// falls back to an exact COUNT(DISTINCT ...) elsewhere.
+func (qb *QueryBuilder[T]) ApproxCountDistinct(ctx context.Context, column string) (int64, error) {
+	if ctx == nil {
+		return 0, ErrNilContext
+	}
+
+	col := qb.dialect.QuoteIdentifier(column)
+	expr := "COUNT(DISTINCT " + col + ")"
+	if qb.client.hasHLL(ctx, qb.tx) {
+		expr = "COALESCE(hll_cardinality(hll_add_agg(hll_hash_any(" + col + "))), 0)"
+	}
+
+	val, err := qb.aggregateExpr(ctx, expr)
+	if err != nil {
+		return 0, err
+	}
+	if f, ok := val.(float64); ok {
+		return int64(math.Round(f)), nil
+	}
+	return countValue(val), nil
+}
+
+// hasHLL reports whether the hll extension is installed in the client's
+// PostgreSQL database. The answer is cached per database; failed lookups are
+// retried on the next call.
+func (c *Client) hasHLL(ctx context.Context, tx *sql.Tx) bool {
+	if c.dialect.Name() != dialectPostgres {
+		return false
+	}
+	if installed, ok := hllSupport.Load(c.db); ok {
+		return installed.(bool)
+	}
+
+	const sqlStr = "SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'hll')"
+	var installed bool
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, sqlStr).Scan(&installed)
+	} else {
+		err = c.db.QueryRowContext(ctx, sqlStr).Scan(&installed)
+	}
+	if err != nil {
+		return false
+	}
+	hllSupport.Store(c.db, installed)
+	return installed
+}
@@ -0,0 +1,221 @@
+package sqlblade
+
+import (
+	"strings"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// QueryAST is the structure of a SELECT built by a QueryBuilder, for tools
+// that analyze, rewrite or translate queries without parsing SQL
+type QueryAST struct {
+	Dialect  string
+	Table    string
+	Alias    string
+	Distinct bool
+	Columns  []string // selected columns; empty for *
+	Exprs    []string // expressions selected with SelectExpr, with ? placeholders
+	Windows  []WindowColumn
+	Joins    []dialect.Join // derived tables of JoinSubquery have no Table
+	Where    []Condition
+	GroupBy  []GroupByExpr
+	Having   []Condition
+	OrderBy  []dialect.OrderBy
+	Limit    *int
+	Offset   *int
+	Lock     dialect.Lock
+	SetOps   []SetOperation // queries combined by UNION, INTERSECT or EXCEPT
+	SQL      string         // the rendered statement
+	Args     []interface{}  // the arguments of SQL, in placeholder order
+}
+
+// SetOperation is a query combined with another by a set operation
+type SetOperation struct {
+	Kind  string // UNION, UNION ALL, INTERSECT or EXCEPT
+	Query *QueryAST
+}
+
+// WindowColumn is a window function selected by a query
+type WindowColumn struct {
+	Function    string
+	PartitionBy []string
+	OrderBy     []dialect.OrderBy
+	Frame       string
+	Alias       string
+}
+
+// ConditionKind is the kind of a Condition
+type ConditionKind int
+
+const (
+	// CompareCondition compares a column with values, another column or a subquery
+	CompareCondition ConditionKind = iota
+	// GroupCondition is a parenthesized group of conditions
+	GroupCondition
+	// RawCondition is a raw SQL predicate
+	RawCondition
+)
+
+// String returns the name of the kind
+func (k ConditionKind) String() string {
+	switch k {
+	case CompareCondition:
+		return "compare"
+	case GroupCondition:
+		return "group"
+	case RawCondition:
+		return "raw"
+	default:
+		return "unknown"
+	}
+}
+
+// Condition is a node of a WHERE or HAVING clause
+type Condition struct {
+	Kind ConditionKind
+	Or   bool // joined to the previous condition with OR instead of AND
+
+	Column      string        // CompareCondition
+	Operator    string        // CompareCondition, upper-cased
+	Values      []interface{} // bound values: one, two for BETWEEN, the list for IN, a subquery's arguments
+	OtherColumn string        // compared column, for WhereColumn and correlated references
+	Subquery    string        // SQL of a subquery, with the dialect's placeholders
+
+	SQL      string      // RawCondition, or the Expr compared by CompareCondition, with ? placeholders; its arguments are in Values
+	Children []Condition // GroupCondition
+	Not      bool        // GroupCondition, negated with NOT
+}
+
+// GroupByExpr is a GROUP BY item: a column, or a raw SQL expression
+type GroupByExpr struct {
+	Expr string
+	Raw  bool
+}
+
+// AST returns the structure of the query. Its slices are copies, so changes
+// to them don't affect the builder.
+func (qb *QueryBuilder[T]) AST() *QueryAST {
+	sqlStr, args := qb.buildSQL()
+
+	ast := &QueryAST{
+		Dialect:  qb.dialect.Name(),
+		Table:    qb.tableName,
+		Alias:    qb.alias,
+		Distinct: qb.distinct,
+		Columns:  append([]string(nil), qb.selectCols...),
+		Joins:    append([]dialect.Join(nil), qb.joins...),
+		Where:    astConditions(qb.dialect, qb.whereClauses),
+		Having:   astConditions(qb.dialect, qb.having),
+		OrderBy:  append([]dialect.OrderBy(nil), qb.orderBy...),
+		Limit:    copyInt(qb.limit),
+		Offset:   copyInt(qb.offset),
+		Lock:     qb.lock,
+		SQL:      sqlStr,
+		Args:     args,
+	}
+	if len(ast.Columns) == 0 && qb.selectMapped {
+		ast.Columns = qb.mappedColumns()
+	}
+	for _, expr := range qb.selectExprs {
+		ast.Exprs = append(ast.Exprs, expr.sql)
+	}
+	for _, win := range qb.windows {
+		ast.Windows = append(ast.Windows, WindowColumn{
+			Function:    win.fn,
+			PartitionBy: append([]string(nil), win.over.partitionBy...),
+			OrderBy:     append([]dialect.OrderBy(nil), win.over.orderBy...),
+			Frame:       win.over.frame,
+			Alias:       win.alias,
+		})
+	}
+	for _, item := range qb.groupBy {
+		ast.GroupBy = append(ast.GroupBy, GroupByExpr{Expr: item.expr, Raw: item.raw})
+	}
+	for _, op := range qb.setOps {
+		ast.SetOps = append(ast.SetOps, SetOperation{Kind: op.kind, Query: op.query.AST()})
+	}
+	return ast
+}
+
+// WalkConditions calls fn for every condition of the WHERE and HAVING
+// clauses, depth first, parents before their children. Returning false from
+// fn skips the children of a group.
+func (a *QueryAST) WalkConditions(fn func(c *Condition) bool) {
+	walkConditions(a.Where, fn)
+	walkConditions(a.Having, fn)
+}
+
+func walkConditions(conditions []Condition, fn func(c *Condition) bool) {
+	for i := range conditions {
+		if fn(&conditions[i]) {
+			walkConditions(conditions[i].Children, fn)
+		}
+	}
+}
+
+// astConditions converts clauses into conditions, with the values that
+// buildConditions binds for them
+func astConditions(d dialect.Dialect, clauses []WhereClause) []Condition {
+	if len(clauses) == 0 {
+		return nil
+	}
+	conditions := make([]Condition, 0, len(clauses))
+	for i, clause := range clauses {
+		c := astCondition(d, clause)
+		c.Or = i > 0 && !clause.And
+		conditions = append(conditions, c)
+	}
+	return conditions
+}
+
+// astCondition converts a single clause, as buildCondition renders it
+func astCondition(d dialect.Dialect, clause WhereClause) Condition {
+	if group, ok := clause.Value.(*ConditionGroup); ok {
+		return Condition{Kind: GroupCondition, Children: astConditions(d, group.clauses), Not: group.not}
+	}
+	if raw, ok := clause.Value.(rawExpr); ok {
+		return Condition{Kind: RawCondition, SQL: raw.sql, Values: append([]interface{}(nil), raw.args...)}
+	}
+
+	c := Condition{
+		Kind:     CompareCondition,
+		Column:   clause.Column,
+		Operator: strings.ToUpper(strings.TrimSpace(clause.Operator)),
+	}
+	switch c.Operator {
+	case "IS NULL", "IS NOT NULL":
+	case "IN", "NOT IN":
+		if values, ok := inValues(clause.Value); ok {
+			c.Values = normalizeInList(d, values)
+		} else if subquery, ok := clause.Value.(*Subquery); ok {
+			c.Subquery = subquery.sql
+			c.Values = subquery.Args()
+		}
+	case "BETWEEN", "NOT BETWEEN":
+		if values, ok := clause.Value.([]interface{}); ok && len(values) == 2 {
+			c.Values = []interface{}{values[0], values[1]}
+		}
+	default:
+		if ref, ok := clause.Value.(ColumnRef); ok {
+			c.OtherColumn = string(ref)
+		} else if subquery, ok := clause.Value.(*Subquery); ok {
+			c.Subquery = subquery.sql
+			c.Values = subquery.Args()
+		} else if expr, ok := clause.Value.(Expression); ok {
+			c.SQL = expr.sql
+			c.Values = append([]interface{}(nil), expr.args...)
+		} else {
+			c.Values = []interface{}{clause.Value}
+		}
+	}
+	return c
+}
+
+// copyInt returns a copy of an optional int
+func copyInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	n := *v
+	return &n
+}
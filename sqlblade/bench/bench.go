@@ -0,0 +1,340 @@
+// Package bench provides a runtime load testing harness for SQLBlade queries.
+//
+// Unlike the go test benchmarks shipped with the repository, bench is meant to
+// be called from application code so users can compare dialects, drivers and
+// connection pool settings against their own databases:
+//
+//	report, err := bench.Run(ctx, bench.Workload{
+//	    bench.Select("active_users", 8, sqlblade.Query[User](db).Where("active", "=", true).Limit(50)),
+//	    bench.Count("orders_today", 2, sqlblade.Query[Order](db).Where("created_at", ">", today)),
+//	}, bench.Options{QPS: 200, Duration: 30 * time.Second})
+package bench
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+)
+
+var (
+	// ErrEmptyWorkload is returned when a workload has no queries with a positive weight
+	ErrEmptyWorkload = errors.New("sqlblade/bench: empty workload")
+
+	// ErrInvalidOptions is returned when QPS or Duration are not positive
+	ErrInvalidOptions = errors.New("sqlblade/bench: QPS and Duration must be positive")
+)
+
+// Query is a single weighted entry of a workload
+type Query struct {
+	Name   string
+	Weight int
+	Run    func(ctx context.Context) error
+}
+
+// Workload is a weighted set of queries to run
+type Workload []Query
+
+// Select wraps a query builder so that each run executes it as a SELECT
+func Select[T any](name string, weight int, qb *sqlblade.QueryBuilder[T]) Query {
+	return Query{
+		Name:   name,
+		Weight: weight,
+		Run: func(ctx context.Context) error {
+			_, err := qb.Execute(ctx)
+			return err
+		},
+	}
+}
+
+// Count wraps a query builder so that each run executes it as a COUNT
+func Count[T any](name string, weight int, qb *sqlblade.QueryBuilder[T]) Query {
+	return Query{
+		Name:   name,
+		Weight: weight,
+		Run: func(ctx context.Context) error {
+			_, err := qb.Count(ctx)
+			return err
+		},
+	}
+}
+
+// Func wraps an arbitrary function, e.g. an INSERT or a transaction
+func Func(name string, weight int, fn func(ctx context.Context) error) Query {
+	return Query{Name: name, Weight: weight, Run: fn}
+}
+
+// Options configures a load test run
+type Options struct {
+	// QPS is the target number of queries started per second
+	QPS int
+	// Duration is how long queries are scheduled for
+	Duration time.Duration
+	// Concurrency caps the number of in-flight queries (defaults to QPS)
+	Concurrency int
+	// Warmup runs the workload for this long before measurements start
+	Warmup time.Duration
+}
+
+// Latency holds latency percentiles of a set of samples
+type Latency struct {
+	Min  time.Duration
+	Mean time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	P95  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+}
+
+// QueryStats contains the results for a single workload entry
+type QueryStats struct {
+	Name    string
+	Count   int64
+	Errors  int64
+	Latency Latency
+}
+
+// Report contains the results of a load test run
+type Report struct {
+	Duration   time.Duration
+	Total      int64
+	Errors     int64
+	Dropped    int64 // queries not started because Concurrency was exhausted
+	Throughput float64
+	Latency    Latency
+	Queries    []QueryStats
+
+	// Allocation statistics are process-wide and averaged per executed query
+	AllocsPerQuery float64
+	BytesPerQuery  float64
+	GCCycles       uint32
+}
+
+type sample struct {
+	query    int
+	duration time.Duration
+	err      error
+}
+
+// Run executes the workload at the target QPS and returns the collected statistics
+func Run(ctx context.Context, w Workload, opts Options) (*Report, error) {
+	if ctx == nil {
+		return nil, sqlblade.ErrNilContext
+	}
+	if opts.QPS <= 0 || opts.Duration <= 0 {
+		return nil, ErrInvalidOptions
+	}
+
+	picker, err := newPicker(w)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = opts.QPS
+	}
+
+	if opts.Warmup > 0 {
+		if _, err := run(ctx, w, picker, opts.QPS, opts.Warmup, opts.Concurrency); err != nil {
+			return nil, err
+		}
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	startTime := time.Now()
+
+	res, err := run(ctx, w, picker, opts.QPS, opts.Duration, opts.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	elapsed := time.Since(startTime)
+	runtime.ReadMemStats(&after)
+
+	report := res.report(w, elapsed)
+	if report.Total > 0 {
+		report.AllocsPerQuery = float64(after.Mallocs-before.Mallocs) / float64(report.Total)
+		report.BytesPerQuery = float64(after.TotalAlloc-before.TotalAlloc) / float64(report.Total)
+	}
+	report.GCCycles = after.NumGC - before.NumGC
+
+	return report, nil
+}
+
+type result struct {
+	samples []sample
+	dropped int64
+}
+
+func run(ctx context.Context, w Workload, p *picker, qps int, duration time.Duration, concurrency int) (*result, error) {
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	interval := time.Second / time.Duration(qps)
+	if interval <= 0 {
+		interval = time.Nanosecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		dropped int64
+		samples = make([]sample, 0, int(duration/interval)+1)
+		slots   = make(chan struct{}, concurrency)
+	)
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec // workload selection does not need a secure source
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			atomic.AddInt64(&dropped, 1)
+			continue
+		}
+
+		idx := p.pick(rng)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			start := time.Now()
+			err := w[idx].Run(context.WithoutCancel(ctx))
+			s := sample{query: idx, duration: time.Since(start), err: err}
+
+			mu.Lock()
+			samples = append(samples, s)
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return nil, err
+	}
+
+	return &result{samples: samples, dropped: dropped}, nil
+}
+
+func (r *result) report(w Workload, elapsed time.Duration) *Report {
+	report := &Report{
+		Duration: elapsed,
+		Total:    int64(len(r.samples)),
+		Dropped:  r.dropped,
+		Queries:  make([]QueryStats, 0, len(w)),
+	}
+
+	all := make([]time.Duration, 0, len(r.samples))
+	perQuery := make([][]time.Duration, len(w))
+	errs := make([]int64, len(w))
+
+	for _, s := range r.samples {
+		all = append(all, s.duration)
+		perQuery[s.query] = append(perQuery[s.query], s.duration)
+		if s.err != nil {
+			errs[s.query]++
+			report.Errors++
+		}
+	}
+
+	report.Latency = percentiles(all)
+	if elapsed > 0 {
+		report.Throughput = float64(report.Total) / elapsed.Seconds()
+	}
+
+	for i, q := range w {
+		if q.Weight <= 0 || q.Run == nil {
+			continue
+		}
+		report.Queries = append(report.Queries, QueryStats{
+			Name:    q.Name,
+			Count:   int64(len(perQuery[i])),
+			Errors:  errs[i],
+			Latency: percentiles(perQuery[i]),
+		})
+	}
+
+	return report
+}
+
+func percentiles(durations []time.Duration) Latency {
+	if len(durations) == 0 {
+		return Latency{}
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+
+	at := func(p float64) time.Duration {
+		idx := int(p*float64(len(durations))+0.5) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		if idx >= len(durations) {
+			idx = len(durations) - 1
+		}
+		return durations[idx]
+	}
+
+	return Latency{
+		Min:  durations[0],
+		Mean: total / time.Duration(len(durations)),
+		P50:  at(0.50),
+		P90:  at(0.90),
+		P95:  at(0.95),
+		P99:  at(0.99),
+		Max:  durations[len(durations)-1],
+	}
+}
+
+// picker selects workload entries proportionally to their weight
+type picker struct {
+	cumulative []int
+	indexes    []int
+	total      int
+}
+
+func newPicker(w Workload) (*picker, error) {
+	p := &picker{}
+	for i, q := range w {
+		if q.Weight <= 0 || q.Run == nil {
+			continue
+		}
+		p.total += q.Weight
+		p.cumulative = append(p.cumulative, p.total)
+		p.indexes = append(p.indexes, i)
+	}
+	if p.total == 0 {
+		return nil, ErrEmptyWorkload
+	}
+	return p, nil
+}
+
+func (p *picker) pick(rng *rand.Rand) int {
+	n := rng.Intn(p.total)
+	i := sort.SearchInts(p.cumulative, n+1)
+	return p.indexes[i]
+}
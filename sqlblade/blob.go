@@ -0,0 +1,249 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// blobChunkSize is the number of bytes of a blob read or written per statement
+const blobChunkSize = 256 << 10
+
+// ReadBlob streams a bytea/BLOB column of the row of a model with the given
+// primary key into w, one chunk per statement, so that large payloads are
+// never held in memory as a whole. NULL is read as no bytes, and a missing
+// row gives ErrNoRows. The column must not be rewritten while it is read.
+func (c *Client) ReadBlob(ctx context.Context, w io.Writer, model interface{}, column string, key interface{}) (int64, error) {
+	if ctx == nil {
+		return 0, ErrNilContext
+	}
+	meta, pk, err := blobModel(model)
+	if err != nil {
+		return 0, err
+	}
+
+	col, from, length := c.dialect.QuoteIdentifier(column), c.dialect.Placeholder(1), c.dialect.Placeholder(2)
+	var chunkSQL string
+	switch c.dialect.Name() {
+	case dialectPostgres:
+		chunkSQL = "substring(" + col + " FROM " + from + " FOR " + length + ")"
+	case dialectMySQL:
+		chunkSQL = "SUBSTRING(" + col + ", " + from + ", " + length + ")"
+	default:
+		chunkSQL = "substr(" + col + ", " + from + ", " + length + ")"
+	}
+	sqlStr := "SELECT " + chunkSQL + " FROM " + c.dialect.QuoteIdentifier(meta.Table) +
+		" WHERE " + c.dialect.QuoteIdentifier(pk) + " = " + c.dialect.Placeholder(3)
+
+	var written int64
+	for {
+		var chunk []byte
+		found, err := c.queryBlob(ctx, nil, meta.Table, &chunk, sqlStr, written+1, blobChunkSize, key)
+		if err != nil {
+			return written, err
+		}
+		if !found {
+			return written, fmt.Errorf("%w: %s %s = %v", ErrNoRows, meta.Table, pk, key)
+		}
+		n, err := w.Write(chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+		if len(chunk) < blobChunkSize {
+			return written, nil
+		}
+	}
+}
+
+// WriteBlob replaces a bytea/BLOB column of the row of a model with the given
+// primary key by the content of r, appending it one chunk per statement in a
+// transaction. A missing row gives ErrNoRows.
+func (c *Client) WriteBlob(ctx context.Context, r io.Reader, model interface{}, column string, key interface{}) (int64, error) {
+	if ctx == nil {
+		return 0, ErrNilContext
+	}
+	meta, pk, err := blobModel(model)
+	if err != nil {
+		return 0, err
+	}
+
+	table, col, data := c.dialect.QuoteIdentifier(meta.Table), c.dialect.QuoteIdentifier(column), c.dialect.Placeholder(1)
+	where := " WHERE " + c.dialect.QuoteIdentifier(pk) + " = " + c.dialect.Placeholder(2)
+	setSQL := "UPDATE " + table + " SET " + col + " = " + data + where
+	var appendSQL string
+	switch c.dialect.Name() {
+	case dialectMySQL:
+		appendSQL = "UPDATE " + table + " SET " + col + " = CONCAT(" + col + ", " + data + ")" + where
+	case dialectSQLite:
+		// || returns text; the cast keeps the column a blob
+		appendSQL = "UPDATE " + table + " SET " + col + " = CAST(" + col + " || " + data + " AS BLOB)" + where
+	default:
+		appendSQL = "UPDATE " + table + " SET " + col + " = " + col + " || " + data + where
+	}
+
+	var written int64
+	err = c.WithTransaction(ctx, func(tx *Tx) error {
+		buf := make([]byte, blobChunkSize)
+		for first := true; ; first = false {
+			n, readErr := io.ReadFull(r, buf)
+			if readErr != nil && !errors.Is(readErr, io.ErrUnexpectedEOF) && !errors.Is(readErr, io.EOF) {
+				return readErr
+			}
+			if n == 0 && !first {
+				return nil
+			}
+
+			sqlStr := appendSQL
+			if first {
+				sqlStr = setSQL
+			}
+			affected, err := c.execBlob(ctx, tx.tx, meta.Table, sqlStr, buf[:n], key)
+			if err != nil {
+				return err
+			}
+			if first && affected == 0 {
+				return fmt.Errorf("%w: %s %s = %v", ErrNoRows, meta.Table, pk, key)
+			}
+			written += int64(n)
+			if readErr != nil {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return written, nil
+}
+
+// CreateLargeObject stores the content of r as a new PostgreSQL large object,
+// one chunk per statement in a transaction, and returns its OID with the
+// number of bytes stored. Reference the OID from a column of type oid.
+func (c *Client) CreateLargeObject(ctx context.Context, r io.Reader) (uint32, int64, error) {
+	if ctx == nil {
+		return 0, 0, ErrNilContext
+	}
+	if c.dialect.Name() != dialectPostgres {
+		return 0, 0, fmt.Errorf("%w: large objects need PostgreSQL", ErrDialectUnsupported)
+	}
+
+	var oid uint32
+	var written int64
+	err := c.WithTransaction(ctx, func(tx *Tx) error {
+		if _, err := c.queryBlob(ctx, tx.tx, "", &oid, "SELECT lo_from_bytea(0, $1)", []byte{}); err != nil {
+			return err
+		}
+		buf := make([]byte, blobChunkSize)
+		for {
+			n, readErr := io.ReadFull(r, buf)
+			if readErr != nil && !errors.Is(readErr, io.ErrUnexpectedEOF) && !errors.Is(readErr, io.EOF) {
+				return readErr
+			}
+			if n > 0 {
+				if _, err := c.execBlob(ctx, tx.tx, "", "SELECT lo_put($1, $2, $3)", oid, written, buf[:n]); err != nil {
+					return err
+				}
+				written += int64(n)
+			}
+			if readErr != nil {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return oid, written, nil
+}
+
+// ReadLargeObject streams the PostgreSQL large object oid into w, one chunk
+// per statement
+func (c *Client) ReadLargeObject(ctx context.Context, w io.Writer, oid uint32) (int64, error) {
+	if ctx == nil {
+		return 0, ErrNilContext
+	}
+	if c.dialect.Name() != dialectPostgres {
+		return 0, fmt.Errorf("%w: large objects need PostgreSQL", ErrDialectUnsupported)
+	}
+
+	var written int64
+	for {
+		var chunk []byte
+		if _, err := c.queryBlob(ctx, nil, "", &chunk, "SELECT lo_get($1, $2, $3)", oid, written, blobChunkSize); err != nil {
+			return written, err
+		}
+		n, err := w.Write(chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+		if len(chunk) < blobChunkSize {
+			return written, nil
+		}
+	}
+}
+
+// DeleteLargeObject deletes the PostgreSQL large object oid
+func (c *Client) DeleteLargeObject(ctx context.Context, oid uint32) error {
+	if ctx == nil {
+		return ErrNilContext
+	}
+	if c.dialect.Name() != dialectPostgres {
+		return fmt.Errorf("%w: large objects need PostgreSQL", ErrDialectUnsupported)
+	}
+	_, err := c.execBlob(ctx, nil, "", "SELECT lo_unlink($1)", oid)
+	return err
+}
+
+// blobModel returns the metadata of a model with a single primary key column
+func blobModel(model interface{}) (*ModelMeta, string, error) {
+	typ := reflect.TypeOf(model)
+	if typ == nil {
+		return nil, "", ErrInvalidModel
+	}
+	meta, err := metadataOf(typ)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(meta.PrimaryKey) != 1 {
+		return nil, "", fmt.Errorf("%w: %s needs a single primary key column", ErrInvalidModel, meta.Table)
+	}
+	return meta, meta.PrimaryKey[0], nil
+}
+
+// queryBlob runs a statement of the blob helpers returning a single value,
+// scanning it into dest. It reports whether a row was returned.
+func (c *Client) queryBlob(ctx context.Context, tx *sql.Tx, table string, dest interface{}, sqlStr string, args ...interface{}) (bool, error) {
+	found := false
+	stmt := &Statement{Operation: "SELECT", Table: table, SQL: sqlStr, Args: args, Primary: tx != nil}
+	err := c.queryHooked(ctx, tx, stmt, func(rows *sql.Rows) error {
+		if rows.Next() {
+			found = true
+			if err := rows.Scan(dest); err != nil {
+				return fmt.Errorf("sqlblade: failed to scan row: %w", err)
+			}
+		}
+		return rows.Err()
+	})
+	return found, err
+}
+
+// execBlob executes a statement of the blob helpers, returning the number of
+// affected rows
+func (c *Client) execBlob(ctx context.Context, tx *sql.Tx, table, sqlStr string, args ...interface{}) (int64, error) {
+	var affected int64
+	stmt := &Statement{Operation: "UPDATE", Table: table, SQL: sqlStr, Args: args, Primary: true}
+	err := c.executeHooked(ctx, stmt, func(ctx context.Context, _ *HookEvent) (sql.Result, error) {
+		result, err := c.execContext(ctx, tx, true, stmt.SQL, stmt.Args)
+		if err != nil {
+			return nil, wrapQueryError(err, stmt.SQL, stmt.Args)
+		}
+		affected, _ = result.RowsAffected()
+		return result, nil
+	})
+	return affected, err
+}
@@ -3,39 +3,50 @@ package sqlblade
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
 	"reflect"
 	"strings"
-	"time"
 
 	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
 )
 
 // QueryBuilder is the main query builder struct
 type QueryBuilder[T any] struct {
-	db           *sql.DB
-	tx           *sql.Tx
-	dialect      dialect.Dialect
-	tableName    string
-	whereClauses []WhereClause
-	joins        []dialect.Join
-	orderBy      []dialect.OrderBy
-	limit        *int
-	offset       *int
-	selectCols   []string
-	groupBy      []string
-	having       []WhereClause
-	distinct     bool
+	db             *sql.DB
+	tx             *sql.Tx
+	client         *Client
+	dialect        dialect.Dialect
+	tableName      string
+	alias          string
+	whereClauses   []WhereClause
+	joins          []dialect.Join
+	joinSubqueries map[int]*Subquery // derived tables of joins, by position
+	orderBy        []dialect.OrderBy
+	limit          *int
+	offset         *int
+	selectCols     []string
+	selectMapped   bool
+	selectExprs    []Expression
+	windows        []windowExpr
+	setOps         []setOp[T]
+	groupBy        []groupByExpr
+	having         []WhereClause
+	distinct       bool
+	lock           dialect.Lock
+	forcePrimary   bool
+	cursor         Cursor
+	cursorBefore   bool
+	allowPartial   bool
+	strict         bool
+	splitInLists   bool
+	tags           []string
+	params         Params // named parameters bound with Bind
 }
 
 // Query creates a new SELECT query builder
-func Query[T any](db *sql.DB) *QueryBuilder[T] {
-	if db == nil {
-		panic(ErrNilDB)
-	}
-
-	d := detectDialect(db.Driver())
+func Query[T any, C Conn](db C) *QueryBuilder[T] {
+	client := resolveConn(db)
 
 	var zero T
 	typ := reflect.TypeOf(zero)
@@ -51,25 +62,22 @@ func Query[T any](db *sql.DB) *QueryBuilder[T] {
 	}
 
 	return &QueryBuilder[T]{
-		db:           db,
-		dialect:      d,
+		db:           client.db,
+		client:       client,
+		dialect:      withColumnAliases(client.dialect, typ, info.tableName),
 		tableName:    info.tableName,
 		whereClauses: make([]WhereClause, 0),
 		joins:        make([]dialect.Join, 0),
 		orderBy:      make([]dialect.OrderBy, 0),
 		selectCols:   make([]string, 0),
-		groupBy:      make([]string, 0),
+		groupBy:      make([]groupByExpr, 0),
 		having:       make([]WhereClause, 0),
 	}
 }
 
 // QueryTx creates a new SELECT query builder with transaction
-func QueryTx[T any](tx *sql.Tx) *QueryBuilder[T] {
-	if tx == nil {
-		panic(ErrNilDB)
-	}
-
-	d := detectDialect(nil)
+func QueryTx[T any, X TxConn](tx X) *QueryBuilder[T] {
+	sqlTx, client := resolveTx(tx)
 
 	var zero T
 	typ := reflect.TypeOf(zero)
@@ -85,14 +93,15 @@ func QueryTx[T any](tx *sql.Tx) *QueryBuilder[T] {
 	}
 
 	return &QueryBuilder[T]{
-		tx:           tx,
-		dialect:      d,
+		tx:           sqlTx,
+		client:       client,
+		dialect:      withColumnAliases(client.dialect, typ, info.tableName),
 		tableName:    info.tableName,
 		whereClauses: make([]WhereClause, 0),
 		joins:        make([]dialect.Join, 0),
 		orderBy:      make([]dialect.OrderBy, 0),
 		selectCols:   make([]string, 0),
-		groupBy:      make([]string, 0),
+		groupBy:      make([]groupByExpr, 0),
 		having:       make([]WhereClause, 0),
 	}
 }
@@ -138,9 +147,82 @@ func (qb *QueryBuilder[T]) OrWhere(column string, operator string, value interfa
 	return qb
 }
 
-// Select specifies columns to select
+// WhereColumn adds a WHERE condition comparing two columns (AND)
+func (qb *QueryBuilder[T]) WhereColumn(column string, operator string, otherColumn string) *QueryBuilder[T] {
+	return qb.Where(column, operator, ColumnRef(otherColumn))
+}
+
+// OrWhereColumn adds a WHERE condition comparing two columns (OR)
+func (qb *QueryBuilder[T]) OrWhereColumn(column string, operator string, otherColumn string) *QueryBuilder[T] {
+	return qb.OrWhere(column, operator, ColumnRef(otherColumn))
+}
+
+// WhereRaw adds a raw WHERE predicate (AND). Use "?" as placeholder for args;
+// it is converted to the dialect's placeholder style.
+func (qb *QueryBuilder[T]) WhereRaw(sql string, args ...interface{}) *QueryBuilder[T] {
+	qb.whereClauses = append(qb.whereClauses, WhereClause{
+		Value: rawExpr{sql: sql, args: args},
+		And:   true,
+	})
+	return qb
+}
+
+// OrWhereRaw adds a raw WHERE predicate (OR)
+func (qb *QueryBuilder[T]) OrWhereRaw(sql string, args ...interface{}) *QueryBuilder[T] {
+	qb.whereClauses = append(qb.whereClauses, WhereClause{
+		Value: rawExpr{sql: sql, args: args},
+		And:   false,
+	})
+	return qb
+}
+
+// WhereIn adds a WHERE column IN (...) condition (AND). An empty list matches no rows.
+func (qb *QueryBuilder[T]) WhereIn(column string, values ...interface{}) *QueryBuilder[T] {
+	return qb.Where(column, "IN", values)
+}
+
+// OrWhereIn adds a WHERE column IN (...) condition (OR)
+func (qb *QueryBuilder[T]) OrWhereIn(column string, values ...interface{}) *QueryBuilder[T] {
+	return qb.OrWhere(column, "IN", values)
+}
+
+// WhereNotIn adds a WHERE column NOT IN (...) condition (AND). An empty list matches all rows.
+func (qb *QueryBuilder[T]) WhereNotIn(column string, values ...interface{}) *QueryBuilder[T] {
+	return qb.Where(column, "NOT IN", values)
+}
+
+// WhereInSlice adds a WHERE column IN (...) condition for a typed slice, e.g.
+// WhereInSlice(sqlblade.Query[User](db), "id", ids) with ids of type []int64
+func WhereInSlice[T any, V comparable](qb *QueryBuilder[T], column string, values []V) *QueryBuilder[T] {
+	return qb.Where(column, "IN", toInterfaces(values))
+}
+
+// WhereNotInSlice adds a WHERE column NOT IN (...) condition for a typed slice
+func WhereNotInSlice[T any, V comparable](qb *QueryBuilder[T], column string, values []V) *QueryBuilder[T] {
+	return qb.Where(column, "NOT IN", toInterfaces(values))
+}
+
+// toInterfaces converts a typed slice to []interface{}
+func toInterfaces[V any](values []V) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
+
+// As sets an alias for the table, which correlated subqueries can reference with Cor
+func (qb *QueryBuilder[T]) As(alias string) *QueryBuilder[T] {
+	qb.alias = alias
+	return qb
+}
+
+// Select specifies columns to select. Without columns the mapped columns of T
+// are selected instead of *, qualified with the table (or its alias) when the
+// query has joins, so that columns added to the table later aren't fetched.
 func (qb *QueryBuilder[T]) Select(columns ...string) *QueryBuilder[T] {
 	qb.selectCols = columns
+	qb.selectMapped = len(columns) == 0
 	return qb
 }
 
@@ -189,9 +271,27 @@ func (qb *QueryBuilder[T]) OrderBy(column string, order dialect.OrderDirection)
 	return qb
 }
 
+// OrderByRaw adds a raw ORDER BY expression, emitted verbatim, e.g.
+// OrderByRaw("CASE WHEN status = 'active' THEN 0 ELSE 1 END, created_at DESC")
+func (qb *QueryBuilder[T]) OrderByRaw(expr string) *QueryBuilder[T] {
+	qb.orderBy = append(qb.orderBy, dialect.OrderBy{
+		Column: expr,
+		Raw:    true,
+	})
+	return qb
+}
+
 // GroupBy adds a GROUP BY clause
 func (qb *QueryBuilder[T]) GroupBy(columns ...string) *QueryBuilder[T] {
-	qb.groupBy = append(qb.groupBy, columns...)
+	for _, col := range columns {
+		qb.groupBy = append(qb.groupBy, groupByExpr{expr: col})
+	}
+	return qb
+}
+
+// GroupByRaw adds a raw GROUP BY expression, e.g. GroupByRaw("DATE(created_at)")
+func (qb *QueryBuilder[T]) GroupByRaw(expr string) *QueryBuilder[T] {
+	qb.groupBy = append(qb.groupBy, groupByExpr{expr: expr, raw: true})
 	return qb
 }
 
@@ -206,6 +306,21 @@ func (qb *QueryBuilder[T]) Having(column string, operator string, value interfac
 	return qb
 }
 
+// ForcePrimary marks the query as one that must run on the primary, e.g. to
+// read data that was just written. See Client.SetRoutingHints.
+func (qb *QueryBuilder[T]) ForcePrimary() *QueryBuilder[T] {
+	qb.forcePrimary = true
+	return qb
+}
+
+// AllowPartialResults makes Execute return the rows scanned so far together
+// with ErrPartialResult when the context is done in the middle of the scan,
+// e.g. for best-effort dashboards, instead of discarding them
+func (qb *QueryBuilder[T]) AllowPartialResults() *QueryBuilder[T] {
+	qb.allowPartial = true
+	return qb
+}
+
 // Limit sets the LIMIT clause
 func (qb *QueryBuilder[T]) Limit(limit int) *QueryBuilder[T] {
 	qb.limit = &limit
@@ -218,148 +333,302 @@ func (qb *QueryBuilder[T]) Offset(offset int) *QueryBuilder[T] {
 	return qb
 }
 
+// fromSQL returns the quoted table name including its alias
+func (qb *QueryBuilder[T]) fromSQL() string {
+	if qb.alias == "" {
+		return qb.dialect.QuoteIdentifier(qb.tableName)
+	}
+	return qb.dialect.QuoteIdentifier(qb.tableName) + " AS " + qb.dialect.QuoteIdentifier(qb.alias)
+}
+
+// buildSQL builds the SELECT statement
 func (qb *QueryBuilder[T]) buildSQL() (string, []interface{}) {
+	return qb.buildSelectSQL("")
+}
+
+// buildSelectSQL builds the SELECT statement, appending the raw extra
+// expression to the selected columns
+func (qb *QueryBuilder[T]) buildSelectSQL(extra string) (string, []interface{}) {
+	key, args := qb.shape(extra)
+	if cached, ok := selectSQLCache.Load(key); ok {
+		return cached.(string), args
+	}
+
+	sqlStr, args := qb.renderSelectSQL(extra)
+	cacheSelectSQL(key, sqlStr)
+	return sqlStr, args
+}
+
+// renderSelectSQL writes out the SELECT statement
+func (qb *QueryBuilder[T]) renderSelectSQL(extra string) (string, []interface{}) {
 	var buf strings.Builder
 	buf.Grow(selectBufferSize)
 	paramIndex := 0
 	args := make([]interface{}, 0, argsInitialCapacity)
 
+	qb.writeSelect(&buf, extra, &paramIndex, &args)
+	return buf.String(), args
+}
+
+// writeSelect writes the SELECT statement, combined with the queries of its
+// set operations, numbering placeholders on from paramIndex
+func (qb *QueryBuilder[T]) writeSelect(buf *strings.Builder, extra string, paramIndex *int, args *[]interface{}) {
+	switch {
+	case len(qb.setOps) == 0:
+		qb.writeSelectCore(buf, extra, paramIndex, args)
+	case extra == "":
+		qb.writeCompound(buf, paramIndex, args)
+	default:
+		buf.WriteString("SELECT *, ")
+		buf.WriteString(extra)
+		buf.WriteString(" FROM (")
+		qb.writeCompound(buf, paramIndex, args)
+		buf.WriteString(") AS ")
+		buf.WriteString(setOpAlias)
+	}
+
+	if len(qb.orderBy) > 0 {
+		buf.WriteString(" ")
+		buf.WriteString(qb.dialect.BuildOrderBy(bindOrderBy(qb.dialect, qb.orderBy, paramIndex, args)))
+	}
+
+	if qb.limit != nil || qb.offset != nil {
+		limitSQL, limitArgs := buildLimitOffset(qb.dialect, qb.limit, qb.offset, paramIndex)
+		buf.WriteString(" ")
+		buf.WriteString(limitSQL)
+		*args = append(*args, limitArgs...)
+	}
+
+	if lockSQL := qb.lockSQL(); lockSQL != "" {
+		buf.WriteString(" ")
+		buf.WriteString(lockSQL)
+	}
+}
+
+// writeSelectCore writes the SELECT statement up to HAVING
+func (qb *QueryBuilder[T]) writeSelectCore(buf *strings.Builder, extra string, paramIndex *int, args *[]interface{}) {
 	buf.WriteString("SELECT ")
 	if qb.distinct {
 		buf.WriteString("DISTINCT ")
 	}
 
-	if len(qb.selectCols) > 0 {
-		quotedCols := make([]string, len(qb.selectCols))
-		for i, col := range qb.selectCols {
+	selectCols := qb.selectCols
+	if len(selectCols) == 0 && qb.selectMapped {
+		selectCols = qb.mappedColumns()
+	}
+	if len(selectCols) > 0 {
+		quotedCols := make([]string, len(selectCols))
+		for i, col := range selectCols {
 			quotedCols[i] = qb.dialect.QuoteIdentifier(col)
 		}
 		buf.WriteString(strings.Join(quotedCols, ", "))
 	} else {
 		buf.WriteString("*")
 	}
+	for _, expr := range qb.selectExprs {
+		buf.WriteString(", ")
+		expr.write(buf, qb.dialect, paramIndex, args)
+	}
+	if len(qb.windows) > 0 {
+		buf.WriteString(", ")
+		buf.WriteString(qb.windowSQL())
+	}
+	if extra != "" {
+		buf.WriteString(", ")
+		buf.WriteString(extra)
+	}
 
 	buf.WriteString(" FROM ")
-	buf.WriteString(qb.dialect.QuoteIdentifier(qb.tableName))
+	buf.WriteString(qb.fromSQL())
 
-	for _, join := range qb.joins {
-		buf.WriteString(" ")
-		buf.WriteString(qb.dialect.BuildJoin(join))
-	}
+	qb.writeJoins(buf, paramIndex, args)
 
-	whereSQL, whereArgs := buildWhereClause(qb.dialect, qb.whereClauses, &paramIndex)
+	whereSQL, whereArgs := buildWhereClause(qb.dialect, qb.whereClauses, paramIndex)
 	if whereSQL != "" {
 		buf.WriteString(" ")
 		buf.WriteString(whereSQL)
-		args = append(args, whereArgs...)
+		*args = append(*args, whereArgs...)
 	}
 
 	if len(qb.groupBy) > 0 {
 		buf.WriteString(" GROUP BY ")
-		quotedCols := make([]string, len(qb.groupBy))
-		for i, col := range qb.groupBy {
-			quotedCols[i] = qb.dialect.QuoteIdentifier(col)
-		}
-		buf.WriteString(strings.Join(quotedCols, ", "))
+		buf.WriteString(buildGroupBy(qb.dialect, qb.groupBy))
 	}
 
 	if len(qb.having) > 0 {
-		havingSQL, havingArgs := buildWhereClause(qb.dialect, qb.having, &paramIndex)
+		havingSQL, havingArgs := buildWhereClause(qb.dialect, qb.having, paramIndex)
 		if havingSQL != "" {
 			buf.WriteString(" ")
 			buf.WriteString(strings.Replace(havingSQL, "WHERE", "HAVING", 1))
-			args = append(args, havingArgs...)
+			*args = append(*args, havingArgs...)
 		}
 	}
+}
 
-	if len(qb.orderBy) > 0 {
-		buf.WriteString(" ")
-		buf.WriteString(qb.dialect.BuildOrderBy(qb.orderBy))
+// Execute executes the query and returns results
+func (qb *QueryBuilder[T]) Execute(ctx context.Context) ([]T, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
 	}
 
-	if qb.limit != nil || qb.offset != nil {
-		buf.WriteString(" ")
-		buf.WriteString(qb.dialect.BuildLimitOffset(qb.limit, qb.offset))
+	qb = qb.defaultLimit()
+	sqlStr, args := qb.buildSQL()
+	if rows, split, err := qb.executeSplit(ctx, len(args)); split {
+		return rows, err
 	}
+	return qb.executeSQL(ctx, sqlStr, args)
+}
 
-	return buf.String(), args
+// executeSQL runs the SELECT statement sqlStr and scans its rows
+func (qb *QueryBuilder[T]) executeSQL(ctx context.Context, sqlStr string, args []interface{}) ([]T, error) {
+	var result []T
+	err := qb.query(ctx, sqlStr, args, func(rows *sql.Rows) error {
+		var err error
+		if result, err = scanRowsOptimized[T](rows, qb.client.timeLocation(ctx)); err != nil {
+			if !qb.allowPartial || ctx.Err() == nil {
+				return err
+			}
+			if hookErr := applyScanHooks(ctx, result); hookErr != nil {
+				return hookErr
+			}
+			return fmt.Errorf("%w: %d rows scanned: %w", ErrPartialResult, len(result), err)
+		}
+		return applyScanHooks(ctx, result)
+	})
+	if err != nil {
+		if errors.Is(err, ErrPartialResult) {
+			return result, err
+		}
+		return nil, err
+	}
+	return result, nil
 }
 
-// Execute executes the query and returns results
-func (qb *QueryBuilder[T]) Execute(ctx context.Context) ([]T, error) {
+// Iterate executes the query and calls fn for every row as it is scanned,
+// without materializing the whole result set. Iteration stops at the first
+// error returned by fn, which is then returned by Iterate.
+func (qb *QueryBuilder[T]) Iterate(ctx context.Context, fn func(T) error) error {
 	if ctx == nil {
-		return nil, ErrNilContext
+		return ErrNilContext
+	}
+	if fn == nil {
+		return ErrNilIterateFunc
 	}
 
 	sqlStr, args := qb.buildSQL()
-	startTime := time.Now()
+	return qb.iterateSQL(ctx, sqlStr, args, fn)
+}
 
-	if err := DefaultHooks.ExecuteBeforeHooks(ctx, sqlStr, args); err != nil {
-		return nil, err
+// iterateSQL runs the SELECT statement sqlStr and calls fn for every row
+func (qb *QueryBuilder[T]) iterateSQL(ctx context.Context, sqlStr string, args []interface{}, fn func(T) error) error {
+	return qb.query(ctx, sqlStr, args, func(rows *sql.Rows) error {
+		rs, err := newRowScanner[T](rows, qb.client.timeLocation(ctx))
+		if err != nil {
+			return err
+		}
+		defer rs.release()
+
+		hooks := scanHooksFor[T]()
+		for rows.Next() {
+			var val T
+			if err := rs.scan(&val); err != nil {
+				return err
+			}
+			if err := runScanHooks(ctx, hooks, &val); err != nil {
+				return err
+			}
+			if err := fn(val); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
+
+// query runs a SELECT of the query builder through the hooks, the debugger and
+// the middleware chain, passing the result rows to fn
+func (qb *QueryBuilder[T]) query(ctx context.Context, sqlStr string, args []interface{}, fn func(rows *sql.Rows) error) error {
+	if err := qb.validate(); err != nil {
+		return err
 	}
+	stmt := qb.statement(sqlStr, args)
+	event := qb.client.newHookEvent(stmt)
+	startTime := event.StartTime
 
-	if globalDebugger.enabled {
+	if err := qb.client.executeBeforeHooks(ctx, event); err != nil {
+		return err
+	}
+
+	if debugger := qb.client.queryDebugger(); debugger.enabled.Load() {
 		debugQuery := &DebugQuery{
 			SQL:       sqlStr,
 			Args:      args,
+			Tags:      stmt.Tags,
 			Table:     qb.tableName,
 			Operation: "SELECT",
 			Timestamp: startTime,
 		}
 		defer func() {
-			debugQuery.Duration = time.Since(startTime)
-			globalDebugger.Log(debugQuery)
+			debugQuery.Duration = qb.client.since(startTime)
+			debugger.Log(debugQuery)
 		}()
 	}
 
-	var rows *sql.Rows
-	var err error
-
-	if qb.tx == nil && globalStmtCache != nil && globalStmtCache.db == qb.db {
-		stmt, stmtErr := globalStmtCache.getStmt(ctx, sqlStr)
-		if stmtErr == nil {
-			rows, err = stmt.QueryContext(ctx, args...)
-			if err == nil {
-				defer func(rows *sql.Rows) {
-					closeErr := rows.Close()
-					if closeErr != nil {
-						log.Printf("failed to close rows: %v", closeErr)
-					}
-				}(rows)
-				result, err := scanRowsOptimized[T](rows)
-				if err == nil {
-					if hookErr := DefaultHooks.ExecuteAfterHooks(ctx, sqlStr, args); hookErr != nil {
-						log.Printf("after query hook error: %v", hookErr)
-					}
-				}
-				return result, err
-			}
-			return nil, wrapQueryError(err, sqlStr, args)
+	err := qb.client.execute(ctx, stmt, func(ctx context.Context) error {
+		rows, err := qb.client.queryContext(ctx, qb.tx, true, stmt.SQL, stmt.Args)
+		if err != nil && qb.tx == nil {
+			rows, err = qb.client.retryAliased(ctx, qb.dialect, qb.tableName, stmt, err)
 		}
-		return nil, wrapQueryError(stmtErr, sqlStr, args)
-	}
+		if err != nil {
+			return wrapQueryError(err, stmt.SQL, stmt.Args)
+		}
+		defer closeRows(rows)
 
-	if qb.tx != nil {
-		rows, err = qb.tx.QueryContext(ctx, sqlStr, args...)
-	} else {
-		rows, err = qb.db.QueryContext(ctx, sqlStr, args...)
+		scanStart := event.clock.Now()
+		defer func() { event.ScanDuration = qb.client.since(scanStart) }()
+		return fn(rows)
+	})
+
+	event.finish(err, nil)
+	qb.client.executeAfterHooks(ctx, event)
+	return err
+}
+
+// statement returns the Statement describing a SELECT of the query builder
+func (qb *QueryBuilder[T]) statement(sqlStr string, args []interface{}) *Statement {
+	return &Statement{
+		Operation: "SELECT",
+		Table:     qb.tableName,
+		SQL:       sqlStr,
+		Args:      args,
+		Primary:   qb.forcePrimary || qb.tx != nil,
+		Tags:      qb.tags,
 	}
+}
 
+// First applies LIMIT 1, executes the query and returns the first result,
+// or ErrNoRows when the query returns no rows
+func (qb *QueryBuilder[T]) First(ctx context.Context) (T, error) {
+	var zero T
+	limit := 1
+	prev := qb.limit
+	qb.limit = &limit
+	results, err := qb.Execute(ctx)
+	qb.limit = prev
 	if err != nil {
-		return nil, wrapQueryError(err, sqlStr, args)
+		return zero, err
 	}
-	defer func(rows *sql.Rows) {
-		closeErr := rows.Close()
-		if closeErr != nil {
-			log.Printf("failed to close rows: %v", closeErr)
-		}
-	}(rows)
+	if len(results) == 0 {
+		return zero, ErrNoRows
+	}
+	return results[0], nil
+}
 
-	result, err := scanRowsOptimized[T](rows)
-	if err == nil {
-		if hookErr := DefaultHooks.ExecuteAfterHooks(ctx, sqlStr, args); hookErr != nil {
-			log.Printf("after query hook error: %v", hookErr)
-		}
+// FirstOrErr is like First but returns the given error when the query returns no rows
+func (qb *QueryBuilder[T]) FirstOrErr(ctx context.Context, notFound error) (T, error) {
+	result, err := qb.First(ctx)
+	if err != nil && errors.Is(err, ErrNoRows) {
+		return result, notFound
 	}
 	return result, err
 }
@@ -372,24 +641,33 @@ func (qb *QueryBuilder[T]) NotExists(ctx context.Context) (bool, error) {
 
 // Exists creates an EXISTS subquery
 func (qb *QueryBuilder[T]) Exists(ctx context.Context) (bool, error) {
-	sql, args := qb.buildSQL()
+	if ctx == nil {
+		return false, ErrNilContext
+	}
+	if err := qb.validate(); err != nil {
+		return false, err
+	}
+
+	sqlStr, args := qb.buildSQL()
 	//nolint:gosec // SQL is generated by buildSQL() which is safe, not user input
-	existsSQL := fmt.Sprintf("SELECT EXISTS(%s)", sql)
+	existsSQL := fmt.Sprintf("SELECT EXISTS(%s)", sqlStr)
 
 	var result bool
-	if qb.tx != nil {
-		row := qb.tx.QueryRowContext(ctx, existsSQL, args...)
-		err := row.Scan(&result)
-		if err != nil {
-			return false, wrapQueryError(err, existsSQL, args)
+	stmt := qb.statement(existsSQL, args)
+	err := qb.client.queryHooked(ctx, qb.tx, stmt, func(rows *sql.Rows) error {
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return wrapQueryError(err, stmt.SQL, stmt.Args)
+			}
+			return wrapQueryError(sql.ErrNoRows, stmt.SQL, stmt.Args)
 		}
-		return result, nil
-	}
-
-	row := qb.db.QueryRowContext(ctx, existsSQL, args...)
-	err := row.Scan(&result)
+		if err := rows.Scan(&result); err != nil {
+			return wrapQueryError(err, stmt.SQL, stmt.Args)
+		}
+		return nil
+	})
 	if err != nil {
-		return false, wrapQueryError(err, existsSQL, args)
+		return false, err
 	}
 
 	return result, nil
@@ -0,0 +1,64 @@
+package sqlblade
+
+import (
+	"context"
+	"sync"
+)
+
+type captureKey struct{}
+
+// Capture records the statements executed with a context returned by WithCapture
+type Capture struct {
+	mu         sync.Mutex
+	statements []Statement
+	parent     *Capture
+}
+
+// WithCapture returns a context that records every statement executed with it.
+// Statements are recorded after the middleware chain has run, so a statement
+// answered by a caching middleware is not counted. Captures can be nested; the
+// inner capture also records into the outer one.
+//
+//	ctx, capture := sqlblade.WithCapture(ctx)
+//	_, _ = sqlblade.Query[User](db).Execute(ctx)
+//	fmt.Println(capture.Count()) // 1
+func WithCapture(ctx context.Context) (context.Context, *Capture) {
+	capture := &Capture{parent: captureFromContext(ctx)}
+	return context.WithValue(ctx, captureKey{}, capture), capture
+}
+
+func captureFromContext(ctx context.Context) *Capture {
+	capture, _ := ctx.Value(captureKey{}).(*Capture)
+	return capture
+}
+
+// Count returns the number of captured statements
+func (c *Capture) Count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.statements)
+}
+
+// Statements returns a copy of the captured statements in execution order
+func (c *Capture) Statements() []Statement {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	statements := make([]Statement, len(c.statements))
+	copy(statements, c.statements)
+	return statements
+}
+
+// Reset discards the captured statements
+func (c *Capture) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statements = nil
+}
+
+func (c *Capture) record(stmt *Statement) {
+	for capture := c; capture != nil; capture = capture.parent {
+		capture.mu.Lock()
+		capture.statements = append(capture.statements, *stmt)
+		capture.mu.Unlock()
+	}
+}
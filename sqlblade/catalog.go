@@ -0,0 +1,152 @@
+package sqlblade
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Catalog describes the registered models and named queries of an
+// application, for data catalogs and generated documentation
+type Catalog struct {
+	Models  []CatalogModel `json:"models"`
+	Queries []CatalogQuery `json:"queries"`
+}
+
+// CatalogModel describes a registered model
+type CatalogModel struct {
+	Name          string            `json:"name"` // Go type, e.g. "main.User"
+	Table         string            `json:"table"`
+	PrimaryKey    []string          `json:"primary_key,omitempty"`
+	SoftDelete    string            `json:"soft_delete,omitempty"`
+	Columns       []CatalogColumn   `json:"columns"`
+	Relations     []CatalogRelation `json:"relations,omitempty"`
+	ColumnAliases map[string]string `json:"column_aliases,omitempty"`
+}
+
+// CatalogColumn describes a mapped column of a model
+type CatalogColumn struct {
+	Name     string   `json:"name"`
+	Field    string   `json:"field"`
+	Type     string   `json:"type"`
+	Nullable bool     `json:"nullable"`
+	Options  []string `json:"options,omitempty"`
+}
+
+// CatalogRelation describes a relation of a model
+type CatalogRelation struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	Table      string `json:"table"`
+	ForeignKey string `json:"foreign_key,omitempty"`
+	References string `json:"references,omitempty"`
+	JoinTable  string `json:"join_table,omitempty"`
+}
+
+// CatalogQuery describes a compiled query registered with RegisterQuery
+type CatalogQuery struct {
+	Name    string `json:"name"`
+	Model   string `json:"model"`
+	Table   string `json:"table"`
+	Dialect string `json:"dialect"`
+	SQL     string `json:"sql"`
+	NumArgs int    `json:"num_args"`
+}
+
+var queryRegistry = struct {
+	mu      sync.RWMutex
+	queries map[string]CatalogQuery
+}{queries: make(map[string]CatalogQuery)}
+
+// RegisterQuery names a compiled query so that it is listed by the catalog,
+// replacing a query registered under the same name, and returns it:
+//
+//	var usersByEmail = sqlblade.RegisterQuery("users.by_email",
+//	    sqlblade.Query[User](db).Where("email", "=", "").Compile())
+func RegisterQuery[T any](name string, cq *CompiledQuery[T]) *CompiledQuery[T] {
+	query := CatalogQuery{
+		Name:    name,
+		Model:   modelType[T]().String(),
+		Table:   cq.qb.tableName,
+		Dialect: cq.qb.dialect.Name(),
+		SQL:     cq.sql,
+		NumArgs: len(cq.args),
+	}
+
+	queryRegistry.mu.Lock()
+	queryRegistry.queries[name] = query
+	queryRegistry.mu.Unlock()
+	return cq
+}
+
+// ExportCatalog returns the catalog of the registered models, sorted by
+// table name, and of the registered queries, sorted by name
+func ExportCatalog() *Catalog {
+	catalog := &Catalog{Models: []CatalogModel{}, Queries: []CatalogQuery{}}
+	for _, meta := range RegisteredModels() {
+		catalog.Models = append(catalog.Models, catalogModel(meta))
+	}
+
+	queryRegistry.mu.RLock()
+	for _, query := range queryRegistry.queries {
+		catalog.Queries = append(catalog.Queries, query)
+	}
+	queryRegistry.mu.RUnlock()
+	sort.Slice(catalog.Queries, func(i, j int) bool { return catalog.Queries[i].Name < catalog.Queries[j].Name })
+
+	return catalog
+}
+
+// catalogModel describes the metadata of a model
+func catalogModel(meta *ModelMeta) CatalogModel {
+	model := CatalogModel{
+		Name:       meta.Type.String(),
+		Table:      meta.Table,
+		PrimaryKey: meta.PrimaryKey,
+		SoftDelete: meta.SoftDelete,
+		Columns:    make([]CatalogColumn, 0, len(meta.Columns)),
+	}
+	for _, col := range meta.Columns {
+		model.Columns = append(model.Columns, CatalogColumn{
+			Name:     col.Name,
+			Field:    col.Field,
+			Type:     col.Type.String(),
+			Nullable: col.Nullable,
+			Options:  col.Options,
+		})
+	}
+	for _, rel := range meta.Relations {
+		model.Relations = append(model.Relations, CatalogRelation{
+			Name:       rel.Name,
+			Kind:       rel.Kind.String(),
+			Table:      rel.Table,
+			ForeignKey: rel.ForeignKey,
+			References: rel.References,
+			JoinTable:  rel.JoinTable,
+		})
+	}
+	if len(meta.aliases) > 0 {
+		model.ColumnAliases = meta.ColumnAliases()
+	}
+	return model
+}
+
+// WriteCatalog writes the catalog as indented JSON
+func WriteCatalog(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ExportCatalog())
+}
+
+// CatalogHandler returns an HTTP handler serving the catalog as JSON, for
+// mounting on an internal endpoint
+func CatalogHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := WriteCatalog(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
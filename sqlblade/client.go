@@ -0,0 +1,165 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// Client wraps a database connection together with the dialect used to build SQL
+// for it and the plugins, middleware and hooks applied to its queries
+type Client struct {
+	db         *sql.DB
+	dialect    dialect.Dialect
+	hooks      *Hooks
+	middleware []Middleware
+	plugins    []Plugin
+	annotator  AnnotateFunc
+	debugger   *QueryDebugger
+	clock      Clock
+	location   *time.Location
+
+	aliasFallback func(ctx context.Context, event AliasFallback)
+
+	scopedHooks    bool
+	routingHints   bool
+	strictDecimals bool
+	strictSession  bool
+	strictQueries  bool
+	maxIdentLength int
+	noPrepare      atomic.Bool
+	readOnly       atomic.Bool
+}
+
+// Conn is implemented by the connection handles accepted by the builders
+type Conn interface {
+	*sql.DB | *Client
+}
+
+// ClientOption configures a Client created by Open
+type ClientOption func(*Client)
+
+// Open creates a new Client for an existing database connection
+func Open(db *sql.DB, opts ...ClientOption) *Client {
+	if db == nil {
+		panic(ErrNilDB)
+	}
+
+	c := newClient(db, nil)
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.dialect == nil {
+		c.dialect = dialectFor(db)
+	}
+	dbDialects.Store(db, c.dialect)
+	return c
+}
+
+// WithDialect sets the dialect of the client instead of detecting it from the
+// driver. The dialect is also used for builders and transactions created from
+// the same *sql.DB, so it only has to be configured once:
+//
+//	client := sqlblade.Open(db, sqlblade.WithDialect(dialect.NewMySQL()))
+func WithDialect(d dialect.Dialect) ClientOption {
+	return func(c *Client) {
+		c.dialect = d
+	}
+}
+
+// WithHooks scopes query hooks to the client: h runs instead of DefaultHooks,
+// e.g. when one binary talks to several databases with different logging
+// requirements. Hooks of plugins registered with Use are added to h.
+func WithHooks(h *Hooks) ClientOption {
+	return func(c *Client) {
+		c.hooks = h
+		c.scopedHooks = true
+	}
+}
+
+// WithDebugger scopes debug logging to the client: d is used instead of the
+// global debugger configured with EnableDebug and ConfigureDebug
+//
+//	client := sqlblade.Open(db, sqlblade.WithDebugger(sqlblade.NewQueryDebugger().Enable()))
+func WithDebugger(d *QueryDebugger) ClientOption {
+	return func(c *Client) {
+		c.debugger = d
+	}
+}
+
+// queryDebugger returns the debugger of the client, or the global debugger
+func (c *Client) queryDebugger() *QueryDebugger {
+	if c != nil && c.debugger != nil {
+		return c.debugger
+	}
+	return globalDebugger
+}
+
+// dbDialects remembers the dialects of the databases opened with Open,
+// configured with WithDialect or detected
+var dbDialects sync.Map // map[*sql.DB]dialect.Dialect
+
+// dialectFor returns the dialect configured for a database, or detects it from the driver
+func dialectFor(db *sql.DB) dialect.Dialect {
+	if d, ok := dbDialects.Load(db); ok {
+		if configured, ok := d.(dialect.Dialect); ok {
+			return configured
+		}
+	}
+	return detectDialect(db.Driver())
+}
+
+func newClient(db *sql.DB, d dialect.Dialect) *Client {
+	return &Client{
+		db:         db,
+		dialect:    d,
+		hooks:      NewHooks(),
+		middleware: make([]Middleware, 0),
+		plugins:    make([]Plugin, 0),
+	}
+}
+
+// resolveConn returns the Client for a connection handle. Plain *sql.DB
+// connections get a client with the configured or detected dialect and no plugins.
+func resolveConn[C Conn](conn C) *Client {
+	switch c := any(conn).(type) {
+	case *Client:
+		if c == nil || c.db == nil {
+			panic(ErrNilDB)
+		}
+		return c
+	case *sql.DB:
+		if c == nil {
+			panic(ErrNilDB)
+		}
+		return &Client{db: c, dialect: dialectFor(c)}
+	default:
+		panic(ErrNilDB)
+	}
+}
+
+// DB returns the underlying database connection
+func (c *Client) DB() *sql.DB {
+	return c.db
+}
+
+// Dialect returns the dialect used by the client
+func (c *Client) Dialect() dialect.Dialect {
+	return c.dialect
+}
+
+// Hooks returns the hooks that run for queries of this client, in addition to
+// DefaultHooks unless the client was opened WithHooks
+func (c *Client) Hooks() *Hooks {
+	return c.hooks
+}
+
+// Close closes the underlying database connection
+func (c *Client) Close() error {
+	return c.db.Close()
+}
@@ -0,0 +1,56 @@
+package sqlblade
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time to a Client. Hook event and debug timings and the
+// Scheduler's maintenance windows read it, so tests can freeze or advance time
+// by configuring their own Clock with WithClock, e.g. a sqlbladetest.Clock.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// Sleep waits until d has elapsed or ctx is done, returning ctx.Err() then
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// SystemClock is the Clock of the system time, used by default
+type SystemClock struct{}
+
+// Now implements Clock
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep implements Clock
+func (SystemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// WithClock sets the clock the client reads the time from
+func WithClock(clock Clock) ClientOption {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// Clock returns the clock of the client, SystemClock unless set with WithClock
+func (c *Client) Clock() Clock {
+	if c == nil || c.clock == nil {
+		return SystemClock{}
+	}
+	return c.clock
+}
+
+// since returns the time elapsed since t on the client's clock
+func (c *Client) since(t time.Time) time.Duration {
+	return c.Clock().Now().Sub(t)
+}
@@ -0,0 +1,170 @@
+package sqlblade
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Codec converts the values of a field to and from the form stored in its
+// column, e.g. to compress large text or protobuf-encode blobs. Codecs are
+// applied to the fields of models on INSERT and UPDATE, to the values given
+// to UpdateBuilder.Set for their columns, and on scan; values given to Where
+// are bound as is.
+type Codec interface {
+	// Serialize returns the value stored for the field value v
+	Serialize(v interface{}) (driver.Value, error)
+	// Deserialize decodes the scanned value src, never NULL, into dest, a
+	// pointer to the field
+	Deserialize(src interface{}, dest interface{}) error
+}
+
+// funcCodec is a Codec of typed functions
+type funcCodec[V any] struct {
+	serialize   func(V) (driver.Value, error)
+	deserialize func(src interface{}) (V, error)
+}
+
+// NewCodec returns a Codec of fields of type V from typed functions
+func NewCodec[V any](serialize func(V) (driver.Value, error), deserialize func(src interface{}) (V, error)) Codec {
+	return funcCodec[V]{serialize: serialize, deserialize: deserialize}
+}
+
+// Serialize implements Codec
+func (c funcCodec[V]) Serialize(v interface{}) (driver.Value, error) {
+	value, ok := v.(V)
+	if !ok {
+		return nil, fmt.Errorf("sqlblade: codec of %s can't serialize %T", reflect.TypeOf((*V)(nil)).Elem(), v)
+	}
+	return c.serialize(value)
+}
+
+// Deserialize implements Codec
+func (c funcCodec[V]) Deserialize(src interface{}, dest interface{}) error {
+	ptr, ok := dest.(*V)
+	if !ok {
+		return fmt.Errorf("sqlblade: codec of %s can't deserialize into %T", reflect.TypeOf((*V)(nil)).Elem(), dest)
+	}
+	value, err := c.deserialize(src)
+	if err != nil {
+		return err
+	}
+	*ptr = value
+	return nil
+}
+
+var codecRegistry = struct {
+	mu      sync.RWMutex
+	types   map[reflect.Type]Codec
+	columns map[reflect.Type]map[string]Codec
+}{types: make(map[reflect.Type]Codec), columns: make(map[reflect.Type]map[string]Codec)}
+
+// RegisterCodec applies codec to the fields of type V of every model, and to
+// the pointer fields to V. Register codecs before using the models.
+func RegisterCodec[V any](codec Codec) {
+	codecRegistry.mu.Lock()
+	codecRegistry.types[reflect.TypeOf((*V)(nil)).Elem()] = codec
+	codecRegistry.mu.Unlock()
+	clearStructCache()
+}
+
+// RegisterColumnCodec applies codec to the field of a column of the model T,
+// over a codec registered for its type
+func RegisterColumnCodec[T any](column string, codec Codec) {
+	typ := modelType[T]()
+	codecRegistry.mu.Lock()
+	if codecRegistry.columns[typ] == nil {
+		codecRegistry.columns[typ] = make(map[string]Codec)
+	}
+	codecRegistry.columns[typ][strings.ToLower(column)] = codec
+	codecRegistry.mu.Unlock()
+	structCache.Delete(typ)
+}
+
+// clearStructCache drops the cached struct information of every type
+func clearStructCache() {
+	structCache.Range(func(key, _ interface{}) bool {
+		structCache.Delete(key)
+		return true
+	})
+}
+
+// setCodecs sets the codecs registered for the fields of the model typ
+func setCodecs(typ reflect.Type, fields []fieldInfo) {
+	codecRegistry.mu.RLock()
+	defer codecRegistry.mu.RUnlock()
+	if len(codecRegistry.types) == 0 && len(codecRegistry.columns[typ]) == 0 {
+		return
+	}
+	for i, field := range fields {
+		if codec, ok := codecRegistry.columns[typ][field.dbColumn]; ok {
+			fields[i].codec = codec
+		} else if codec, ok := codecRegistry.types[field.fieldType]; ok {
+			fields[i].codec = codec
+		}
+	}
+}
+
+// hasCodecs reports whether fields of the struct have codecs
+func (info *structInfo) hasCodecs() bool {
+	for _, field := range info.fields {
+		if field.codec != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// codecValue is a field value bound through its codec
+type codecValue struct {
+	codec Codec
+	v     interface{}
+}
+
+// Value implements driver.Valuer. Nil pointers are bound as NULL.
+func (c codecValue) Value() (driver.Value, error) {
+	if isNilValue(c.v) {
+		return nil, nil
+	}
+	rv := reflect.ValueOf(c.v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	value, err := c.codec.Serialize(rv.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("sqlblade: serializing value: %w", err)
+	}
+	return value, nil
+}
+
+// codecArg returns the value to bind for a column of the model, through the
+// codec of its field if any
+func codecArg(info *structInfo, column string, value interface{}) interface{} {
+	if _, ok := value.(codecValue); ok || isOmitted(value) {
+		return value
+	}
+	for _, field := range info.fields {
+		if field.dbColumn == column && field.codec != nil {
+			return codecValue{codec: field.codec, v: value}
+		}
+	}
+	return value
+}
+
+// scanCodec decodes a scanned value into a field through its codec; NULL
+// resets the field
+func scanCodec(field reflect.Value, info fieldInfo, src interface{}) error {
+	if src == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	if info.isPtr {
+		if field.IsNil() {
+			field.Set(reflect.New(info.fieldType))
+		}
+		return info.codec.Deserialize(src, field.Interface())
+	}
+	return info.codec.Deserialize(src, field.Addr().Interface())
+}
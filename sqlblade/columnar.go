@@ -0,0 +1,289 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ColumnKind is the type of the values of a column of a ColumnBatch
+type ColumnKind int
+
+const (
+	// ColumnString columns hold strings, including the exact text of decimals
+	ColumnString ColumnKind = iota
+	// ColumnInt columns hold int64s
+	ColumnInt
+	// ColumnFloat columns hold float64s
+	ColumnFloat
+	// ColumnBool columns hold bools
+	ColumnBool
+	// ColumnBytes columns hold []bytes
+	ColumnBytes
+	// ColumnTime columns hold time.Times
+	ColumnTime
+)
+
+// String returns the name of the kind
+func (k ColumnKind) String() string {
+	switch k {
+	case ColumnInt:
+		return "int"
+	case ColumnFloat:
+		return "float"
+	case ColumnBool:
+		return "bool"
+	case ColumnBytes:
+		return "bytes"
+	case ColumnTime:
+		return "time"
+	default:
+		return "string"
+	}
+}
+
+// BatchColumn describes a column of a ColumnBatch
+type BatchColumn struct {
+	Name         string
+	DatabaseType string // as reported by the driver, e.g. "INT8"
+	Kind         ColumnKind
+}
+
+// ColumnBatch is a batch of result rows stored column by column, for columnar
+// formats such as Arrow and Parquet. Values[c][r] is the value of column c in
+// row r, of the Go type of the column's Kind, or nil for NULL.
+type ColumnBatch struct {
+	Columns []BatchColumn
+	Values  [][]interface{}
+	Rows    int
+}
+
+// ExecuteBatches executes the query and streams its rows to fn in column
+// batches of up to size rows, so that large exports are never held in
+// memory. The columns and their kinds are the same in every batch: kinds
+// come from the database types, or from the first batch's values for
+// untyped columns such as SQLite expressions. Scan hooks of T are not run.
+func (qb *QueryBuilder[T]) ExecuteBatches(ctx context.Context, size int, fn func(*ColumnBatch) error) error {
+	if ctx == nil {
+		return ErrNilContext
+	}
+	if fn == nil {
+		return ErrNilIterateFunc
+	}
+
+	sqlStr, args := qb.buildSQL()
+	return qb.query(ctx, sqlStr, args, func(rows *sql.Rows) error {
+		return scanBatches(rows, size, qb.client.timeLocation(ctx), fn)
+	})
+}
+
+// ExecuteBatches executes the raw query and streams its rows to fn in column
+// batches, see QueryBuilder.ExecuteBatches
+func (rq *RawQuery[T]) ExecuteBatches(ctx context.Context, size int, fn func(*ColumnBatch) error) error {
+	if ctx == nil {
+		return ErrNilContext
+	}
+	if fn == nil {
+		return ErrNilIterateFunc
+	}
+
+	stmt := &Statement{Operation: "RAW", SQL: rq.query, Args: rq.args, Primary: true, Tags: rq.tags}
+	return rq.client.queryHooked(ctx, rq.tx, stmt, func(rows *sql.Rows) error {
+		return scanBatches(rows, size, rq.client.timeLocation(ctx), fn)
+	})
+}
+
+// scanBatches scans rows into column batches of up to size rows, passing
+// each to fn
+func scanBatches(rows *sql.Rows, size int, loc *time.Location, fn func(*ColumnBatch) error) error {
+	if size <= 0 {
+		size = resultInitialCapacity
+	}
+	names, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	columns := make([]BatchColumn, len(names))
+	known := make([]bool, len(names))
+	for i, name := range names {
+		typeName := strings.ToUpper(types[i].DatabaseTypeName())
+		columns[i] = BatchColumn{Name: name, DatabaseType: typeName}
+		columns[i].Kind, known[i] = columnKindOf(typeName)
+	}
+
+	buf := globalScanBufferPool.Get(len(names))
+	defer globalScanBufferPool.Put(buf)
+
+	first := true
+	batch := newColumnBatch(columns, size)
+	flush := func() error {
+		if first {
+			// Untyped columns take the kind of their first value
+			first = false
+			for c := range columns {
+				if !known[c] {
+					columns[c].Kind = valueKind(batch.Values[c])
+				}
+			}
+		}
+		for c, column := range columns {
+			for r, value := range batch.Values[c] {
+				v, err := columnValue(value, column.Kind)
+				if err != nil {
+					return fmt.Errorf("sqlblade: column %s: %w", column.Name, err)
+				}
+				batch.Values[c][r] = v
+			}
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		batch = newColumnBatch(columns, size)
+		return nil
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(buf.ptrs...); err != nil {
+			return fmt.Errorf("sqlblade: failed to scan row: %w", err)
+		}
+		for c := range columns {
+			batch.Values[c] = append(batch.Values[c], normalizeMapValue(buf.values[c], columns[c].DatabaseType, loc))
+		}
+		batch.Rows++
+		if batch.Rows == size {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if batch.Rows > 0 || first {
+		return flush()
+	}
+	return nil
+}
+
+// newColumnBatch returns an empty batch of the columns
+func newColumnBatch(columns []BatchColumn, size int) *ColumnBatch {
+	batch := &ColumnBatch{Columns: columns, Values: make([][]interface{}, len(columns))}
+	for c := range batch.Values {
+		batch.Values[c] = make([]interface{}, 0, size)
+	}
+	return batch
+}
+
+// columnKindOf returns the kind of the values of a column of the given
+// database type, and whether the type is known
+func columnKindOf(typeName string) (ColumnKind, bool) {
+	switch {
+	case typeName == "":
+		return ColumnString, false
+	case strings.HasSuffix(typeName, "INT") || strings.HasPrefix(typeName, "INT") && typeName != "INTERVAL" || typeName == "YEAR":
+		return ColumnInt, true
+	case typeName == "FLOAT" || typeName == "DOUBLE" || typeName == "REAL" || strings.HasPrefix(typeName, "FLOAT"):
+		return ColumnFloat, true
+	case typeName == "BOOL" || typeName == "BOOLEAN":
+		return ColumnBool, true
+	case isBinaryType(typeName):
+		return ColumnBytes, true
+	case typeName == "DATE" || typeName == "DATETIME" || strings.HasPrefix(typeName, "TIMESTAMP"):
+		return ColumnTime, true
+	}
+	return ColumnString, true
+}
+
+// valueKind returns the kind of the first non-NULL value, ColumnString when
+// all are NULL
+func valueKind(values []interface{}) ColumnKind {
+	for _, value := range values {
+		switch value.(type) {
+		case nil:
+			continue
+		case int64:
+			return ColumnInt
+		case float64:
+			return ColumnFloat
+		case bool:
+			return ColumnBool
+		case []byte:
+			return ColumnBytes
+		case time.Time:
+			return ColumnTime
+		}
+		return ColumnString
+	}
+	return ColumnString
+}
+
+// columnValue converts a normalized value to the Go type of kind
+func columnValue(value interface{}, kind ColumnKind) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch kind {
+	case ColumnInt:
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case bool:
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		}
+	case ColumnFloat:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int64:
+			return float64(v), nil
+		case string:
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return f, nil
+			}
+		}
+	case ColumnBool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case int64:
+			return v != 0, nil
+		}
+	case ColumnBytes:
+		switch v := value.(type) {
+		case []byte:
+			return v, nil
+		case string:
+			return []byte(v), nil
+		}
+	case ColumnTime:
+		if t, ok := value.(time.Time); ok {
+			return t, nil
+		}
+		var t time.Time
+		if err := setTimeField(reflect.ValueOf(&t).Elem(), reflect.ValueOf(value)); err == nil {
+			return t, nil
+		}
+	default:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case []byte:
+			return string(v), nil
+		case time.Time:
+			return v.Format(time.RFC3339Nano), nil
+		}
+		return fmt.Sprint(value), nil
+	}
+	return nil, fmt.Errorf("cannot convert %T to %s", value, kind)
+}
@@ -0,0 +1,306 @@
+package sqlblade
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// CompiledQuery is a SELECT frozen by Compile. Its SQL is built once; only the
+// arguments are bound on each execution.
+type CompiledQuery[T any] struct {
+	qb   *QueryBuilder[T]
+	sql  string
+	args []interface{}
+}
+
+// Compile freezes the query into a CompiledQuery for hot paths. Changes made
+// to the builder afterwards don't affect the compiled query.
+func (qb *QueryBuilder[T]) Compile() *CompiledQuery[T] {
+	sqlStr, args := qb.buildSQL()
+	frozen := *qb
+	return &CompiledQuery[T]{qb: &frozen, sql: sqlStr, args: args}
+}
+
+// SQL returns the SQL of the compiled query
+func (cq *CompiledQuery[T]) SQL() string {
+	return cq.sql
+}
+
+// Args returns the arguments the query was compiled with
+func (cq *CompiledQuery[T]) Args() []interface{} {
+	return append([]interface{}(nil), cq.args...)
+}
+
+// NumArgs returns the number of arguments bound on execution
+func (cq *CompiledQuery[T]) NumArgs() int {
+	return len(cq.args)
+}
+
+// Execute runs the compiled query. Without args the arguments it was compiled
+// with are used; otherwise args replace them in placeholder order.
+func (cq *CompiledQuery[T]) Execute(ctx context.Context, args ...interface{}) ([]T, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+	bound, err := cq.bind(args)
+	if err != nil {
+		return nil, err
+	}
+	return cq.qb.executeSQL(ctx, cq.sql, bound)
+}
+
+// Iterate runs the compiled query like Execute, calling fn for every row as
+// it is scanned
+func (cq *CompiledQuery[T]) Iterate(ctx context.Context, fn func(T) error, args ...interface{}) error {
+	if ctx == nil {
+		return ErrNilContext
+	}
+	if fn == nil {
+		return ErrNilIterateFunc
+	}
+	bound, err := cq.bind(args)
+	if err != nil {
+		return err
+	}
+	return cq.qb.iterateSQL(ctx, cq.sql, bound, fn)
+}
+
+// bind returns the arguments to execute the query with
+func (cq *CompiledQuery[T]) bind(args []interface{}) ([]interface{}, error) {
+	if len(args) == 0 {
+		return cq.args, nil
+	}
+	if len(args) != len(cq.args) {
+		return nil, fmt.Errorf("%w: got %d, want %d", ErrArgCount, len(args), len(cq.args))
+	}
+	return args, nil
+}
+
+// maxSelectSQLCacheSize bounds the number of query shapes whose SQL is cached
+const maxSelectSQLCacheSize = 4096
+
+// selectSQLCache holds the SQL built for query shapes: everything about a
+// query except its argument values
+var (
+	selectSQLCache     sync.Map // map[string]string
+	selectSQLCacheSize atomic.Int64
+)
+
+// cacheSelectSQL stores the SQL of a query shape while the cache has room
+func cacheSelectSQL(key, sqlStr string) {
+	if selectSQLCacheSize.Load() >= maxSelectSQLCacheSize {
+		return
+	}
+	if _, loaded := selectSQLCache.LoadOrStore(key, sqlStr); !loaded {
+		selectSQLCacheSize.Add(1)
+	}
+}
+
+// shapeWriter writes a query shape key
+type shapeWriter struct {
+	strings.Builder
+}
+
+// part writes a length-prefixed string, so that adjacent parts can't run together
+func (w *shapeWriter) part(s string) {
+	w.WriteString(strconv.Itoa(len(s)))
+	w.WriteByte(':')
+	w.WriteString(s)
+}
+
+// flag writes a boolean
+func (w *shapeWriter) flag(b bool) {
+	if b {
+		w.WriteByte('1')
+	} else {
+		w.WriteByte('0')
+	}
+}
+
+// shape returns the shape key of the SELECT statement and its arguments, in
+// the order renderSelectSQL binds them
+func (qb *QueryBuilder[T]) shape(extra string) (string, []interface{}) {
+	var w shapeWriter
+	args := make([]interface{}, 0, argsInitialCapacity)
+
+	w.part(qb.dialect.Name())
+	w.part(aliasKey(qb.dialect))
+	w.flag(qb.distinct)
+	w.flag(qb.selectMapped)
+	// Select() lists the mapped columns of the model, which tables share
+	typ := modelType[T]()
+	w.part(typ.PkgPath())
+	w.part(typ.String())
+	w.WriteString(strconv.Itoa(len(qb.selectCols)))
+	for _, col := range qb.selectCols {
+		w.part(col)
+	}
+	w.WriteString(strconv.Itoa(len(qb.selectExprs)))
+	for _, expr := range qb.selectExprs {
+		w.part(expr.sql)
+		args = append(args, expr.args...)
+	}
+	w.WriteString(strconv.Itoa(len(qb.windows)))
+	if len(qb.windows) > 0 {
+		w.part(qb.windowSQL())
+	}
+	w.part(extra)
+	w.part(qb.tableName)
+	w.part(qb.alias)
+
+	w.WriteString(strconv.Itoa(len(qb.joins)))
+	for i, join := range qb.joins {
+		w.WriteString(strconv.Itoa(int(join.Type)))
+		w.part(join.Table)
+		w.part(join.Alias)
+		w.part(join.Condition)
+		if sub := qb.joinSubqueries[i]; sub != nil {
+			key, subArgs := sub.shapeKey()
+			w.part(key)
+			args = append(args, subArgs...)
+		}
+	}
+
+	w.WriteByte('W')
+	shapeConditions(&w, qb.dialect, qb.whereClauses, &args)
+
+	w.WriteString(strconv.Itoa(len(qb.groupBy)))
+	for _, item := range qb.groupBy {
+		w.flag(item.raw)
+		w.part(item.expr)
+	}
+
+	w.WriteByte('H')
+	shapeConditions(&w, qb.dialect, qb.having, &args)
+
+	w.WriteString(strconv.Itoa(len(qb.setOps)))
+	for _, op := range qb.setOps {
+		w.part(op.kind)
+		key, opArgs := op.query.shape("")
+		w.part(key)
+		args = append(args, opArgs...)
+	}
+
+	// ORDER BY follows the queries of set operations
+	w.WriteString(strconv.Itoa(len(qb.orderBy)))
+	for _, ob := range qb.orderBy {
+		w.WriteString(strconv.Itoa(int(ob.Order)))
+		w.flag(ob.Raw)
+		w.part(ob.Column)
+		args = append(args, ob.Args...)
+	}
+
+	w.WriteByte('F')
+	w.WriteString(strconv.Itoa(int(qb.lock.Strength)))
+	w.WriteString(strconv.Itoa(int(qb.lock.Wait)))
+
+	if qb.limit != nil || qb.offset != nil {
+		_, bound := qb.dialect.(dialect.LimitOffsetBinder)
+		bound = bound && bindLimitOffset.Load()
+		w.WriteByte('L')
+		w.flag(bound)
+		for _, v := range []*int{qb.limit, qb.offset} {
+			w.flag(v != nil)
+			if v != nil && !bound {
+				w.WriteString(strconv.Itoa(*v))
+			}
+			w.WriteByte(';')
+		}
+		paramIndex := 0
+		_, limitArgs := buildLimitOffset(qb.dialect, qb.limit, qb.offset, &paramIndex)
+		args = append(args, limitArgs...)
+	}
+
+	return w.String(), args
+}
+
+// shapeConditions writes the shape of the clauses, appending their arguments
+// as buildConditions does
+func shapeConditions(w *shapeWriter, d dialect.Dialect, clauses []WhereClause, args *[]interface{}) {
+	w.WriteByte('(')
+	for _, clause := range clauses {
+		w.flag(clause.And)
+		shapeCondition(w, d, clause, args)
+	}
+	w.WriteByte(')')
+}
+
+// shapeCondition writes the shape of a single condition, appending its
+// arguments as buildCondition does
+func shapeCondition(w *shapeWriter, d dialect.Dialect, clause WhereClause, args *[]interface{}) {
+	if group, ok := clause.Value.(*ConditionGroup); ok {
+		if group.not {
+			w.WriteByte('N')
+		}
+		w.WriteByte('G')
+		shapeConditions(w, d, group.clauses, args)
+		return
+	}
+
+	if raw, ok := clause.Value.(rawExpr); ok {
+		w.WriteByte('R')
+		w.part(raw.sql)
+		*args = append(*args, raw.args...)
+		return
+	}
+
+	op := strings.ToUpper(strings.TrimSpace(clause.Operator))
+	w.part(clause.Column)
+	w.part(op)
+	if !isValidOperator(op) {
+		return
+	}
+
+	switch op {
+	case "IS NULL", "IS NOT NULL":
+	case "IN", "NOT IN":
+		if values, ok := inValues(clause.Value); ok {
+			values = normalizeInList(d, values)
+			w.WriteByte('L')
+			w.WriteString(strconv.Itoa(len(values)))
+			*args = append(*args, values...)
+		} else if subquery, ok := clause.Value.(*Subquery); ok {
+			shapeSubquery(w, subquery, args)
+		} else if param, ok := clause.Value.(NamedParam); ok {
+			w.WriteByte('P')
+			*args = append(*args, param)
+		}
+	case "BETWEEN", "NOT BETWEEN":
+		if values, ok := clause.Value.([]interface{}); ok && len(values) == 2 {
+			w.WriteByte('B')
+			*args = append(*args, values[0], values[1])
+		}
+	default:
+		if ref, ok := clause.Value.(ColumnRef); ok {
+			w.WriteByte('C')
+			w.part(string(ref))
+		} else if subquery, ok := clause.Value.(*Subquery); ok {
+			shapeSubquery(w, subquery, args)
+		} else if expr, ok := clause.Value.(Expression); ok {
+			w.WriteByte('X')
+			w.part(expr.sql)
+			*args = append(*args, expr.args...)
+		} else {
+			if _, ok := clause.Value.(JSONValue); ok {
+				w.WriteByte('J')
+			}
+			w.WriteByte('V')
+			*args = append(*args, clause.Value)
+		}
+	}
+}
+
+// shapeSubquery writes the shape of a subquery compared in a condition,
+// appending its arguments
+func shapeSubquery(w *shapeWriter, subquery *Subquery, args *[]interface{}) {
+	key, subArgs := subquery.shapeKey()
+	w.WriteByte('S')
+	w.part(key)
+	*args = append(*args, subArgs...)
+}
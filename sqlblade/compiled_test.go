@@ -0,0 +1,42 @@
+package sqlblade_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+)
+
+type txUserWithEmail struct {
+	ID    int    `db:"id"`
+	Name  string `db:"name"`
+	Email string `db:"email"`
+}
+
+func (txUserWithEmail) TableName() string { return "users" }
+
+func TestSelectMappedCacheKeyedByModel(t *testing.T) {
+	ctx := context.Background()
+	db, rec := openRecorder(t)
+
+	if _, err := sqlblade.Query[txUserWithEmail](db).Select().Where("id", "=", 1).Execute(ctx); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if _, err := sqlblade.Query[txUser](db).Select().Where("id", "=", 1).Execute(ctx); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	statements := rec.statements()
+	want := []string{
+		`SELECT "id", "name", "email" FROM "users" WHERE "id" = $1`,
+		`SELECT "id", "name" FROM "users" WHERE "id" = $1`,
+	}
+	if len(statements) != len(want) {
+		t.Fatalf("statements = %q, want %q", statements, want)
+	}
+	for i := range want {
+		if statements[i] != want[i] {
+			t.Errorf("statement %d = %q, want %q", i, statements[i], want[i])
+		}
+	}
+}
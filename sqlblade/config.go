@@ -0,0 +1,194 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Config holds the settings that can change while the application runs,
+// shared by every client. Update it with SetConfig, e.g. from a remote
+// config service, read it from the environment with ConfigFromEnv, or reload
+// it on SIGHUP with ReloadConfigOnSignal.
+type Config struct {
+	// Debug enables the global query debugger, as EnableDebug does
+	Debug bool
+	// SlowQueryThreshold is the duration above which the global debugger
+	// flags queries as slow
+	SlowQueryThreshold time.Duration
+	// DefaultLimit is the LIMIT of queries executed without one, guarding
+	// services against loading whole tables; 0 for none
+	DefaultLimit int
+	// MaxRetries is the number of times a SELECT outside a transaction is
+	// sent again after a transient error: a broken connection, a deadlock, a
+	// serialization failure or a busy SQLite database; 0 for none
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled for each next one
+	RetryBackoff time.Duration
+}
+
+var runtimeConfig atomic.Pointer[Config]
+
+func init() {
+	runtimeConfig.Store(&Config{RetryBackoff: 50 * time.Millisecond})
+}
+
+// CurrentConfig returns the current runtime configuration
+func CurrentConfig() Config {
+	cfg := *runtimeConfig.Load()
+	cfg.Debug = globalDebugger.enabled.Load()
+	cfg.SlowQueryThreshold = time.Duration(globalDebugger.slowQueryThreshold.Load())
+	return cfg
+}
+
+// SetConfig replaces the runtime configuration; it applies to the statements
+// executed from then on. Change a copy of CurrentConfig to keep the other
+// settings.
+func SetConfig(cfg Config) {
+	if cfg.Debug {
+		globalDebugger.Enable()
+	} else {
+		globalDebugger.Disable()
+	}
+	globalDebugger.SetSlowQueryThreshold(cfg.SlowQueryThreshold)
+	runtimeConfig.Store(&cfg)
+}
+
+// ConfigFromEnv returns the current configuration with the settings given by
+// the environment variables SQLBLADE_DEBUG (true/false),
+// SQLBLADE_SLOW_QUERY_THRESHOLD and SQLBLADE_RETRY_BACKOFF (durations such as
+// "250ms"), SQLBLADE_DEFAULT_LIMIT and SQLBLADE_MAX_RETRIES
+func ConfigFromEnv() (Config, error) {
+	cfg := CurrentConfig()
+	var err error
+	parse := func(name string, set func(value string) error) {
+		if value, ok := os.LookupEnv(name); ok && err == nil {
+			if parseErr := set(strings.TrimSpace(value)); parseErr != nil {
+				err = fmt.Errorf("sqlblade: %s: %w", name, parseErr)
+			}
+		}
+	}
+	parse("SQLBLADE_DEBUG", func(v string) (err error) {
+		cfg.Debug, err = strconv.ParseBool(v)
+		return err
+	})
+	parse("SQLBLADE_SLOW_QUERY_THRESHOLD", func(v string) (err error) {
+		cfg.SlowQueryThreshold, err = time.ParseDuration(v)
+		return err
+	})
+	parse("SQLBLADE_DEFAULT_LIMIT", func(v string) (err error) {
+		cfg.DefaultLimit, err = strconv.Atoi(v)
+		return err
+	})
+	parse("SQLBLADE_MAX_RETRIES", func(v string) (err error) {
+		cfg.MaxRetries, err = strconv.Atoi(v)
+		return err
+	})
+	parse("SQLBLADE_RETRY_BACKOFF", func(v string) (err error) {
+		cfg.RetryBackoff, err = time.ParseDuration(v)
+		return err
+	})
+	return cfg, err
+}
+
+// ReloadConfigOnSignal applies the configuration returned by load each time
+// the process receives one of signals, SIGHUP by default, until ctx is done.
+// Without signals on platforms lacking SIGHUP, it does nothing.
+// load may read a file or a config service; when it fails, the error is
+// logged and the configuration is kept.
+func ReloadConfigOnSignal(ctx context.Context, load func() (Config, error), signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = reloadSignals
+	}
+	if len(signals) == 0 {
+		return
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				cfg, err := load()
+				if err != nil {
+					log.Printf("sqlblade: reloading the configuration: %v", err)
+					continue
+				}
+				SetConfig(cfg)
+			}
+		}
+	}()
+}
+
+// defaultLimit returns the query with the configured default LIMIT when it
+// has none
+func (qb *QueryBuilder[T]) defaultLimit() *QueryBuilder[T] {
+	limit := runtimeConfig.Load().DefaultLimit
+	if limit <= 0 || qb.limit != nil || len(qb.setOps) > 0 {
+		return qb
+	}
+	clone := *qb
+	clone.limit = &limit
+	return &clone
+}
+
+// retryQuery sends a SELECT failing with a transient error again, as many
+// times as configured. Statements of transactions are not retried, as the
+// error may have aborted the transaction.
+func (c *Client) retryQuery(ctx context.Context, tx *sql.Tx, sqlStr string, query func() (*sql.Rows, error)) (*sql.Rows, error) {
+	rows, err := query()
+	if err == nil || tx != nil {
+		return rows, err
+	}
+	cfg := runtimeConfig.Load()
+	for attempt := 0; attempt < cfg.MaxRetries && err != nil && isTransientError(err) && isSelectSQL(sqlStr); attempt++ {
+		if sleepErr := c.Clock().Sleep(ctx, cfg.RetryBackoff<<attempt); sleepErr != nil {
+			return nil, err
+		}
+		rows, err = query()
+	}
+	return rows, err
+}
+
+// isTransientError reports whether a statement failing with err may succeed
+// when sent again
+func isTransientError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, transient := range []string{
+		"deadlock", "40p01", "40001", "could not serialize access", "serialization failure",
+		"connection reset", "broken pipe", "database is locked",
+	} {
+		if strings.Contains(msg, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSelectSQL reports whether sqlStr is a SELECT, after its leading comments
+func isSelectSQL(sqlStr string) bool {
+	s := strings.TrimSpace(sqlStr)
+	for strings.HasPrefix(s, "/*") {
+		end := strings.Index(s, "*/")
+		if end < 0 {
+			return false
+		}
+		s = strings.TrimSpace(s[end+2:])
+	}
+	return len(s) >= 6 && strings.EqualFold(s[:6], "SELECT")
+}
@@ -0,0 +1,9 @@
+//go:build js || plan9
+
+package sqlblade
+
+import "os"
+
+// reloadSignals are the default signals of ReloadConfigOnSignal; the
+// platform has no SIGHUP
+var reloadSignals []os.Signal
@@ -0,0 +1,11 @@
+//go:build !js && !plan9
+
+package sqlblade
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadSignals are the default signals of ReloadConfigOnSignal
+var reloadSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,127 @@
+package sqlblade
+
+import "strings"
+
+// onConflict describes how an INSERT handles rows that violate a unique constraint
+type onConflict struct {
+	target   []string
+	doUpdate bool
+	update   []string
+}
+
+// OnConflict sets the conflict target columns of the INSERT. Follow it with
+// DoNothing or DoUpdate; without either, conflicting rows are skipped. MySQL
+// ignores the target and uses ON DUPLICATE KEY UPDATE, with a no-op update of
+// the first target column for DoNothing.
+func (ib *InsertBuilder[T]) OnConflict(columns ...string) *InsertBuilder[T] {
+	ib.conflict = &onConflict{target: columns}
+	return ib
+}
+
+// DoNothing skips rows that conflict with existing rows
+func (ib *InsertBuilder[T]) DoNothing() *InsertBuilder[T] {
+	if ib.conflict == nil {
+		ib.conflict = &onConflict{}
+	}
+	ib.conflict.doUpdate = false
+	ib.conflict.update = nil
+	return ib
+}
+
+// DoUpdate updates the given columns of conflicting rows with the inserted
+// values. Without columns, every inserted column except the conflict target
+// is updated. PostgreSQL and SQLite require a conflict target.
+func (ib *InsertBuilder[T]) DoUpdate(columns ...string) *InsertBuilder[T] {
+	if ib.conflict == nil {
+		ib.conflict = &onConflict{}
+	}
+	ib.conflict.doUpdate = true
+	ib.conflict.update = columns
+	return ib
+}
+
+// writeConflict writes the conflict clause of the INSERT, if any
+func (ib *InsertBuilder[T]) writeConflict(buf *strings.Builder, columns []string) {
+	c := ib.conflict
+	if c == nil {
+		return
+	}
+
+	mysql := ib.dialect.Name() == dialectMySQL
+	if !c.doUpdate {
+		if mysql {
+			// A no-op update rather than INSERT IGNORE, which would also turn
+			// errors such as truncation or foreign key violations into warnings
+			var col string
+			switch {
+			case len(c.target) > 0:
+				col = c.target[0]
+			case len(columns) > 0:
+				col = columns[0]
+			default:
+				return
+			}
+			quoted := ib.dialect.QuoteIdentifier(col)
+			buf.WriteString(" ON DUPLICATE KEY UPDATE " + quoted + " = " + quoted)
+			return
+		}
+		buf.WriteString(" ON CONFLICT")
+		ib.writeConflictTarget(buf)
+		buf.WriteString(" DO NOTHING")
+		return
+	}
+
+	update := c.update
+	if len(update) == 0 {
+		update = make([]string, 0, len(columns))
+		for _, col := range columns {
+			if !containsString(c.target, col) {
+				update = append(update, col)
+			}
+		}
+	}
+
+	if mysql {
+		buf.WriteString(" ON DUPLICATE KEY UPDATE ")
+	} else {
+		buf.WriteString(" ON CONFLICT")
+		ib.writeConflictTarget(buf)
+		buf.WriteString(" DO UPDATE SET ")
+	}
+
+	for i, col := range update {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		quoted := ib.dialect.QuoteIdentifier(col)
+		buf.WriteString(quoted)
+		if mysql {
+			buf.WriteString(" = VALUES(" + quoted + ")")
+		} else {
+			buf.WriteString(" = EXCLUDED." + quoted)
+		}
+	}
+}
+
+func (ib *InsertBuilder[T]) writeConflictTarget(buf *strings.Builder) {
+	if len(ib.conflict.target) == 0 {
+		return
+	}
+	buf.WriteString(" (")
+	for i, col := range ib.conflict.target {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(ib.dialect.QuoteIdentifier(col))
+	}
+	buf.WriteString(")")
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
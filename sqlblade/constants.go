@@ -2,6 +2,8 @@ package sqlblade
 
 const (
 	dialectPostgres = "postgres"
+	dialectMySQL    = "mysql"
+	dialectSQLite   = "sqlite"
 
 	// Buffer sizes for SQL building
 	sqlBuilderBufferSize  = 512
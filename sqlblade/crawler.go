@@ -0,0 +1,226 @@
+package sqlblade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// defaultCrawlBatchSize is the number of rows a Crawler reads per batch by default
+const defaultCrawlBatchSize = 500
+
+// CheckpointStore persists the positions of crawlers, so that a crawl can
+// resume where it stopped after a restart
+type CheckpointStore interface {
+	// Load returns the saved position of a crawl, or "" when there is none
+	Load(ctx context.Context, name string) (Cursor, error)
+	// Save stores the position of a crawl; "" clears it
+	Save(ctx context.Context, name string, cursor Cursor) error
+}
+
+// MemoryCheckpointStore keeps checkpoints in memory, e.g. for tests and
+// crawls that don't need to survive restarts
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]Cursor
+}
+
+// NewMemoryCheckpointStore creates an empty in-memory checkpoint store
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{checkpoints: make(map[string]Cursor)}
+}
+
+// Load implements CheckpointStore
+func (s *MemoryCheckpointStore) Load(_ context.Context, name string) (Cursor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkpoints[name], nil
+}
+
+// Save implements CheckpointStore
+func (s *MemoryCheckpointStore) Save(_ context.Context, name string, cursor Cursor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cursor == "" {
+		delete(s.checkpoints, name)
+	} else {
+		s.checkpoints[name] = cursor
+	}
+	return nil
+}
+
+// FileCheckpointStore keeps each checkpoint in a file named after the crawl
+// in a directory
+type FileCheckpointStore struct {
+	dir string
+}
+
+// NewFileCheckpointStore creates a checkpoint store writing to dir, which is
+// created when missing
+func NewFileCheckpointStore(dir string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCheckpointStore{dir: dir}, nil
+}
+
+// Load implements CheckpointStore
+func (s *FileCheckpointStore) Load(_ context.Context, name string) (Cursor, error) {
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return Cursor(data), nil
+}
+
+// Save implements CheckpointStore. The file is replaced atomically, so a
+// crash while saving keeps the previous checkpoint.
+func (s *FileCheckpointStore) Save(_ context.Context, name string, cursor Cursor) error {
+	path := s.path(name)
+	if cursor == "" {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(s.dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.WriteString(string(cursor)); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *FileCheckpointStore) path(name string) string {
+	return filepath.Join(s.dir, filepath.Base(name)+".checkpoint")
+}
+
+// CrawlerOptions configures a Crawler
+type CrawlerOptions struct {
+	// Name identifies the crawl in the checkpoint store; defaults to the table name
+	Name string
+	// BatchSize is the number of rows per batch; defaults to 500
+	BatchSize int
+	// Store persists the checkpoints; defaults to an in-memory store
+	Store CheckpointStore
+}
+
+// Crawler iterates over a whole table in primary key order in batches, for
+// long-running backfills. After each processed batch its position is saved to
+// a CheckpointStore, and a crawl that is run again resumes after the last
+// saved batch. Batches are processed at least once: a batch whose checkpoint
+// wasn't saved is processed again on resume.
+type Crawler[T any] struct {
+	qb        *QueryBuilder[T]
+	name      string
+	batchSize int
+	store     CheckpointStore
+	err       error
+}
+
+// NewCrawler creates a crawler over the rows selected by qb, which may carry
+// WHERE conditions. Its ordering is replaced by the primary key of T.
+func NewCrawler[T any](qb *QueryBuilder[T], opts CrawlerOptions) *Crawler[T] {
+	c := &Crawler[T]{
+		qb:        qb,
+		name:      opts.Name,
+		batchSize: opts.BatchSize,
+		store:     opts.Store,
+	}
+	if c.name == "" {
+		c.name = qb.tableName
+	}
+	if c.batchSize <= 0 {
+		c.batchSize = defaultCrawlBatchSize
+	}
+	if c.store == nil {
+		c.store = NewMemoryCheckpointStore()
+	}
+
+	meta, err := metadataOf(modelType[T]())
+	if err != nil {
+		c.err = err
+		return c
+	}
+	if len(meta.PrimaryKey) == 0 {
+		c.err = fmt.Errorf("%w: crawling %s requires a primary key", ErrInvalidModel, meta.Table)
+		return c
+	}
+	qb.orderBy = make([]dialect.OrderBy, len(meta.PrimaryKey))
+	for i, col := range meta.PrimaryKey {
+		qb.orderBy[i] = dialect.OrderBy{Column: col, Order: dialect.ASC}
+	}
+	return c
+}
+
+// Run crawls the table from the saved checkpoint, calling fn for each batch
+// and saving the checkpoint after it returns. It stops at the end of the
+// table, at the first error from fn or the store, or when ctx is done. The
+// checkpoint is kept at the end of the table, so later runs only see rows
+// added beyond it; call Reset to crawl from the start again.
+func (c *Crawler[T]) Run(ctx context.Context, fn func(ctx context.Context, batch []T) error) error {
+	if ctx == nil {
+		return ErrNilContext
+	}
+	if c.err != nil {
+		return c.err
+	}
+
+	cursor, err := c.store.Load(ctx, c.name)
+	if err != nil {
+		return fmt.Errorf("sqlblade: loading checkpoint %s: %w", c.name, err)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := c.qb.After(cursor).CursorPaginate(ctx, c.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(page.Items) == 0 {
+			return nil
+		}
+
+		if err := fn(ctx, page.Items); err != nil {
+			return err
+		}
+		cursor = page.Next
+		if err := c.store.Save(ctx, c.name, cursor); err != nil {
+			return fmt.Errorf("sqlblade: saving checkpoint %s: %w", c.name, err)
+		}
+
+		if !page.HasNext {
+			return nil
+		}
+	}
+}
+
+// Checkpoint returns the saved position of the crawl, "" at the start
+func (c *Crawler[T]) Checkpoint(ctx context.Context) (Cursor, error) {
+	return c.store.Load(ctx, c.name)
+}
+
+// Reset clears the saved position, so the next Run starts from the beginning
+func (c *Crawler[T]) Reset(ctx context.Context) error {
+	return c.store.Save(ctx, c.name, "")
+}
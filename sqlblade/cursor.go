@@ -0,0 +1,306 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// Cursor is an opaque position in a keyset-paginated result, encoding the
+// ordering column values of a row
+type Cursor string
+
+// CursorPage is a page of keyset-paginated query results. Next and Prev are
+// the cursors of the last and first item, to be passed to After and Before.
+type CursorPage[T any] struct {
+	Items   []T
+	Next    Cursor
+	Prev    Cursor
+	HasNext bool
+	HasPrev bool
+}
+
+// cursorValue is an encoded ordering column value of a cursor
+type cursorValue struct {
+	Type  string          `json:"t"`
+	Value json.RawMessage `json:"v"`
+}
+
+// After sets the cursor that CursorPaginate continues after
+func (qb *QueryBuilder[T]) After(cursor Cursor) *QueryBuilder[T] {
+	qb.cursor, qb.cursorBefore = cursor, false
+	return qb
+}
+
+// Before sets the cursor that CursorPaginate returns the rows before
+func (qb *QueryBuilder[T]) Before(cursor Cursor) *QueryBuilder[T] {
+	qb.cursor, qb.cursorBefore = cursor, true
+	return qb
+}
+
+// CursorPaginate returns up to limit rows after (or before) the cursor set
+// with After or Before, using keyset pagination on the OrderBy columns instead
+// of OFFSET. The ordering columns must be fields of the model, must not be
+// NULL, and together must identify a row uniquely, e.g. OrderBy("created_at",
+// DESC).OrderBy("id", DESC). The builder's own LIMIT and OFFSET are ignored.
+func (qb *QueryBuilder[T]) CursorPaginate(ctx context.Context, limit int) (*CursorPage[T], error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("%w: limit must be positive", ErrInvalidPage)
+	}
+	if len(qb.orderBy) == 0 {
+		return nil, fmt.Errorf("%w: CursorPaginate requires OrderBy", ErrInvalidCursor)
+	}
+	if len(qb.setOps) > 0 {
+		return nil, fmt.Errorf("%w: set operations are not supported", ErrInvalidCursor)
+	}
+	for _, ob := range qb.orderBy {
+		if ob.Raw {
+			return nil, fmt.Errorf("%w: raw ORDER BY expressions are not supported", ErrInvalidCursor)
+		}
+	}
+
+	prevWhere, prevOrderBy := qb.whereClauses, qb.orderBy
+	prevLimit, prevOffset := qb.limit, qb.offset
+	defer func() {
+		qb.whereClauses, qb.orderBy = prevWhere, prevOrderBy
+		qb.limit, qb.offset = prevLimit, prevOffset
+	}()
+
+	orderBy := prevOrderBy
+	if qb.cursorBefore {
+		// Rows before the cursor are read in reverse order and flipped back
+		orderBy = make([]dialect.OrderBy, len(prevOrderBy))
+		for i, ob := range prevOrderBy {
+			ob.Order = 1 - ob.Order
+			orderBy[i] = ob
+		}
+	}
+
+	if qb.cursor != "" {
+		values, err := decodeCursor(qb.cursor, len(orderBy))
+		if err != nil {
+			return nil, err
+		}
+		// The existing conditions are grouped so that a top-level OR can't
+		// bypass the keyset condition
+		qb.whereClauses = []WhereClause{
+			{Value: &ConditionGroup{clauses: prevWhere}, And: true},
+			{Value: keysetCondition(qb.dialect, orderBy, values), And: true},
+		}
+	}
+
+	fetch := limit + 1
+	qb.orderBy, qb.limit, qb.offset = orderBy, &fetch, nil
+
+	items, err := qb.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	more := len(items) > limit
+	if more {
+		items = items[:limit]
+	}
+
+	page := &CursorPage[T]{Items: items}
+	if qb.cursorBefore {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+		page.HasPrev, page.HasNext = more, qb.cursor != ""
+	} else {
+		page.HasNext, page.HasPrev = more, qb.cursor != ""
+	}
+
+	if len(items) > 0 {
+		if page.Prev, err = encodeCursor(items[0], prevOrderBy); err != nil {
+			return nil, err
+		}
+		if page.Next, err = encodeCursor(items[len(items)-1], prevOrderBy); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// keysetCondition builds the condition selecting the rows that come after the
+// cursor values in the given order. A uniform direction on PostgreSQL and
+// SQLite uses a row value comparison, which can use a composite index;
+// otherwise the comparison is expanded to (a > ?) OR (a = ? AND b > ?) ...
+func keysetCondition(d dialect.Dialect, orderBy []dialect.OrderBy, values []interface{}) rawExpr {
+	quoted := make([]string, len(orderBy))
+	uniform := true
+	for i, ob := range orderBy {
+		quoted[i] = d.QuoteIdentifier(ob.Column)
+		uniform = uniform && ob.Order == orderBy[0].Order
+	}
+
+	if len(orderBy) > 1 && uniform && d.Name() != dialectMySQL {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+		sqlStr := "(" + strings.Join(quoted, ", ") + ") " + keysetOperator(orderBy[0].Order) + " (" + placeholders + ")"
+		return rawExpr{sql: sqlStr, args: values}
+	}
+
+	var args []interface{}
+	parts := make([]string, len(orderBy))
+	for i, ob := range orderBy {
+		terms := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			terms = append(terms, quoted[j]+" = ?")
+			args = append(args, values[j])
+		}
+		terms = append(terms, quoted[i]+" "+keysetOperator(ob.Order)+" ?")
+		args = append(args, values[i])
+		parts[i] = "(" + strings.Join(terms, " AND ") + ")"
+	}
+	return rawExpr{sql: strings.Join(parts, " OR "), args: args}
+}
+
+// keysetOperator returns the comparison selecting the rows after a value
+func keysetOperator(order dialect.OrderDirection) string {
+	if order == dialect.DESC {
+		return "<"
+	}
+	return ">"
+}
+
+// encodeCursor encodes the ordering column values of a row
+func encodeCursor[T any](row T, orderBy []dialect.OrderBy) (Cursor, error) {
+	val := reflect.ValueOf(row)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return "", fmt.Errorf("%w: nil row", ErrInvalidCursor)
+		}
+		val = val.Elem()
+	}
+
+	info, err := getStructInfo(val.Type())
+	if err != nil {
+		return "", err
+	}
+
+	encoded := make([]cursorValue, len(orderBy))
+	for i, ob := range orderBy {
+		column := strings.ToLower(ob.Column)
+		if dot := strings.LastIndexByte(column, '.'); dot >= 0 {
+			column = column[dot+1:]
+		}
+
+		var field *fieldInfo
+		for j := range info.fields {
+			if info.fields[j].dbColumn == column {
+				field = &info.fields[j]
+				break
+			}
+		}
+		if field == nil {
+			return "", fmt.Errorf("%w: ordering column %s is not a field of the model", ErrInvalidCursor, ob.Column)
+		}
+
+		v, err := driver.DefaultParameterConverter.ConvertValue(field.interfaceOf(val))
+		if err != nil {
+			return "", fmt.Errorf("%w: column %s: %w", ErrInvalidCursor, ob.Column, err)
+		}
+		if encoded[i], err = encodeCursorValue(v); err != nil {
+			return "", fmt.Errorf("%w: column %s: %w", ErrInvalidCursor, ob.Column, err)
+		}
+	}
+
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		return "", err
+	}
+	return Cursor(base64.RawURLEncoding.EncodeToString(data)), nil
+}
+
+// encodeCursorValue encodes a driver value with its type
+func encodeCursorValue(v driver.Value) (cursorValue, error) {
+	var typ string
+	switch x := v.(type) {
+	case int64:
+		typ = "i"
+	case float64:
+		typ = "f"
+	case bool:
+		typ = "b"
+	case string:
+		typ = "s"
+	case []byte:
+		typ = "x"
+	case time.Time:
+		typ, v = "t", x.Format(time.RFC3339Nano)
+	case nil:
+		return cursorValue{}, fmt.Errorf("NULL ordering values are not supported")
+	default:
+		return cursorValue{}, fmt.Errorf("unsupported ordering value %T", v)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return cursorValue{}, err
+	}
+	return cursorValue{Type: typ, Value: data}, nil
+}
+
+// decodeCursor decodes the ordering column values of a cursor
+func decodeCursor(cursor Cursor, n int) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(string(cursor))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	var encoded []cursorValue
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	if len(encoded) != n {
+		return nil, fmt.Errorf("%w: cursor has %d values, query orders by %d columns", ErrInvalidCursor, len(encoded), n)
+	}
+
+	values := make([]interface{}, n)
+	for i, cv := range encoded {
+		var err error
+		switch cv.Type {
+		case "i":
+			values[i], err = decodeCursorJSON[int64](cv.Value)
+		case "f":
+			values[i], err = decodeCursorJSON[float64](cv.Value)
+		case "b":
+			values[i], err = decodeCursorJSON[bool](cv.Value)
+		case "s":
+			values[i], err = decodeCursorJSON[string](cv.Value)
+		case "x":
+			values[i], err = decodeCursorJSON[[]byte](cv.Value)
+		case "t":
+			var s string
+			if err = json.Unmarshal(cv.Value, &s); err == nil {
+				values[i], err = time.Parse(time.RFC3339Nano, s)
+			}
+		default:
+			err = fmt.Errorf("unknown value type %q", cv.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+		}
+	}
+	return values, nil
+}
+
+// decodeCursorJSON decodes an encoded cursor value of type V
+func decodeCursorJSON[V any](data json.RawMessage) (interface{}, error) {
+	var v V
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -3,18 +3,19 @@ package sqlblade
 import (
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
 // QueryDebugger provides SQL query debugging and logging capabilities
 type QueryDebugger struct {
-	enabled            bool
+	enabled            atomic.Bool
 	logger             Logger
 	showArgs           bool
 	colorize           bool
 	indentSQL          bool
 	showTiming         bool
-	slowQueryThreshold time.Duration
+	slowQueryThreshold atomic.Int64 // time.Duration
 }
 
 // Logger interface for custom logging
@@ -32,6 +33,7 @@ type DebugQuery struct {
 	RowsAffected int64
 	Error        error
 	Timestamp    time.Time
+	Tags         []string // set with the builders' Tag
 }
 
 // DefaultLogger is a simple logger that prints to stdout
@@ -43,26 +45,26 @@ func (l *DefaultLogger) Log(query *DebugQuery) {
 
 // NewQueryDebugger creates a new query debugger
 func NewQueryDebugger() *QueryDebugger {
-	return &QueryDebugger{
-		enabled:            false,
-		logger:             &DefaultLogger{},
-		showArgs:           true,
-		colorize:           true,
-		indentSQL:          true,
-		showTiming:         true,
-		slowQueryThreshold: 100 * time.Millisecond,
+	qd := &QueryDebugger{
+		logger:     &DefaultLogger{},
+		showArgs:   true,
+		colorize:   true,
+		indentSQL:  true,
+		showTiming: true,
 	}
+	qd.slowQueryThreshold.Store(int64(100 * time.Millisecond))
+	return qd
 }
 
 // Enable enables query debugging
 func (qd *QueryDebugger) Enable() *QueryDebugger {
-	qd.enabled = true
+	qd.enabled.Store(true)
 	return qd
 }
 
 // Disable disables query debugging
 func (qd *QueryDebugger) Disable() *QueryDebugger {
-	qd.enabled = false
+	qd.enabled.Store(false)
 	return qd
 }
 
@@ -98,13 +100,13 @@ func (qd *QueryDebugger) ShowTiming(enable bool) *QueryDebugger {
 
 // SetSlowQueryThreshold sets the threshold for slow query warnings
 func (qd *QueryDebugger) SetSlowQueryThreshold(threshold time.Duration) *QueryDebugger {
-	qd.slowQueryThreshold = threshold
+	qd.slowQueryThreshold.Store(int64(threshold))
 	return qd
 }
 
 // Log logs a query if debugging is enabled
 func (qd *QueryDebugger) Log(query *DebugQuery) {
-	if !qd.enabled {
+	if !qd.enabled.Load() {
 		return
 	}
 	qd.logger.Log(query)
@@ -152,11 +154,14 @@ func formatQuery(query *DebugQuery) string {
 	if query.Table != "" {
 		sb.WriteString(fmt.Sprintf("Table:     %s\n", query.Table))
 	}
+	if len(query.Tags) > 0 {
+		sb.WriteString(fmt.Sprintf("Tags:      %s\n", strings.Join(query.Tags, ", ")))
+	}
 
 	// Timing
 	if globalDebugger.showTiming && query.Duration > 0 {
 		sb.WriteString(fmt.Sprintf("Duration:  %s", query.Duration))
-		if query.Duration > globalDebugger.slowQueryThreshold {
+		if query.Duration > time.Duration(globalDebugger.slowQueryThreshold.Load()) {
 			sb.WriteString(" ⚠️  SLOW QUERY")
 		}
 		sb.WriteString("\n")
@@ -0,0 +1,105 @@
+package sqlblade
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// Decimal is an arbitrary-precision decimal number, such as the Decimal of
+// github.com/shopspring/decimal. Decimal arguments are bound as their exact
+// text unless they implement driver.Valuer, and Decimal fields are scanned
+// through sql.Scanner or, failing that, encoding.TextUnmarshaler. Fields of a
+// Decimal type, or tagged with the decimal option, are decimal columns:
+// AutoMigrate creates them as NUMERIC, and WithStrictDecimals rejects floats
+// written to them.
+type Decimal interface {
+	// String returns the exact decimal text, e.g. "12.30"
+	String() string
+	// Float64 returns the nearest float64 and whether it is exact
+	Float64() (f float64, exact bool)
+}
+
+var (
+	decimalType         = reflect.TypeOf((*Decimal)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// WithStrictDecimals makes INSERT and UPDATE statements fail with
+// ErrFloatDecimal when a float is written to a decimal column, so that money
+// never goes through binary floating point
+func WithStrictDecimals() ClientOption {
+	return func(c *Client) {
+		c.strictDecimals = true
+	}
+}
+
+// isDecimalType reports whether typ, or the type it points to, is a Decimal
+func isDecimalType(typ reflect.Type) bool {
+	typ = baseType(typ)
+	return typ.Implements(decimalType) || reflect.PointerTo(typ).Implements(decimalType)
+}
+
+// isDecimalField reports whether a field maps a decimal column
+func isDecimalField(field fieldInfo) bool {
+	return field.hasOption("decimal") || isDecimalType(field.fieldType)
+}
+
+// checkDecimal returns ErrFloatDecimal when the client is strict about
+// decimals and value is a float written to a decimal column of the model
+func (c *Client) checkDecimal(info *structInfo, column string, value interface{}) error {
+	if c == nil || !c.strictDecimals || value == nil {
+		return nil
+	}
+	switch baseType(reflect.TypeOf(value)).Kind() {
+	case reflect.Float32, reflect.Float64:
+	default:
+		return nil
+	}
+	for _, field := range info.fields {
+		if field.dbColumn == column && isDecimalField(field) {
+			return fmt.Errorf("%w: %s.%s", ErrFloatDecimal, info.tableName, column)
+		}
+	}
+	return nil
+}
+
+// decimalText returns the text of a value scanned for a Decimal field
+func decimalText(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case []byte:
+		return string(v), true
+	case string:
+		return v, true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// scanDecimal sets a Decimal field that isn't a sql.Scanner from a scanned
+// value through its encoding.TextUnmarshaler. It reports whether the field is such a Decimal.
+func scanDecimal(field reflect.Value, value interface{}) (bool, error) {
+	if !isDecimalType(field.Type()) || !reflect.PointerTo(field.Type()).Implements(textUnmarshalerType) {
+		return false, nil
+	}
+	text, ok := decimalText(value)
+	if !ok {
+		return true, fmt.Errorf("sqlblade: cannot convert %T to %s", value, field.Type())
+	}
+	return true, field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+}
+
+// decimalColumnType returns the column type of decimal columns for the dialect
+func decimalColumnType(d dialect.Dialect) string {
+	if d.Name() == dialectMySQL {
+		// MySQL's NUMERIC defaults to no decimal places
+		return "DECIMAL(65,30)"
+	}
+	return "NUMERIC"
+}
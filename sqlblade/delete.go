@@ -3,6 +3,7 @@ package sqlblade
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"reflect"
 	"strings"
 
@@ -13,19 +14,21 @@ import (
 type DeleteBuilder[T any] struct {
 	db           *sql.DB
 	tx           *sql.Tx
+	client       *Client
 	dialect      dialect.Dialect
 	tableName    string
 	whereClauses []WhereClause
+	orderBy      []dialect.OrderBy
+	limit        *int
 	returning    []string
+	strict       bool
+	allowFull    bool
+	tags         []string
 }
 
 // Delete creates a new DELETE builder
-func Delete[T any](db *sql.DB) *DeleteBuilder[T] {
-	if db == nil {
-		panic(ErrNilDB)
-	}
-
-	d := detectDialect(db.Driver())
+func Delete[T any, C Conn](db C) *DeleteBuilder[T] {
+	client := resolveConn(db)
 	var zero T
 	typ := reflect.TypeOf(zero)
 	if typ.Kind() == reflect.Ptr {
@@ -40,8 +43,9 @@ func Delete[T any](db *sql.DB) *DeleteBuilder[T] {
 	}
 
 	return &DeleteBuilder[T]{
-		db:           db,
-		dialect:      d,
+		db:           client.db,
+		client:       client,
+		dialect:      withColumnAliases(client.dialect, typ, info.tableName),
 		tableName:    info.tableName,
 		whereClauses: make([]WhereClause, 0),
 		returning:    make([]string, 0),
@@ -49,12 +53,8 @@ func Delete[T any](db *sql.DB) *DeleteBuilder[T] {
 }
 
 // DeleteTx creates a new DELETE builder with transaction
-func DeleteTx[T any](tx *sql.Tx) *DeleteBuilder[T] {
-	if tx == nil {
-		panic(ErrNilDB)
-	}
-
-	d := detectDialect(nil)
+func DeleteTx[T any, X TxConn](tx X) *DeleteBuilder[T] {
+	sqlTx, client := resolveTx(tx)
 	var zero T
 	typ := reflect.TypeOf(zero)
 	if typ.Kind() == reflect.Ptr {
@@ -69,8 +69,9 @@ func DeleteTx[T any](tx *sql.Tx) *DeleteBuilder[T] {
 	}
 
 	return &DeleteBuilder[T]{
-		tx:           tx,
-		dialect:      d,
+		tx:           sqlTx,
+		client:       client,
+		dialect:      withColumnAliases(client.dialect, typ, info.tableName),
 		tableName:    info.tableName,
 		whereClauses: make([]WhereClause, 0),
 		returning:    make([]string, 0),
@@ -88,7 +89,93 @@ func (db *DeleteBuilder[T]) Where(column string, operator string, value interfac
 	return db
 }
 
-// Returning specifies columns to return (PostgreSQL)
+// OrWhere adds a WHERE condition (OR)
+func (db *DeleteBuilder[T]) OrWhere(column string, operator string, value interface{}) *DeleteBuilder[T] {
+	db.whereClauses = append(db.whereClauses, WhereClause{
+		Column:   column,
+		Operator: operator,
+		Value:    value,
+		And:      false,
+	})
+	return db
+}
+
+// WhereIn adds a WHERE column IN (...) condition. An empty list matches no rows.
+func (db *DeleteBuilder[T]) WhereIn(column string, values ...interface{}) *DeleteBuilder[T] {
+	return db.Where(column, "IN", values)
+}
+
+// OrWhereIn adds a WHERE column IN (...) condition (OR)
+func (db *DeleteBuilder[T]) OrWhereIn(column string, values ...interface{}) *DeleteBuilder[T] {
+	return db.OrWhere(column, "IN", values)
+}
+
+// WhereNotIn adds a WHERE column NOT IN (...) condition. An empty list matches all rows.
+func (db *DeleteBuilder[T]) WhereNotIn(column string, values ...interface{}) *DeleteBuilder[T] {
+	return db.Where(column, "NOT IN", values)
+}
+
+// WhereRaw adds a raw WHERE predicate (AND). Use "?" as placeholder for args;
+// it is converted to the dialect's placeholder style.
+func (db *DeleteBuilder[T]) WhereRaw(sql string, args ...interface{}) *DeleteBuilder[T] {
+	db.whereClauses = append(db.whereClauses, WhereClause{
+		Value: rawExpr{sql: sql, args: args},
+		And:   true,
+	})
+	return db
+}
+
+// OrWhereRaw adds a raw WHERE predicate (OR)
+func (db *DeleteBuilder[T]) OrWhereRaw(sql string, args ...interface{}) *DeleteBuilder[T] {
+	db.whereClauses = append(db.whereClauses, WhereClause{
+		Value: rawExpr{sql: sql, args: args},
+		And:   false,
+	})
+	return db
+}
+
+// WhereSubquery adds a WHERE condition using a subquery, e.g.
+// WhereSubquery("user_id", "IN", sqlblade.NewSubquery(...))
+func (db *DeleteBuilder[T]) WhereSubquery(column string, operator string, subquery *Subquery) *DeleteBuilder[T] {
+	return db.Where(column, operator, subquery)
+}
+
+// OrderBy adds an ORDER BY clause, to delete the first rows in that order
+// with Limit. Only dialects supporting it (MySQL) are allowed; others fail
+// with ErrDialectUnsupported.
+func (db *DeleteBuilder[T]) OrderBy(column string, order dialect.OrderDirection) *DeleteBuilder[T] {
+	db.orderBy = append(db.orderBy, dialect.OrderBy{
+		Column: column,
+		Order:  order,
+	})
+	return db
+}
+
+// OrderByExpr adds an expression to the ORDER BY clause, see OrderBy, e.g.
+// OrderByExpr(Expr("abs(score - ?)", target), dialect.ASC)
+func (db *DeleteBuilder[T]) OrderByExpr(expr Expression, order dialect.OrderDirection) *DeleteBuilder[T] {
+	direction := " ASC"
+	if order == dialect.DESC {
+		direction = " DESC"
+	}
+	db.orderBy = append(db.orderBy, dialect.OrderBy{
+		Column: expr.sql + direction,
+		Order:  order,
+		Raw:    true,
+		Args:   expr.args,
+	})
+	return db
+}
+
+// Limit sets the LIMIT clause, e.g. to delete old rows in small batches.
+// Only dialects supporting it (MySQL) are allowed; others fail with
+// ErrDialectUnsupported.
+func (db *DeleteBuilder[T]) Limit(limit int) *DeleteBuilder[T] {
+	db.limit = &limit
+	return db
+}
+
+// Returning specifies columns to return (PostgreSQL, SQLite)
 func (db *DeleteBuilder[T]) Returning(columns ...string) *DeleteBuilder[T] {
 	db.returning = columns
 	return db
@@ -99,7 +186,62 @@ func (db *DeleteBuilder[T]) Execute(ctx context.Context) (sql.Result, error) {
 	if ctx == nil {
 		return nil, ErrNilContext
 	}
+	if err := db.checkOrderLimit(); err != nil {
+		return nil, err
+	}
+	if err := db.checkFullTable(); err != nil {
+		return nil, err
+	}
+	if err := db.validate(); err != nil {
+		return nil, err
+	}
+
+	sqlStr, args := db.buildSQL(db.returning)
 
+	var result sql.Result
+	stmt := &Statement{Operation: "DELETE", Table: db.tableName, SQL: sqlStr, Args: args, Primary: true, Tags: db.tags}
+	err := db.client.executeHooked(ctx, stmt, func(ctx context.Context, _ *HookEvent) (sql.Result, error) {
+		var execErr error
+		result, execErr = db.client.execContext(ctx, db.tx, true, stmt.SQL, stmt.Args)
+		if execErr != nil {
+			return nil, wrapQueryError(execErr, stmt.SQL, stmt.Args)
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ExecuteReturning executes the DELETE statement and scans the deleted rows
+// returned by its RETURNING clause into T. Without Returning, all columns are
+// returned. Only dialects supporting RETURNING (PostgreSQL, SQLite) are allowed.
+func (db *DeleteBuilder[T]) ExecuteReturning(ctx context.Context) ([]T, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+	if !supportsReturning(db.dialect) {
+		return nil, ErrReturningUnsupported
+	}
+	if err := db.checkOrderLimit(); err != nil {
+		return nil, err
+	}
+	if err := db.checkFullTable(); err != nil {
+		return nil, err
+	}
+	if err := db.validate(); err != nil {
+		return nil, err
+	}
+
+	sqlStr, args := db.buildSQL(returningColumns(db.returning))
+	stmt := &Statement{Operation: "DELETE", Table: db.tableName, SQL: sqlStr, Args: args, Primary: true, Tags: db.tags}
+	return executeReturning[T](ctx, db.client, db.tx, stmt)
+}
+
+// buildSQL builds the DELETE statement with the given RETURNING columns
+func (db *DeleteBuilder[T]) buildSQL(returning []string) (string, []interface{}) {
 	var buf strings.Builder
 	paramIndex := 0
 	var args []interface{}
@@ -114,29 +256,28 @@ func (db *DeleteBuilder[T]) Execute(ctx context.Context) (sql.Result, error) {
 		args = append(args, whereArgs...)
 	}
 
-	if len(db.returning) > 0 && db.dialect.Name() == dialectPostgres {
-		buf.WriteString(" RETURNING ")
-		returningCols := make([]string, len(db.returning))
-		for i, col := range db.returning {
-			returningCols[i] = db.dialect.QuoteIdentifier(col)
-		}
-		buf.WriteString(strings.Join(returningCols, ", "))
+	if len(db.orderBy) > 0 {
+		buf.WriteString(" ")
+		buf.WriteString(db.dialect.BuildOrderBy(bindOrderBy(db.dialect, db.orderBy, &paramIndex, &args)))
 	}
 
-	sqlStr := buf.String()
+	if db.limit != nil {
+		limitSQL, limitArgs := buildLimitOffset(db.dialect, db.limit, nil, &paramIndex)
+		buf.WriteString(" ")
+		buf.WriteString(limitSQL)
+		args = append(args, limitArgs...)
+	}
 
-	var result sql.Result
-	var err error
+	writeReturning(&buf, db.dialect, returning)
 
-	if db.tx != nil {
-		result, err = db.tx.ExecContext(ctx, sqlStr, args...)
-	} else {
-		result, err = db.db.ExecContext(ctx, sqlStr, args...)
-	}
+	return buf.String(), args
+}
 
-	if err != nil {
-		return nil, wrapQueryError(err, sqlStr, args)
+// checkOrderLimit returns ErrDialectUnsupported when ORDER BY or LIMIT is set
+// on a dialect whose DELETE doesn't accept them
+func (db *DeleteBuilder[T]) checkOrderLimit() error {
+	if (len(db.orderBy) > 0 || db.limit != nil) && !dialect.CapabilitiesOf(db.dialect).DeleteOrderLimit {
+		return fmt.Errorf("%w: DELETE with ORDER BY or LIMIT", ErrDialectUnsupported)
 	}
-
-	return result, nil
+	return nil
 }
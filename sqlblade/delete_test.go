@@ -0,0 +1,65 @@
+package sqlblade_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+func TestDeleteOrderByBindsArgs(t *testing.T) {
+	db, _ := openRecorder(t)
+	client := sqlblade.Open(db, sqlblade.WithDialect(dialect.NewMySQL()))
+	ctx, capture := sqlblade.WithCapture(context.Background())
+
+	_, err := sqlblade.Delete[txUser](client).
+		Where("name", "=", "x").
+		OrderByExpr(sqlblade.Expr("abs(id - ?)", 10), dialect.ASC).
+		Limit(5).
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	statements := capture.Statements()
+	if len(statements) != 1 {
+		t.Fatalf("expected 1 statement, got %d", len(statements))
+	}
+	wantSQL := "DELETE FROM `users` WHERE `name` = ? ORDER BY abs(id - ?) ASC LIMIT ?"
+	if statements[0].SQL != wantSQL {
+		t.Errorf("SQL = %q, want %q", statements[0].SQL, wantSQL)
+	}
+	if wantArgs := []interface{}{"x", 10, 5}; !reflect.DeepEqual(statements[0].Args, wantArgs) {
+		t.Errorf("args = %v, want %v", statements[0].Args, wantArgs)
+	}
+}
+
+func TestDeleteConditionAPI(t *testing.T) {
+	db, _ := openRecorder(t)
+	client := sqlblade.Open(db, sqlblade.WithDialect(dialect.NewPostgreSQL()))
+	ctx, capture := sqlblade.WithCapture(context.Background())
+
+	_, err := sqlblade.Delete[txUser](client).
+		WhereNotIn("name", "a", "b").
+		OrWhereIn("id", 1, 2).
+		WhereRaw("length(name) > ?", 3).
+		OrWhereRaw("id < ?", 0).
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	statements := capture.Statements()
+	if len(statements) != 1 {
+		t.Fatalf("expected 1 statement, got %d", len(statements))
+	}
+	wantSQL := `DELETE FROM "users" WHERE "name" NOT IN ($1, $2) OR "id" IN ($3, $4) AND (length(name) > $5) OR (id < $6)`
+	if statements[0].SQL != wantSQL {
+		t.Errorf("SQL = %q, want %q", statements[0].SQL, wantSQL)
+	}
+	if wantArgs := []interface{}{"a", "b", 1, 2, 3, 0}; !reflect.DeepEqual(statements[0].Args, wantArgs) {
+		t.Errorf("args = %v, want %v", statements[0].Args, wantArgs)
+	}
+}
@@ -0,0 +1,32 @@
+package dialect
+
+// Capabilities describes the features and limits of a dialect
+type Capabilities struct {
+	// Returning reports whether INSERT/UPDATE/DELETE support a RETURNING clause
+	Returning bool
+	// LastInsertID reports whether the driver supports LastInsertId()
+	LastInsertID bool
+	// MaxBindParams is the maximum number of bind parameters of a statement,
+	// 0 when unknown
+	MaxBindParams int
+	// MaxIdentifierLength is the maximum length of a table, column or alias
+	// name, in bytes (characters for MySQL), 0 when unknown
+	MaxIdentifierLength int
+	// DeleteOrderLimit reports whether DELETE accepts ORDER BY and LIMIT clauses
+	DeleteOrderLimit bool
+}
+
+// CapabilityProvider is implemented by dialects that describe their capabilities
+type CapabilityProvider interface {
+	Capabilities() Capabilities
+}
+
+// CapabilitiesOf returns the capabilities of a dialect. Dialects not
+// implementing CapabilityProvider get the capabilities derived from the
+// Dialect interface, without limits.
+func CapabilitiesOf(d Dialect) Capabilities {
+	if p, ok := d.(CapabilityProvider); ok {
+		return p.Capabilities()
+	}
+	return Capabilities{LastInsertID: d.SupportLastInsertID()}
+}
@@ -31,10 +31,20 @@ type Dialect interface {
 	LastInsertIDReturning(tableName string, idColumn string) string
 }
 
+// LimitOffsetBinder is implemented by dialects that can bind LIMIT and OFFSET
+// values as query parameters instead of formatting them into the SQL
+type LimitOffsetBinder interface {
+	// BuildLimitOffsetParams builds LIMIT and OFFSET clauses with placeholders,
+	// advancing paramIndex, and returns the values to bind
+	BuildLimitOffsetParams(limit, offset *int, paramIndex *int) (string, []interface{})
+}
+
 // OrderBy represents an ORDER BY clause
 type OrderBy struct {
 	Column string
 	Order  OrderDirection
+	Raw    bool          // Column is a raw SQL expression, emitted verbatim without direction
+	Args   []interface{} // arguments of the "?" placeholders of a raw expression, bound by the builder
 }
 
 // OrderDirection represents the order direction
@@ -49,9 +59,19 @@ const (
 type Join struct {
 	Type      JoinType
 	Table     string
+	Alias     string // optional alias of the joined table
 	Condition string
 }
 
+// joinTarget returns the quoted table of a join, followed by its alias
+func joinTarget(d Dialect, join Join) string {
+	table := d.QuoteIdentifier(join.Table)
+	if join.Alias != "" {
+		table += " AS " + d.QuoteIdentifier(join.Alias)
+	}
+	return table
+}
+
 // JoinType represents the type of JOIN
 type JoinType int
 
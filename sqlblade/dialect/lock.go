@@ -0,0 +1,59 @@
+package dialect
+
+// LockStrength is the row-level lock taken by a SELECT
+type LockStrength int
+
+const (
+	// NoLock takes no row-level lock
+	NoLock LockStrength = iota
+	// ForUpdate locks the selected rows for writing
+	ForUpdate
+	// ForShare locks the selected rows against writes by other transactions
+	ForShare
+)
+
+// LockWait is how a locking SELECT handles rows locked by other transactions
+type LockWait int
+
+const (
+	// Wait blocks until the rows are unlocked
+	Wait LockWait = iota
+	// NoWait fails instead of waiting
+	NoWait
+	// SkipLocked leaves locked rows out of the result
+	SkipLocked
+)
+
+// Lock is the locking clause of a SELECT
+type Lock struct {
+	Strength LockStrength
+	Wait     LockWait
+}
+
+// LockBuilder is implemented by dialects supporting row-level locking clauses
+type LockBuilder interface {
+	// BuildLock builds the locking clause, empty for NoLock
+	BuildLock(lock Lock) string
+}
+
+// buildLock builds a FOR UPDATE / FOR SHARE clause, as written by PostgreSQL
+// and MySQL 8
+func buildLock(lock Lock) string {
+	var clause string
+	switch lock.Strength {
+	case ForUpdate:
+		clause = "FOR UPDATE"
+	case ForShare:
+		clause = "FOR SHARE"
+	default:
+		return ""
+	}
+
+	switch lock.Wait {
+	case NoWait:
+		clause += " NOWAIT"
+	case SkipLocked:
+		clause += " SKIP LOCKED"
+	}
+	return clause
+}
@@ -52,6 +52,26 @@ func (m *MySQL) BuildLimitOffset(limit, offset *int) string {
 	return fmt.Sprintf("LIMIT 18446744073709551615 OFFSET %d", *offset) // MySQL requires LIMIT when using OFFSET
 }
 
+// BuildLimitOffsetParams builds LIMIT and OFFSET clauses with bound values
+func (m *MySQL) BuildLimitOffsetParams(limit, offset *int, paramIndex *int) (string, []interface{}) {
+	if limit == nil && offset == nil {
+		return "", nil
+	}
+	if limit == nil {
+		*paramIndex++
+		return "LIMIT 18446744073709551615 OFFSET " + m.Placeholder(*paramIndex), []interface{}{*offset}
+	}
+	*paramIndex++
+	sql := "LIMIT " + m.Placeholder(*paramIndex)
+	args := []interface{}{*limit}
+	if offset != nil {
+		*paramIndex++
+		sql += " OFFSET " + m.Placeholder(*paramIndex)
+		args = append(args, *offset)
+	}
+	return sql, args
+}
+
 // BuildOrderBy builds ORDER BY clause
 func (m *MySQL) BuildOrderBy(orderBy []OrderBy) string {
 	if len(orderBy) == 0 {
@@ -59,6 +79,10 @@ func (m *MySQL) BuildOrderBy(orderBy []OrderBy) string {
 	}
 	var parts []string
 	for _, ob := range orderBy {
+		if ob.Raw {
+			parts = append(parts, ob.Column)
+			continue
+		}
 		order := orderASC
 		if ob.Order == DESC {
 			order = orderDESC
@@ -68,9 +92,15 @@ func (m *MySQL) BuildOrderBy(orderBy []OrderBy) string {
 	return "ORDER BY " + strings.Join(parts, ", ")
 }
 
+// BuildLock builds the row-level locking clause for MySQL; FOR SHARE, NOWAIT
+// and SKIP LOCKED need MySQL 8.0
+func (m *MySQL) BuildLock(lock Lock) string {
+	return buildLock(lock)
+}
+
 // BuildJoin builds JOIN clause
 func (m *MySQL) BuildJoin(join Join) string {
-	return fmt.Sprintf("%s %s ON %s", join.Type.String(), m.QuoteIdentifier(join.Table), join.Condition)
+	return fmt.Sprintf("%s %s ON %s", join.Type.String(), joinTarget(m, join), join.Condition)
 }
 
 // SupportLastInsertID returns true for MySQL
@@ -82,3 +112,16 @@ func (m *MySQL) SupportLastInsertID() bool {
 func (m *MySQL) LastInsertIDReturning(tableName string, idColumn string) string {
 	return ""
 }
+
+// Capabilities returns the capabilities of MySQL; MaxBindParams is 65535
+// because the wire protocol encodes the parameter count in 16 bits, and
+// identifiers are limited to 64 characters
+func (m *MySQL) Capabilities() Capabilities {
+	return Capabilities{
+		Returning:           false,
+		LastInsertID:        true,
+		MaxBindParams:       65535,
+		MaxIdentifierLength: 64,
+		DeleteOrderLimit:    true,
+	}
+}
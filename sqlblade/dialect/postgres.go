@@ -65,6 +65,23 @@ func (p *PostgreSQL) BuildLimitOffset(limit, offset *int) string {
 	return strings.Join(parts, " ")
 }
 
+// BuildLimitOffsetParams builds LIMIT and OFFSET clauses with bound values
+func (p *PostgreSQL) BuildLimitOffsetParams(limit, offset *int, paramIndex *int) (string, []interface{}) {
+	var parts []string
+	var args []interface{}
+	if limit != nil {
+		*paramIndex++
+		parts = append(parts, "LIMIT "+p.Placeholder(*paramIndex))
+		args = append(args, *limit)
+	}
+	if offset != nil {
+		*paramIndex++
+		parts = append(parts, "OFFSET "+p.Placeholder(*paramIndex))
+		args = append(args, *offset)
+	}
+	return strings.Join(parts, " "), args
+}
+
 // BuildOrderBy builds ORDER BY clause
 func (p *PostgreSQL) BuildOrderBy(orderBy []OrderBy) string {
 	if len(orderBy) == 0 {
@@ -72,6 +89,10 @@ func (p *PostgreSQL) BuildOrderBy(orderBy []OrderBy) string {
 	}
 	var parts []string
 	for _, ob := range orderBy {
+		if ob.Raw {
+			parts = append(parts, ob.Column)
+			continue
+		}
 		order := orderASC
 		if ob.Order == DESC {
 			order = orderDESC
@@ -81,9 +102,14 @@ func (p *PostgreSQL) BuildOrderBy(orderBy []OrderBy) string {
 	return "ORDER BY " + strings.Join(parts, ", ")
 }
 
+// BuildLock builds the row-level locking clause for PostgreSQL
+func (p *PostgreSQL) BuildLock(lock Lock) string {
+	return buildLock(lock)
+}
+
 // BuildJoin builds JOIN clause
 func (p *PostgreSQL) BuildJoin(join Join) string {
-	return fmt.Sprintf("%s %s ON %s", join.Type.String(), p.QuoteIdentifier(join.Table), join.Condition)
+	return fmt.Sprintf("%s %s ON %s", join.Type.String(), joinTarget(p, join), join.Condition)
 }
 
 // SupportLastInsertID returns false for PostgreSQL (uses RETURNING instead)
@@ -95,3 +121,15 @@ func (p *PostgreSQL) SupportLastInsertID() bool {
 func (p *PostgreSQL) LastInsertIDReturning(tableName string, idColumn string) string {
 	return fmt.Sprintf("RETURNING %s", p.QuoteIdentifier(idColumn))
 }
+
+// Capabilities returns the capabilities of PostgreSQL; MaxBindParams is 65535
+// because the wire protocol encodes the parameter count in 16 bits, and
+// longer identifiers are truncated to NAMEDATALEN-1 (63) bytes
+func (p *PostgreSQL) Capabilities() Capabilities {
+	return Capabilities{
+		Returning:           true,
+		LastInsertID:        false,
+		MaxBindParams:       65535,
+		MaxIdentifierLength: 63,
+	}
+}
@@ -50,6 +50,23 @@ func (s *SQLite) BuildLimitOffset(limit, offset *int) string {
 	return strings.Join(parts, " ")
 }
 
+// BuildLimitOffsetParams builds LIMIT and OFFSET clauses with bound values
+func (s *SQLite) BuildLimitOffsetParams(limit, offset *int, paramIndex *int) (string, []interface{}) {
+	var parts []string
+	var args []interface{}
+	if limit != nil {
+		*paramIndex++
+		parts = append(parts, "LIMIT "+s.Placeholder(*paramIndex))
+		args = append(args, *limit)
+	}
+	if offset != nil {
+		*paramIndex++
+		parts = append(parts, "OFFSET "+s.Placeholder(*paramIndex))
+		args = append(args, *offset)
+	}
+	return strings.Join(parts, " "), args
+}
+
 // BuildOrderBy builds ORDER BY clause
 func (s *SQLite) BuildOrderBy(orderBy []OrderBy) string {
 	if len(orderBy) == 0 {
@@ -57,6 +74,10 @@ func (s *SQLite) BuildOrderBy(orderBy []OrderBy) string {
 	}
 	var parts []string
 	for _, ob := range orderBy {
+		if ob.Raw {
+			parts = append(parts, ob.Column)
+			continue
+		}
 		order := orderASC
 		if ob.Order == DESC {
 			order = orderDESC
@@ -68,7 +89,7 @@ func (s *SQLite) BuildOrderBy(orderBy []OrderBy) string {
 
 // BuildJoin builds JOIN clause
 func (s *SQLite) BuildJoin(join Join) string {
-	return fmt.Sprintf("%s %s ON %s", join.Type.String(), s.QuoteIdentifier(join.Table), join.Condition)
+	return fmt.Sprintf("%s %s ON %s", join.Type.String(), joinTarget(s, join), join.Condition)
 }
 
 // SupportLastInsertID returns true for SQLite
@@ -80,3 +101,13 @@ func (s *SQLite) SupportLastInsertID() bool {
 func (s *SQLite) LastInsertIDReturning(tableName string, idColumn string) string {
 	return ""
 }
+
+// Capabilities returns the capabilities of SQLite; MaxBindParams is 32766,
+// the default SQLITE_MAX_VARIABLE_NUMBER since SQLite 3.32
+func (s *SQLite) Capabilities() Capabilities {
+	return Capabilities{
+		Returning:     true,
+		LastInsertID:  true,
+		MaxBindParams: 32766,
+	}
+}
@@ -0,0 +1,120 @@
+package sqlblade
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"time"
+)
+
+// ResultDiff is the difference between two result sets, as returned by DiffResults
+type ResultDiff[T any, K comparable] struct {
+	Added   []T // rows only in b
+	Removed []T // rows only in a
+	Changed []RowChange[T, K]
+}
+
+// RowChange is a row present in both result sets with different column values
+type RowChange[T any, K comparable] struct {
+	Key    K
+	Old    T
+	New    T
+	Fields []FieldDiff
+}
+
+// FieldDiff is a column whose value differs between two versions of a row
+type FieldDiff struct {
+	Column string // db column, empty when T is not a struct
+	Field  string // struct field name
+	Old    interface{}
+	New    interface{}
+}
+
+// Empty reports whether the result sets are equal
+func (d *ResultDiff[T, K]) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffResults compares two result sets, matching rows by the key returned by
+// keyFn, e.g. for data reconciliation jobs. Struct rows are compared column by
+// column on their db fields; other types are compared as a whole. Keys are
+// expected to be unique; for duplicate keys the last row wins. Removed and
+// Changed follow the order of a, Added follows the order of b.
+func DiffResults[T any, K comparable](a, b []T, keyFn func(T) K) *ResultDiff[T, K] {
+	diff := &ResultDiff[T, K]{}
+
+	inB := make(map[K]int, len(b))
+	for i, row := range b {
+		inB[keyFn(row)] = i
+	}
+
+	inA := make(map[K]bool, len(a))
+	for _, row := range a {
+		key := keyFn(row)
+		inA[key] = true
+
+		j, ok := inB[key]
+		if !ok {
+			diff.Removed = append(diff.Removed, row)
+			continue
+		}
+		if fields := diffFields(row, b[j]); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, RowChange[T, K]{Key: key, Old: row, New: b[j], Fields: fields})
+		}
+	}
+
+	for _, row := range b {
+		if !inA[keyFn(row)] {
+			diff.Added = append(diff.Added, row)
+		}
+	}
+
+	return diff
+}
+
+// diffFields returns the db fields that differ between two rows
+func diffFields[T any](oldRow, newRow T) []FieldDiff {
+	oldVal, newVal := reflect.ValueOf(&oldRow).Elem(), reflect.ValueOf(&newRow).Elem()
+	for oldVal.Kind() == reflect.Ptr && !oldVal.IsNil() && !newVal.IsNil() {
+		oldVal, newVal = oldVal.Elem(), newVal.Elem()
+	}
+
+	if oldVal.Kind() != reflect.Struct {
+		if valuesEqual(oldVal.Interface(), newVal.Interface()) {
+			return nil
+		}
+		return []FieldDiff{{Old: oldVal.Interface(), New: newVal.Interface()}}
+	}
+
+	info, err := getStructInfo(oldVal.Type())
+	if err != nil {
+		return nil
+	}
+
+	var fields []FieldDiff
+	for _, field := range info.fields {
+		o, n := field.interfaceOf(oldVal), field.interfaceOf(newVal)
+		if !valuesEqual(o, n) {
+			fields = append(fields, FieldDiff{Column: field.dbColumn, Field: field.name, Old: o, New: n})
+		}
+	}
+	return fields
+}
+
+// valuesEqual compares two column values by their database representation, so
+// that e.g. times in different locations or Null wrappers compare as expected
+func valuesEqual(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+
+	av, errA := driver.DefaultParameterConverter.ConvertValue(a)
+	bv, errB := driver.DefaultParameterConverter.ConvertValue(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	if at, ok := av.(time.Time); ok {
+		bt, ok := bv.(time.Time)
+		return ok && at.Equal(bt)
+	}
+	return reflect.DeepEqual(av, bv)
+}
@@ -6,7 +6,7 @@
 // Features:
 //
 //   - Type-safe queries with compile-time type checking
-//   - Zero reflection overhead at runtime
+//   - Zero reflection overhead at runtime with scanners generated by cmd/sqlbladegen
 //   - High performance with zero-allocation string building
 //   - Multi-database support (PostgreSQL, MySQL, SQLite)
 //   - Full SQL support (SELECT, INSERT, UPDATE, DELETE, JOIN, Transactions)
@@ -0,0 +1,357 @@
+package sqlblade
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// dumpVersion is the version of the dump format written by DumpTable
+const dumpVersion = 1
+
+// restoreChunkSize is the number of rows inserted per statement by RestoreTable
+const restoreChunkSize = 200
+
+// Portable column types of a dump
+const (
+	dumpInteger = "integer"
+	dumpFloat   = "float"
+	dumpBool    = "bool"
+	dumpTime    = "time"
+	dumpBytes   = "bytes"
+	dumpText    = "text"
+)
+
+// DumpHeader is the first line of a table dump, describing the table schema
+type DumpHeader struct {
+	Version    int          `json:"sqlblade_dump"`
+	Table      string       `json:"table"`
+	PrimaryKey []string     `json:"primary_key,omitempty"`
+	Columns    []DumpColumn `json:"columns"`
+}
+
+// DumpColumn describes a column of a table dump
+type DumpColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // integer, float, bool, time, bytes or text
+}
+
+// DumpTable writes the rows of a model's table to w as NDJSON: a DumpHeader
+// line with a portable schema, followed by one JSON object per row. Times are
+// written in RFC 3339 format and bytes base64-encoded, so the dump can be
+// restored with RestoreTable into any supported database.
+func (c *Client) DumpTable(ctx context.Context, w io.Writer, model interface{}) error {
+	if ctx == nil {
+		return ErrNilContext
+	}
+
+	typ := reflect.TypeOf(model)
+	if typ == nil {
+		return ErrInvalidModel
+	}
+	meta, err := metadataOf(typ)
+	if err != nil {
+		return err
+	}
+
+	header := DumpHeader{Version: dumpVersion, Table: meta.Table, PrimaryKey: meta.PrimaryKey}
+	quoted := make([]string, len(meta.Columns))
+	for i, col := range meta.Columns {
+		header.Columns = append(header.Columns, DumpColumn{Name: col.Name, Type: dumpType(col.Type)})
+		quoted[i] = c.dialect.QuoteIdentifier(col.Name)
+	}
+
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	if err := enc.Encode(header); err != nil {
+		return err
+	}
+
+	sqlStr := "SELECT " + strings.Join(quoted, ", ") + " FROM " + c.dialect.QuoteIdentifier(meta.Table)
+	if len(meta.PrimaryKey) > 0 {
+		keys := make([]string, len(meta.PrimaryKey))
+		for i, col := range meta.PrimaryKey {
+			keys[i] = c.dialect.QuoteIdentifier(col)
+		}
+		sqlStr += " ORDER BY " + strings.Join(keys, ", ")
+	}
+
+	stmt := &Statement{Operation: "SELECT", Table: meta.Table, SQL: sqlStr}
+	err = c.execute(ctx, stmt, func(ctx context.Context) error {
+		rows, err := c.queryContext(ctx, nil, false, stmt.SQL, stmt.Args)
+		if err != nil {
+			return wrapQueryError(err, stmt.SQL, stmt.Args)
+		}
+		defer closeRows(rows)
+
+		values := make([]interface{}, len(header.Columns))
+		ptrs := make([]interface{}, len(values))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+
+		for rows.Next() {
+			if err := rows.Scan(ptrs...); err != nil {
+				return fmt.Errorf("sqlblade: failed to scan row: %w", err)
+			}
+			row := make(map[string]interface{}, len(values))
+			for i, col := range header.Columns {
+				row[col.Name] = dumpValue(col.Type, values[i])
+			}
+			if err := enc.Encode(row); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return err
+	}
+
+	return buf.Flush()
+}
+
+// RestoreTable reads a dump written by DumpTable and inserts its rows in one
+// transaction. The table is created from the dump's schema if it does not
+// exist; on PostgreSQL the sequence of an integer primary key is advanced
+// past the restored ids.
+func (c *Client) RestoreTable(ctx context.Context, r io.Reader) error {
+	if ctx == nil {
+		return ErrNilContext
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+	dec.UseNumber()
+
+	var header DumpHeader
+	if err := dec.Decode(&header); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidDump, err)
+	}
+	if header.Version != dumpVersion || header.Table == "" || len(header.Columns) == 0 {
+		return ErrInvalidDump
+	}
+
+	meta := &ModelMeta{Table: header.Table, PrimaryKey: header.PrimaryKey}
+	for _, col := range header.Columns {
+		meta.Columns = append(meta.Columns, ColumnMeta{Name: col.Name, Type: restoreGoType(col.Type)})
+	}
+
+	return c.WithTransaction(ctx, func(tx *Tx) error {
+		if err := tx.exec(ctx, buildCreateTableSQL(c.dialect, meta)); err != nil {
+			return err
+		}
+
+		chunk := make([][]interface{}, 0, restoreChunkSize)
+		for {
+			var row map[string]interface{}
+			if err := dec.Decode(&row); err == io.EOF {
+				break
+			} else if err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidDump, err)
+			}
+
+			values := make([]interface{}, len(header.Columns))
+			for i, col := range header.Columns {
+				v, err := restoreValue(col.Type, row[col.Name])
+				if err != nil {
+					return fmt.Errorf("%w: column %s: %w", ErrInvalidDump, col.Name, err)
+				}
+				values[i] = v
+			}
+
+			chunk = append(chunk, values)
+			if len(chunk) == restoreChunkSize {
+				if err := c.restoreRows(ctx, tx, &header, chunk); err != nil {
+					return err
+				}
+				chunk = chunk[:0]
+			}
+		}
+		if err := c.restoreRows(ctx, tx, &header, chunk); err != nil {
+			return err
+		}
+
+		return c.resetSequence(ctx, tx, meta)
+	})
+}
+
+// restoreRows inserts a chunk of restored rows
+func (c *Client) restoreRows(ctx context.Context, tx *Tx, header *DumpHeader, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	quoted := make([]string, len(header.Columns))
+	for i, col := range header.Columns {
+		quoted[i] = c.dialect.QuoteIdentifier(col.Name)
+	}
+
+	var buf strings.Builder
+	buf.WriteString("INSERT INTO ")
+	buf.WriteString(c.dialect.QuoteIdentifier(header.Table))
+	buf.WriteString(" (")
+	buf.WriteString(strings.Join(quoted, ", "))
+	buf.WriteString(") VALUES ")
+
+	paramIndex := 0
+	args := make([]interface{}, 0, len(rows)*len(header.Columns))
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString("(")
+		for j, v := range row {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			paramIndex++
+			buf.WriteString(c.dialect.Placeholder(paramIndex))
+			args = append(args, v)
+		}
+		buf.WriteString(")")
+	}
+
+	stmt := &Statement{Operation: "INSERT", Table: header.Table, SQL: buf.String(), Args: args, Primary: true}
+	return c.execute(ctx, stmt, func(ctx context.Context) error {
+		if _, err := c.execContext(ctx, tx.tx, true, stmt.SQL, stmt.Args); err != nil {
+			return wrapQueryError(err, stmt.SQL, stmt.Args)
+		}
+		return nil
+	})
+}
+
+// resetSequence advances the PostgreSQL sequence of a single integer primary
+// key past the restored ids, so later inserts don't collide with them
+func (c *Client) resetSequence(ctx context.Context, tx *Tx, meta *ModelMeta) error {
+	if c.dialect.Name() != dialectPostgres || len(meta.PrimaryKey) != 1 {
+		return nil
+	}
+	col, ok := meta.Column(meta.PrimaryKey[0])
+	if !ok || !isIntKind(col.Type.Kind()) {
+		return nil
+	}
+
+	table := c.dialect.QuoteIdentifier(meta.Table)
+	pk := c.dialect.QuoteIdentifier(col.Name)
+	sqlStr := fmt.Sprintf(
+		"SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE((SELECT MAX(%s) FROM %s), 0) + 1, false)",
+		c.dialect.EscapeString(meta.Table), c.dialect.EscapeString(col.Name), pk, table,
+	)
+	return tx.exec(ctx, sqlStr)
+}
+
+// dumpType returns the portable type of a Go field type
+func dumpType(typ reflect.Type) string {
+	typ = baseType(typ)
+	switch {
+	case typ == timeType:
+		return dumpTime
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8:
+		return dumpBytes
+	case typ.Kind() == reflect.Bool:
+		return dumpBool
+	case isIntKind(typ.Kind()):
+		return dumpInteger
+	case typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64:
+		return dumpFloat
+	default:
+		return dumpText
+	}
+}
+
+// restoreGoType returns the Go type used to create a column of a portable type
+func restoreGoType(typ string) reflect.Type {
+	switch typ {
+	case dumpInteger:
+		return reflect.TypeOf(int64(0))
+	case dumpFloat:
+		return reflect.TypeOf(float64(0))
+	case dumpBool:
+		return reflect.TypeOf(false)
+	case dumpTime:
+		return timeType
+	case dumpBytes:
+		return reflect.TypeOf([]byte(nil))
+	default:
+		return reflect.TypeOf("")
+	}
+}
+
+// dumpValue converts a scanned value to its JSON representation
+func dumpValue(typ string, v interface{}) interface{} {
+	b, isBytes := v.([]byte)
+	switch {
+	case v == nil:
+		return nil
+	case typ == dumpBytes && isBytes:
+		return base64.StdEncoding.EncodeToString(b)
+	case isBytes:
+		// Text protocols return most column types as bytes
+		return string(b)
+	case typ == dumpTime:
+		if t, ok := v.(time.Time); ok {
+			return t.Format(time.RFC3339Nano)
+		}
+	}
+	return v
+}
+
+// restoreValue converts a JSON value of a dump back to a value to bind
+func restoreValue(typ string, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	switch typ {
+	case dumpInteger:
+		switch n := v.(type) {
+		case json.Number:
+			return n.Int64()
+		case string:
+			var i int64
+			_, err := fmt.Sscan(n, &i)
+			return i, err
+		}
+	case dumpFloat:
+		switch n := v.(type) {
+		case json.Number:
+			return n.Float64()
+		case string:
+			var f float64
+			_, err := fmt.Sscan(n, &f)
+			return f, err
+		}
+	case dumpBool:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case json.Number:
+			return b.String() != "0", nil
+		case string:
+			return b == "1" || strings.EqualFold(b, "true") || strings.EqualFold(b, "t"), nil
+		}
+	case dumpTime:
+		if s, ok := v.(string); ok {
+			return parseTime(s)
+		}
+	case dumpBytes:
+		if s, ok := v.(string); ok {
+			return base64.StdEncoding.DecodeString(s)
+		}
+	default:
+		switch s := v.(type) {
+		case string:
+			return s, nil
+		case json.Number:
+			return s.String(), nil
+		}
+		return fmt.Sprint(v), nil
+	}
+	return nil, fmt.Errorf("unexpected value %v for type %s", v, typ)
+}
@@ -34,6 +34,81 @@ var (
 
 	// ErrTransactionCommit is returned when transaction commit fails
 	ErrTransactionCommit = errors.New("sqlblade: transaction commit failed")
+
+	// ErrNilIterateFunc is returned when Iterate is called without a callback
+	ErrNilIterateFunc = errors.New("sqlblade: nil iterate function")
+
+	// ErrInvalidPatchValue is returned when a patch value cannot be decoded into its column's type
+	ErrInvalidPatchValue = errors.New("sqlblade: invalid patch value")
+
+	// ErrReturningUnsupported is returned by ExecuteReturning on dialects without RETURNING support
+	ErrReturningUnsupported = errors.New("sqlblade: RETURNING is not supported by the dialect")
+
+	// ErrReturningMismatch is returned when RETURNING yields a different number of rows than were inserted
+	ErrReturningMismatch = errors.New("sqlblade: returned rows do not match inserted rows")
+
+	// ErrInvalidPage is returned by Paginate for invalid page sizes
+	ErrInvalidPage = errors.New("sqlblade: invalid page")
+
+	// ErrInvalidCursor is returned by CursorPaginate for malformed cursors or
+	// orderings that can't be used for keyset pagination
+	ErrInvalidCursor = errors.New("sqlblade: invalid cursor")
+
+	// ErrPartialResult is returned by Execute together with the rows scanned so
+	// far when AllowPartialResults is set and the context is done mid-scan
+	ErrPartialResult = errors.New("sqlblade: partial result")
+
+	// ErrReadOnly is returned for writes on a client in read-only mode
+	ErrReadOnly = errors.New("sqlblade: client is read-only")
+
+	// ErrMaintenanceWindow is returned for statements rejected by a Scheduler window
+	ErrMaintenanceWindow = errors.New("sqlblade: rejected during maintenance window")
+
+	// ErrOverloaded is returned for low-priority statements shed by a ConcurrencyLimiter
+	ErrOverloaded = errors.New("sqlblade: overloaded, low-priority statement shed")
+
+	// ErrTooManyParams is returned for statements with more bind parameters
+	// than the dialect allows, before they reach the driver
+	ErrTooManyParams = errors.New("sqlblade: too many bind parameters")
+
+	// ErrArgCount is returned when a CompiledQuery is executed with a number of
+	// arguments other than its placeholders
+	ErrArgCount = errors.New("sqlblade: wrong number of arguments")
+
+	// ErrDialectUnsupported is returned by helpers that are specific to another database
+	ErrDialectUnsupported = errors.New("sqlblade: not supported by the dialect")
+
+	// ErrFloatDecimal is returned by clients configured with WithStrictDecimals
+	// for floats written to decimal columns
+	ErrFloatDecimal = errors.New("sqlblade: float written to a decimal column")
+
+	// ErrRowLocked is returned by LockRow when the row stays locked by another
+	// transaction beyond the wait timeout
+	ErrRowLocked = errors.New("sqlblade: row is locked")
+
+	// ErrInvalidDestination is returned by ScanInto for destinations it can't
+	// scan into
+	ErrInvalidDestination = errors.New("sqlblade: invalid scan destination")
+
+	// ErrIdentifierTooLong is returned for statements with a table, column or
+	// alias name longer than the limit of WithIdentifierLengthCheck
+	ErrIdentifierTooLong = errors.New("sqlblade: identifier too long")
+
+	// ErrFullTable is returned by Update and Delete executed without WHERE
+	// conditions, or with conditions that match all rows such as NOT IN with
+	// an empty list, unless AllowFullTable was called
+	ErrFullTable = errors.New("sqlblade: UPDATE or DELETE without WHERE conditions")
+
+	// ErrInvalidFragment is returned when decoding malformed QueryFragment
+	// JSON, or encoding fragments with values JSON can't represent
+	ErrInvalidFragment = errors.New("sqlblade: invalid query fragment")
+
+	// ErrUnboundParam is returned for statements executed with a Param whose
+	// value was not bound
+	ErrUnboundParam = errors.New("sqlblade: unbound named parameter")
+
+	// ErrInvalidDump is returned by RestoreTable for input that is not a table dump
+	ErrInvalidDump = errors.New("sqlblade: invalid table dump")
 )
 
 // QueryError wraps a database error with query context
@@ -0,0 +1,140 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// queryContext runs a query on the transaction or the client's database, going
+// through the prepared statement cache when it is enabled for the database.
+// Inside a transaction the cached statement is bound to it.
+func (c *Client) queryContext(ctx context.Context, tx *sql.Tx, useStmtCache bool, sqlStr string, args []interface{}) (*sql.Rows, error) {
+	args = c.bindArgs(ctx, args)
+	return c.retryQuery(ctx, tx, sqlStr, func() (*sql.Rows, error) {
+		return c.queryOnce(ctx, tx, useStmtCache, sqlStr, args)
+	})
+}
+
+// queryOnce sends a query once, see queryContext
+func (c *Client) queryOnce(ctx context.Context, tx *sql.Tx, useStmtCache bool, sqlStr string, args []interface{}) (*sql.Rows, error) {
+	if sc := c.cachedStmts(useStmtCache); sc != nil {
+		rows, err := sc.queryContext(ctx, tx, sqlStr, args)
+		switch {
+		case errors.Is(err, errStmtUnavailable) || isUnpreparableError(err):
+		case isPreparedStatementError(err):
+			c.disablePreparedStatements(sc, err)
+			if tx != nil {
+				// The failed statement may have aborted the transaction
+				return nil, err
+			}
+		default:
+			return rows, err
+		}
+	}
+
+	if tx != nil {
+		return tx.QueryContext(ctx, c.annotate(ctx, sqlStr), args...)
+	}
+	return c.db.QueryContext(ctx, c.annotate(ctx, sqlStr), args...)
+}
+
+// execContext executes a statement on the transaction or the client's database,
+// going through the prepared statement cache when it is enabled for the database.
+// Inside a transaction the cached statement is bound to it.
+func (c *Client) execContext(ctx context.Context, tx *sql.Tx, useStmtCache bool, sqlStr string, args []interface{}) (sql.Result, error) {
+	args = c.bindArgs(ctx, args)
+	if sc := c.cachedStmts(useStmtCache); sc != nil {
+		result, err := sc.execContext(ctx, tx, sqlStr, args)
+		switch {
+		case errors.Is(err, errStmtUnavailable) || isUnpreparableError(err):
+		case isPreparedStatementError(err):
+			c.disablePreparedStatements(sc, err)
+			if tx != nil {
+				// The failed statement may have aborted the transaction
+				return nil, err
+			}
+		default:
+			return result, err
+		}
+	}
+
+	if tx != nil {
+		return tx.ExecContext(ctx, c.annotate(ctx, sqlStr), args...)
+	}
+	return c.db.ExecContext(ctx, c.annotate(ctx, sqlStr), args...)
+}
+
+// bindArgs returns the arguments of a statement of ctx to pass to the driver,
+// with their times in UTC when the client converts times
+func (c *Client) bindArgs(ctx context.Context, args []interface{}) []interface{} {
+	args = bindArgs(args)
+	if c.timeLocation(ctx) != nil {
+		args = bindUTC(args)
+	}
+	return args
+}
+
+// ptrValuers caches per type whether only a pointer to it implements driver.Valuer
+var ptrValuers sync.Map // map[reflect.Type]bool
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// bindArgs returns the arguments to pass to the driver, binding values whose
+// driver.Valuer has a pointer receiver through a pointer. The slice is only
+// copied when an argument changes.
+func bindArgs(args []interface{}) []interface{} {
+	var bound []interface{}
+	for i, arg := range args {
+		if v, ok := bindValue(arg); ok {
+			if bound == nil {
+				bound = append([]interface{}(nil), args...)
+			}
+			bound[i] = v
+		}
+	}
+	if bound == nil {
+		return args
+	}
+	return bound
+}
+
+// bindValue returns a pointer to a copy of value when only the pointer type
+// implements driver.Valuer, so that database/sql uses its Value method instead
+// of converting the underlying kind or failing, and the text of other Decimal
+// values. It reports whether value was replaced.
+func bindValue(value interface{}) (interface{}, bool) {
+	switch value.(type) {
+	case nil, driver.Valuer, int, int64, int32, float64, string, bool, []byte, time.Time:
+		return value, false
+	}
+
+	typ := reflect.TypeOf(value)
+	ptrValuer, ok := ptrValuers.Load(typ)
+	if !ok {
+		ptrValuer = typ.Kind() != reflect.Ptr && reflect.PointerTo(typ).Implements(valuerType)
+		ptrValuers.Store(typ, ptrValuer)
+	}
+	if !ptrValuer.(bool) {
+		if dec, ok := value.(Decimal); ok {
+			return dec.String(), true
+		}
+		return value, false
+	}
+
+	ptr := reflect.New(typ)
+	ptr.Elem().Set(reflect.ValueOf(value))
+	return ptr.Interface(), true
+}
+
+// closeRows closes rows, logging any error
+func closeRows(rows *sql.Rows) {
+	if closeErr := rows.Close(); closeErr != nil {
+		log.Printf("failed to close rows: %v", closeErr)
+	}
+}
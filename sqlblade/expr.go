@@ -0,0 +1,192 @@
+package sqlblade
+
+import (
+	"strings"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// Expression is a raw SQL expression with its arguments, built with Expr
+type Expression struct {
+	sql  string
+	args []interface{}
+}
+
+// Expr returns a raw SQL expression for SQL functions and arithmetic the
+// builders can't express, e.g. Expr("views + ?", 1) or Expr("lower(email)").
+// "?" placeholders are converted to the dialect's placeholders; use "??" for
+// a literal question mark. Expressions can be:
+//
+//   - compared by Where, OrWhere and Having: Where("email", "=", Expr("lower(?)", email))
+//   - assigned by UpdateBuilder.Set: Set("views", Expr("views + ?", 1))
+//   - used as predicates by WhereExpr and HavingExpr
+//   - selected by SelectExpr and ordered by with OrderByExpr
+func Expr(sql string, args ...interface{}) Expression {
+	return Expression{sql: sql, args: args}
+}
+
+// SQL returns the SQL of the expression, with "?" placeholders
+func (e Expression) SQL() string {
+	return e.sql
+}
+
+// Args returns the arguments of the expression
+func (e Expression) Args() []interface{} {
+	return e.args
+}
+
+// write writes the expression with the dialect's placeholders, numbered on
+// from paramIndex
+func (e Expression) write(buf *strings.Builder, d dialect.Dialect, paramIndex *int, args *[]interface{}) {
+	buf.WriteString(rebindPlaceholders(d, e.sql, paramIndex))
+	*args = append(*args, e.args...)
+}
+
+// WhereExpr adds an expression as a WHERE predicate (AND), e.g.
+// WhereExpr(Expr("lower(email) = ?", email))
+func (qb *QueryBuilder[T]) WhereExpr(expr Expression) *QueryBuilder[T] {
+	return qb.WhereRaw(expr.sql, expr.args...)
+}
+
+// OrWhereExpr adds an expression as a WHERE predicate (OR)
+func (qb *QueryBuilder[T]) OrWhereExpr(expr Expression) *QueryBuilder[T] {
+	return qb.OrWhereRaw(expr.sql, expr.args...)
+}
+
+// HavingExpr adds an expression as a HAVING predicate, e.g.
+// HavingExpr(Expr("COUNT(*) > ?", 5))
+func (qb *QueryBuilder[T]) HavingExpr(expr Expression) *QueryBuilder[T] {
+	qb.having = append(qb.having, WhereClause{
+		Value: rawExpr{sql: expr.sql, args: expr.args},
+		And:   true,
+	})
+	return qb
+}
+
+// SelectExpr adds expressions to the selected columns, after the columns of
+// Select or *, e.g. SelectExpr(Expr("price * ? AS gross", 1.2))
+func (qb *QueryBuilder[T]) SelectExpr(exprs ...Expression) *QueryBuilder[T] {
+	qb.selectExprs = append(qb.selectExprs, exprs...)
+	return qb
+}
+
+// OrderByExpr adds an expression to the ORDER BY clause, e.g.
+// OrderByExpr(Expr("abs(score - ?)", target), dialect.ASC)
+func (qb *QueryBuilder[T]) OrderByExpr(expr Expression, order dialect.OrderDirection) *QueryBuilder[T] {
+	direction := " ASC"
+	if order == dialect.DESC {
+		direction = " DESC"
+	}
+	qb.orderBy = append(qb.orderBy, dialect.OrderBy{
+		Column: expr.sql + direction,
+		Order:  order,
+		Raw:    true,
+		Args:   expr.args,
+	})
+	return qb
+}
+
+// bindOrderBy returns the ORDER BY items with the placeholders of their raw
+// expressions converted to the dialect's, appending their arguments
+func bindOrderBy(d dialect.Dialect, orderBy []dialect.OrderBy, paramIndex *int, args *[]interface{}) []dialect.OrderBy {
+	var bound []dialect.OrderBy
+	for i, ob := range orderBy {
+		if !ob.Raw || len(ob.Args) == 0 {
+			continue
+		}
+		if bound == nil {
+			bound = append([]dialect.OrderBy(nil), orderBy...)
+		}
+		bound[i].Column = rebindPlaceholders(d, ob.Column, paramIndex)
+		*args = append(*args, ob.Args...)
+	}
+	if bound == nil {
+		return orderBy
+	}
+	return bound
+}
+
+// CaseBuilder builds a searched CASE expression, started with Case
+type CaseBuilder struct {
+	whens     []Expression
+	thens     []interface{}
+	elseValue interface{}
+	hasElse   bool
+}
+
+// Case starts a CASE expression for data-dependent values in UPDATE SET and
+// SELECT lists, e.g.
+//
+//	sqlblade.Update[Order](db).Set("tier", sqlblade.Case().
+//		When("total >= ?", 1000).Then("gold").
+//		When("total >= ?", 100).Then("silver").
+//		Else("bronze").End())
+//
+// Results given to Then and Else are bound as arguments, unless they are
+// Expressions. PostgreSQL types CASE results that are only bound arguments as
+// text; cast them, e.g. Then(Expr("CAST(? AS integer)", 1)), or give one
+// column reference such as Else(Expr(`"tier"`)).
+func Case() *CaseBuilder {
+	return &CaseBuilder{}
+}
+
+// When adds a condition with "?" placeholders, whose result is set by the
+// following Then
+func (cb *CaseBuilder) When(condition string, args ...interface{}) *CaseBuilder {
+	cb.whens = append(cb.whens, Expr(condition, args...))
+	cb.thens = append(cb.thens, nil)
+	return cb
+}
+
+// Then sets the result of the last condition added with When
+func (cb *CaseBuilder) Then(value interface{}) *CaseBuilder {
+	if len(cb.thens) > 0 {
+		cb.thens[len(cb.thens)-1] = value
+	}
+	return cb
+}
+
+// Else sets the result when no condition holds; without it, the result is NULL
+func (cb *CaseBuilder) Else(value interface{}) *CaseBuilder {
+	cb.elseValue = value
+	cb.hasElse = true
+	return cb
+}
+
+// End returns the CASE expression
+func (cb *CaseBuilder) End() Expression {
+	var buf strings.Builder
+	var args []interface{}
+	buf.WriteString("CASE")
+	for i, when := range cb.whens {
+		buf.WriteString(" WHEN ")
+		buf.WriteString(when.sql)
+		args = append(args, when.args...)
+		buf.WriteString(" THEN ")
+		args = writeCaseValue(&buf, cb.thens[i], args)
+	}
+	if cb.hasElse {
+		buf.WriteString(" ELSE ")
+		args = writeCaseValue(&buf, cb.elseValue, args)
+	}
+	buf.WriteString(" END")
+	return Expression{sql: buf.String(), args: args}
+}
+
+// As returns the CASE expression with an alias, for SelectExpr
+func (cb *CaseBuilder) As(alias string) Expression {
+	expr := cb.End()
+	expr.sql += " AS " + alias
+	return expr
+}
+
+// writeCaseValue writes a result of a CASE expression, inlining expressions
+// and binding other values
+func writeCaseValue(buf *strings.Builder, value interface{}, args []interface{}) []interface{} {
+	if expr, ok := value.(Expression); ok {
+		buf.WriteString(expr.sql)
+		return append(args, expr.args...)
+	}
+	buf.WriteByte('?')
+	return append(args, value)
+}
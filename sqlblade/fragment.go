@@ -0,0 +1,308 @@
+package sqlblade
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// Clone returns a copy of the fragment that can be changed without changing qf
+func (qf *QueryFragment) Clone() *QueryFragment {
+	clone := *qf
+	clone.whereClauses = append([]WhereClause(nil), qf.whereClauses...)
+	clone.joins = append([]dialect.Join(nil), qf.joins...)
+	clone.orderBy = append([]dialect.OrderBy(nil), qf.orderBy...)
+	clone.selectCols = append([]string(nil), qf.selectCols...)
+	clone.groupBy = append([]groupByExpr(nil), qf.groupBy...)
+	clone.having = append([]WhereClause(nil), qf.having...)
+	if qf.limit != nil {
+		limit := *qf.limit
+		clone.limit = &limit
+	}
+	if qf.offset != nil {
+		offset := *qf.offset
+		clone.offset = &offset
+	}
+	return &clone
+}
+
+// Not returns a copy of the fragment matching the rows its WHERE conditions
+// don't match, e.g. to turn a saved "active users" filter into "inactive
+// users". The other parts of the fragment are kept; a fragment without
+// conditions negated matches no rows.
+func (qf *QueryFragment) Not() *QueryFragment {
+	clone := qf.Clone()
+	group := &ConditionGroup{clauses: clone.whereClauses, not: true}
+	clone.whereClauses = []WhereClause{{Value: group, And: true}}
+	return clone
+}
+
+// Merge returns a fragment with the parts of qf and other: the rows must
+// match the WHERE and HAVING conditions of both, joins, ordering, selected
+// columns and grouping are those of qf followed by those of other, and the
+// limit and offset of qf take precedence
+func (qf *QueryFragment) Merge(other *QueryFragment) *QueryFragment {
+	merged := qf.Clone()
+	if other == nil {
+		return merged
+	}
+	merged.whereClauses = mergeConditions(merged.whereClauses, other.whereClauses)
+	merged.having = mergeConditions(merged.having, other.having)
+	merged.joins = append(merged.joins, other.joins...)
+	merged.orderBy = append(merged.orderBy, other.orderBy...)
+	merged.selectCols = append(merged.selectCols, other.selectCols...)
+	merged.groupBy = append(merged.groupBy, other.groupBy...)
+	merged.distinct = merged.distinct || other.distinct
+	if merged.limit == nil && other.limit != nil {
+		limit := *other.limit
+		merged.limit = &limit
+	}
+	if merged.offset == nil && other.offset != nil {
+		offset := *other.offset
+		merged.offset = &offset
+	}
+	return merged
+}
+
+// mergeConditions returns the conditions of a AND those of b, grouping the
+// sides with OR conditions so that they don't bind to the other side
+func mergeConditions(a, b []WhereClause) []WhereClause {
+	if len(a) == 0 || len(b) == 0 {
+		return append(append([]WhereClause(nil), a...), b...)
+	}
+	return append(andGroup(a), andGroup(b)...)
+}
+
+// andGroup returns the clauses, in a group joined with AND when they have an
+// OR condition
+func andGroup(clauses []WhereClause) []WhereClause {
+	for i, clause := range clauses {
+		if i > 0 && !clause.And {
+			return []WhereClause{{Value: &ConditionGroup{clauses: clauses}, And: true}}
+		}
+	}
+	result := append([]WhereClause(nil), clauses...)
+	result[0].And = true
+	return result
+}
+
+// fragmentJSON is the JSON form of a QueryFragment
+type fragmentJSON struct {
+	Where    []conditionJSON `json:"where,omitempty"`
+	Joins    []joinJSON      `json:"joins,omitempty"`
+	OrderBy  []orderByJSON   `json:"orderBy,omitempty"`
+	Select   []string        `json:"select,omitempty"`
+	GroupBy  []groupByJSON   `json:"groupBy,omitempty"`
+	Having   []conditionJSON `json:"having,omitempty"`
+	Distinct bool            `json:"distinct,omitempty"`
+	Limit    *int            `json:"limit,omitempty"`
+	Offset   *int            `json:"offset,omitempty"`
+}
+
+// conditionJSON is the JSON form of a WHERE or HAVING condition; Kind is
+// the name of its ConditionKind
+type conditionJSON struct {
+	Kind        string          `json:"kind"`
+	Or          bool            `json:"or,omitempty"`
+	Column      string          `json:"column,omitempty"`
+	Operator    string          `json:"op,omitempty"`
+	Value       interface{}     `json:"value,omitempty"`
+	OtherColumn string          `json:"otherColumn,omitempty"`
+	SQL         string          `json:"sql,omitempty"`
+	Args        []interface{}   `json:"args,omitempty"`
+	Children    []conditionJSON `json:"children,omitempty"`
+	Not         bool            `json:"not,omitempty"`
+}
+
+// joinJSON is the JSON form of a join; Type is e.g. "LEFT JOIN"
+type joinJSON struct {
+	Type      string `json:"type"`
+	Table     string `json:"table"`
+	Alias     string `json:"alias,omitempty"`
+	Condition string `json:"condition"`
+}
+
+// orderByJSON is the JSON form of an ORDER BY item
+type orderByJSON struct {
+	Column string        `json:"column"`
+	Desc   bool          `json:"desc,omitempty"`
+	Raw    bool          `json:"raw,omitempty"`
+	Args   []interface{} `json:"args,omitempty"`
+}
+
+// groupByJSON is the JSON form of a GROUP BY item
+type groupByJSON struct {
+	Expr string `json:"expr"`
+	Raw  bool   `json:"raw,omitempty"`
+}
+
+// MarshalJSON encodes the fragment as JSON, e.g. to store saved filters.
+// Values are encoded as JSON values, so integers decode as int64, other
+// numbers as float64 and times as strings; conditions on subqueries and
+// expressions can't be encoded and fail with ErrInvalidFragment.
+func (qf *QueryFragment) MarshalJSON() ([]byte, error) {
+	var f fragmentJSON
+	var err error
+	if f.Where, err = encodeConditions(qf.whereClauses); err != nil {
+		return nil, err
+	}
+	if f.Having, err = encodeConditions(qf.having); err != nil {
+		return nil, err
+	}
+	for _, join := range qf.joins {
+		f.Joins = append(f.Joins, joinJSON{Type: join.Type.String(), Table: join.Table, Alias: join.Alias, Condition: join.Condition})
+	}
+	for _, ob := range qf.orderBy {
+		f.OrderBy = append(f.OrderBy, orderByJSON{Column: ob.Column, Desc: ob.Order == dialect.DESC, Raw: ob.Raw, Args: ob.Args})
+	}
+	for _, gb := range qf.groupBy {
+		f.GroupBy = append(f.GroupBy, groupByJSON{Expr: gb.expr, Raw: gb.raw})
+	}
+	f.Select = qf.selectCols
+	f.Distinct = qf.distinct
+	f.Limit = qf.limit
+	f.Offset = qf.offset
+	return json.Marshal(f)
+}
+
+// UnmarshalJSON decodes a fragment encoded by MarshalJSON, replacing the
+// parts of qf
+func (qf *QueryFragment) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var f fragmentJSON
+	if err := dec.Decode(&f); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFragment, err)
+	}
+
+	decoded := NewQueryFragment()
+	var err error
+	if decoded.whereClauses, err = decodeConditions(f.Where); err != nil {
+		return err
+	}
+	if decoded.having, err = decodeConditions(f.Having); err != nil {
+		return err
+	}
+	for _, join := range f.Joins {
+		joinType, ok := joinTypes[join.Type]
+		if !ok {
+			return fmt.Errorf("%w: join type %q", ErrInvalidFragment, join.Type)
+		}
+		decoded.joins = append(decoded.joins, dialect.Join{Type: joinType, Table: join.Table, Alias: join.Alias, Condition: join.Condition})
+	}
+	for _, ob := range f.OrderBy {
+		order := dialect.ASC
+		if ob.Desc {
+			order = dialect.DESC
+		}
+		decoded.orderBy = append(decoded.orderBy, dialect.OrderBy{Column: ob.Column, Order: order, Raw: ob.Raw, Args: fragmentValues(ob.Args)})
+	}
+	for _, gb := range f.GroupBy {
+		decoded.groupBy = append(decoded.groupBy, groupByExpr{expr: gb.Expr, raw: gb.Raw})
+	}
+	if f.Select != nil {
+		decoded.selectCols = f.Select
+	}
+	decoded.distinct = f.Distinct
+	decoded.limit = f.Limit
+	decoded.offset = f.Offset
+	*qf = *decoded
+	return nil
+}
+
+// joinTypes maps the SQL of join types to them
+var joinTypes = map[string]dialect.JoinType{
+	dialect.InnerJoin.String(): dialect.InnerJoin,
+	dialect.LeftJoin.String():  dialect.LeftJoin,
+	dialect.RightJoin.String(): dialect.RightJoin,
+	dialect.FullJoin.String():  dialect.FullJoin,
+}
+
+// encodeConditions returns the JSON form of clauses
+func encodeConditions(clauses []WhereClause) ([]conditionJSON, error) {
+	var result []conditionJSON
+	for i, clause := range clauses {
+		c := conditionJSON{Or: i > 0 && !clause.And}
+		switch v := clause.Value.(type) {
+		case *ConditionGroup:
+			children, err := encodeConditions(v.clauses)
+			if err != nil {
+				return nil, err
+			}
+			c.Kind, c.Children, c.Not = GroupCondition.String(), children, v.not
+		case rawExpr:
+			c.Kind, c.SQL, c.Args = RawCondition.String(), v.sql, v.args
+		case *Subquery, Expression:
+			return nil, fmt.Errorf("%w: cannot encode %T compared with %s", ErrInvalidFragment, v, clause.Column)
+		case ColumnRef:
+			c.Kind, c.Column, c.Operator, c.OtherColumn = CompareCondition.String(), clause.Column, clause.Operator, string(v)
+		default:
+			c.Kind, c.Column, c.Operator, c.Value = CompareCondition.String(), clause.Column, clause.Operator, v
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// decodeConditions returns the clauses of their JSON form
+func decodeConditions(conditions []conditionJSON) ([]WhereClause, error) {
+	clauses := make([]WhereClause, 0, len(conditions))
+	for _, c := range conditions {
+		clause := WhereClause{And: !c.Or}
+		switch c.Kind {
+		case GroupCondition.String():
+			children, err := decodeConditions(c.Children)
+			if err != nil {
+				return nil, err
+			}
+			clause.Value = &ConditionGroup{clauses: children, not: c.Not}
+		case RawCondition.String():
+			clause.Value = rawExpr{sql: c.SQL, args: fragmentValues(c.Args)}
+		case CompareCondition.String():
+			clause.Column, clause.Operator = c.Column, c.Operator
+			clause.Value = fragmentValue(c.Value)
+			if c.OtherColumn != "" {
+				clause.Value = ColumnRef(c.OtherColumn)
+			}
+		default:
+			return nil, fmt.Errorf("%w: condition kind %q", ErrInvalidFragment, c.Kind)
+		}
+		clauses = append(clauses, clause)
+	}
+	return clauses, nil
+}
+
+// fragmentValue converts a decoded JSON value: integers to int64, other
+// numbers to float64, arrays to []interface{}, {"param": name} to Param(name)
+func fragmentValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if name, ok := v["param"].(string); ok && len(v) == 1 {
+			return Param(name)
+		}
+	case json.Number:
+		if n, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		return fragmentValues(v)
+	}
+	return value
+}
+
+// fragmentValues converts decoded JSON values, see fragmentValue
+func fragmentValues(values []interface{}) []interface{} {
+	if values == nil {
+		return nil
+	}
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		result[i] = fragmentValue(value)
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+package sqlblade
+
+import "github.com/alicanli1995/sqlblade/sqlblade/dialect"
+
+// AllowFullTable allows the update to run without WHERE conditions,
+// rewriting every row of the table. Without it, such updates fail with
+// ErrFullTable, as a forgotten Where would otherwise go unnoticed.
+func (ub *UpdateBuilder[T]) AllowFullTable() *UpdateBuilder[T] {
+	ub.allowFull = true
+	return ub
+}
+
+// AllowFullTable allows the delete to run without WHERE conditions, emptying
+// the table. Without it, such deletes fail with ErrFullTable.
+func (db *DeleteBuilder[T]) AllowFullTable() *DeleteBuilder[T] {
+	db.allowFull = true
+	return db
+}
+
+// checkFullTable returns ErrFullTable when the update has no conditions and
+// AllowFullTable wasn't called. Joined tables restrict the updated rows too.
+func (ub *UpdateBuilder[T]) checkFullTable() error {
+	if ub.allowFull || len(ub.joins) > 0 || hasConditions(ub.dialect, ub.whereClauses) {
+		return nil
+	}
+	return ErrFullTable
+}
+
+// checkFullTable returns ErrFullTable when the delete has no conditions and
+// AllowFullTable wasn't called. A LIMIT bounds the deleted rows too.
+func (db *DeleteBuilder[T]) checkFullTable() error {
+	if db.allowFull || db.limit != nil || hasConditions(db.dialect, db.whereClauses) {
+		return nil
+	}
+	return ErrFullTable
+}
+
+// hasConditions reports whether the clauses restrict the rows, so that empty
+// groups and conditions that always match, such as NOT IN with an empty list,
+// don't count as conditions
+func hasConditions(d dialect.Dialect, clauses []WhereClause) bool {
+	paramIndex := 0
+	var args []interface{}
+	rendered, always := alwaysTrue(d, clauses, &paramIndex, &args)
+	return rendered && !always
+}
+
+// alwaysTrue reports whether any of the clauses render and whether they then
+// match all rows: as AND binds tighter than OR, when one of the terms joined
+// with OR only has conditions rendering TRUE
+func alwaysTrue(d dialect.Dialect, clauses []WhereClause, paramIndex *int, args *[]interface{}) (rendered, always bool) {
+	termTrue := true
+	for _, clause := range clauses {
+		var condRendered, condTrue bool
+		if group, ok := clause.Value.(*ConditionGroup); ok && !group.not {
+			condRendered, condTrue = alwaysTrue(d, group.clauses, paramIndex, args)
+		} else {
+			condition := buildCondition(d, clause, paramIndex, args)
+			condRendered, condTrue = condition != "", condition == "TRUE"
+		}
+		if !condRendered {
+			continue
+		}
+		if rendered && !clause.And {
+			if termTrue {
+				return true, true
+			}
+			termTrue = true
+		}
+		rendered = true
+		termTrue = termTrue && condTrue
+	}
+	return rendered, rendered && termTrue
+}
@@ -0,0 +1,64 @@
+package sqlblade_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+)
+
+func TestDeleteFullTable(t *testing.T) {
+	ctx := context.Background()
+	db, _ := openRecorder(t)
+
+	tests := []struct {
+		name     string
+		build    func(*sqlblade.DeleteBuilder[txUser]) *sqlblade.DeleteBuilder[txUser]
+		fullScan bool
+	}{
+		{"no conditions", func(b *sqlblade.DeleteBuilder[txUser]) *sqlblade.DeleteBuilder[txUser] { return b }, true},
+		{"empty group", func(b *sqlblade.DeleteBuilder[txUser]) *sqlblade.DeleteBuilder[txUser] {
+			return b.WhereGroup(func(*sqlblade.ConditionGroup) {})
+		}, true},
+		{"empty NOT IN", func(b *sqlblade.DeleteBuilder[txUser]) *sqlblade.DeleteBuilder[txUser] {
+			return b.Where("id", "NOT IN", []int{})
+		}, true},
+		{"OR empty NOT IN", func(b *sqlblade.DeleteBuilder[txUser]) *sqlblade.DeleteBuilder[txUser] {
+			return b.Where("id", "=", 1).OrWhere("id", "NOT IN", []int{})
+		}, true},
+		{"grouped empty NOT IN", func(b *sqlblade.DeleteBuilder[txUser]) *sqlblade.DeleteBuilder[txUser] {
+			return b.WhereGroup(func(g *sqlblade.ConditionGroup) { g.Where("id", "NOT IN", []int{}) })
+		}, true},
+		{"AND empty NOT IN", func(b *sqlblade.DeleteBuilder[txUser]) *sqlblade.DeleteBuilder[txUser] {
+			return b.Where("id", "=", 1).Where("name", "NOT IN", []string{})
+		}, false},
+		{"empty IN", func(b *sqlblade.DeleteBuilder[txUser]) *sqlblade.DeleteBuilder[txUser] {
+			return b.Where("id", "IN", []int{})
+		}, false},
+		{"allowed", func(b *sqlblade.DeleteBuilder[txUser]) *sqlblade.DeleteBuilder[txUser] {
+			return b.Where("id", "NOT IN", []int{}).AllowFullTable()
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.build(sqlblade.Delete[txUser](db)).Execute(ctx)
+			if got := errors.Is(err, sqlblade.ErrFullTable); got != tt.fullScan {
+				t.Errorf("Execute error = %v, want ErrFullTable: %v", err, tt.fullScan)
+			}
+		})
+	}
+}
+
+func TestUpdateFullTable(t *testing.T) {
+	ctx := context.Background()
+	db, _ := openRecorder(t)
+
+	_, err := sqlblade.Update[txUser](db).Set("name", "x").Where("id", "NOT IN", []int{}).Execute(ctx)
+	if !errors.Is(err, sqlblade.ErrFullTable) {
+		t.Errorf("Execute error = %v, want ErrFullTable", err)
+	}
+	if _, err := sqlblade.Update[txUser](db).Set("name", "x").Where("id", "=", 1).Execute(ctx); err != nil {
+		t.Errorf("Execute error = %v, want nil", err)
+	}
+}
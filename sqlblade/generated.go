@@ -0,0 +1,100 @@
+package sqlblade
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Generated holds the functions emitted by the sqlbladegen code generator for
+// a model. Once registered, rows are scanned into the model through them
+// instead of reflection.
+//
+// Generated scanners leave type conversion to database/sql, so NULL columns
+// need pointer, Null or sql.Null* fields.
+type Generated[T any] struct {
+	// Columns returns the model's columns in field order
+	Columns func() []string
+	// Values returns the values of v's fields in Columns order
+	Values func(v *T) []interface{}
+	// ScanRow stores pointers to v's fields in dest, in Columns order
+	ScanRow func(v *T, dest []interface{})
+}
+
+// generatedModel is a registered Generated with its column index
+type generatedModel[T any] struct {
+	Generated[T]
+	size  int
+	index map[string]int // lower-case column -> position in Columns
+}
+
+// generatedModels holds the registered generated models
+var generatedModels sync.Map // map[reflect.Type]interface{} (*generatedModel[T])
+
+// RegisterGenerated registers the generated functions of T. It is called from
+// the init function of the code emitted by sqlbladegen.
+func RegisterGenerated[T any](g Generated[T]) {
+	if g.Columns == nil || g.ScanRow == nil {
+		panic(fmt.Sprintf("sqlblade: incomplete generated functions for %T", *new(T)))
+	}
+
+	columns := g.Columns()
+	index := make(map[string]int, len(columns))
+	for i, col := range columns {
+		index[strings.ToLower(col)] = i
+	}
+	generatedModels.Store(reflect.TypeOf((*T)(nil)).Elem(), &generatedModel[T]{Generated: g, size: len(columns), index: index})
+}
+
+// generatedFor returns the generated model registered for T, or nil
+func generatedFor[T any]() *generatedModel[T] {
+	g, ok := generatedModels.Load(reflect.TypeOf((*T)(nil)).Elem())
+	if !ok {
+		return nil
+	}
+	return g.(*generatedModel[T])
+}
+
+// generatedScan scans rows through a generated model
+type generatedScan[T any] struct {
+	model  *generatedModel[T]
+	fields []int // result column -> position in Columns, -1 when not a field
+	ptrs   []interface{}
+	dest   []interface{}
+}
+
+// newGeneratedScan prepares scanning the result columns through model. Columns
+// that are not model fields are scanned into buf.
+func newGeneratedScan[T any](model *generatedModel[T], columns []string, buf *scanBuffer) *generatedScan[T] {
+	gs := &generatedScan[T]{
+		model:  model,
+		fields: make([]int, len(columns)),
+		ptrs:   make([]interface{}, model.size),
+		dest:   make([]interface{}, len(columns)),
+	}
+	for i, col := range columns {
+		pos, ok := model.index[cachedToLower(col)]
+		if !ok {
+			pos = -1
+			gs.dest[i] = buf.ptrs[i]
+		}
+		gs.fields[i] = pos
+	}
+	return gs
+}
+
+// scan reads the current row into dest
+func (gs *generatedScan[T]) scan(rows *sql.Rows, dest *T) error {
+	gs.model.ScanRow(dest, gs.ptrs)
+	for i, pos := range gs.fields {
+		if pos >= 0 {
+			gs.dest[i] = gs.ptrs[pos]
+		}
+	}
+	if err := rows.Scan(gs.dest...); err != nil {
+		return fmt.Errorf("sqlblade: failed to scan row: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package sqlblade
+
+import (
+	"context"
+	"fmt"
+)
+
+// FieldFilter is a GraphQL filter input on a column; its set operators are
+// combined with AND. sqlbladegen -graphql emits filter inputs built of them.
+type FieldFilter[V any] struct {
+	Eq     *V      `json:"eq,omitempty"`
+	Ne     *V      `json:"ne,omitempty"`
+	In     []V     `json:"in,omitempty"`
+	NotIn  []V     `json:"notIn,omitempty"`
+	Lt     *V      `json:"lt,omitempty"`
+	Lte    *V      `json:"lte,omitempty"`
+	Gt     *V      `json:"gt,omitempty"`
+	Gte    *V      `json:"gte,omitempty"`
+	Like   *string `json:"like,omitempty"`
+	IsNull *bool   `json:"isNull,omitempty"`
+}
+
+// FilterWhere adds the conditions of a filter input on column to the query;
+// a nil filter adds none
+func FilterWhere[T, V any](qb *QueryBuilder[T], column string, f *FieldFilter[V]) *QueryBuilder[T] {
+	if f == nil {
+		return qb
+	}
+	for _, cmp := range []struct {
+		op    string
+		value *V
+	}{{"=", f.Eq}, {"!=", f.Ne}, {"<", f.Lt}, {"<=", f.Lte}, {">", f.Gt}, {">=", f.Gte}} {
+		if cmp.value != nil {
+			qb.Where(column, cmp.op, *cmp.value)
+		}
+	}
+	if f.In != nil {
+		qb.WhereIn(column, toInterfaces(f.In)...)
+	}
+	if f.NotIn != nil {
+		qb.WhereNotIn(column, toInterfaces(f.NotIn)...)
+	}
+	if f.Like != nil {
+		qb.Where(column, "LIKE", *f.Like)
+	}
+	if f.IsNull != nil {
+		if *f.IsNull {
+			qb.Where(column, "IS NULL", nil)
+		} else {
+			qb.Where(column, "IS NOT NULL", nil)
+		}
+	}
+	return qb
+}
+
+// ConnectionArgs are the arguments of a Relay connection field
+type ConnectionArgs struct {
+	First  *int    `json:"first,omitempty"`
+	After  *string `json:"after,omitempty"`
+	Last   *int    `json:"last,omitempty"`
+	Before *string `json:"before,omitempty"`
+}
+
+// Connection is a page of a Relay connection
+type Connection[T any] struct {
+	Edges    []Edge[T] `json:"edges"`
+	PageInfo PageInfo  `json:"pageInfo"`
+}
+
+// Edge is a row of a Connection with its cursor
+type Edge[T any] struct {
+	Node   T      `json:"node"`
+	Cursor string `json:"cursor"`
+}
+
+// PageInfo describes the position of a Connection page
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+}
+
+// Connect resolves a Relay connection over the query with CursorPaginate, so
+// the query must be ordered by columns identifying its rows. Forward pages
+// take First rows After a cursor, backward pages Last rows Before one, or the
+// last rows without it; without First or Last, pages have defaultSize rows.
+func Connect[T any](ctx context.Context, qb *QueryBuilder[T], args ConnectionArgs, defaultSize int) (*Connection[T], error) {
+	if args.First != nil && args.Last != nil {
+		return nil, fmt.Errorf("%w: first and last can't be combined", ErrInvalidPage)
+	}
+
+	size := defaultSize
+	switch {
+	case args.Last != nil:
+		size = *args.Last
+	case args.First != nil:
+		size = *args.First
+	}
+	switch {
+	case args.Last != nil || args.Before != nil && args.First == nil:
+		// Without a cursor, the last rows of the whole result
+		before := ""
+		if args.Before != nil {
+			before = *args.Before
+		}
+		qb.Before(Cursor(before))
+	case args.After != nil:
+		qb.After(Cursor(*args.After))
+	}
+
+	page, err := qb.CursorPaginate(ctx, size)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &Connection[T]{
+		Edges: make([]Edge[T], 0, len(page.Items)),
+		PageInfo: PageInfo{
+			HasNextPage:     page.HasNext,
+			HasPreviousPage: page.HasPrev,
+		},
+	}
+	for _, item := range page.Items {
+		cursor, err := encodeCursor(item, qb.orderBy)
+		if err != nil {
+			return nil, err
+		}
+		conn.Edges = append(conn.Edges, Edge[T]{Node: item, Cursor: string(cursor)})
+	}
+	if len(conn.Edges) > 0 {
+		start, end := conn.Edges[0].Cursor, conn.Edges[len(conn.Edges)-1].Cursor
+		conn.PageInfo.StartCursor, conn.PageInfo.EndCursor = &start, &end
+	}
+	return conn, nil
+}
@@ -0,0 +1,114 @@
+package sqlblade
+
+// ConditionGroup collects conditions that are combined in parentheses, e.g.
+//
+//	Where("a", "=", 1).WhereGroup(func(g *sqlblade.ConditionGroup) {
+//	    g.Where("b", "=", 2).OrWhere("c", "=", 3)
+//	})
+//
+// produces WHERE a = 1 AND (b = 2 OR c = 3). Empty groups are left out.
+type ConditionGroup struct {
+	clauses []WhereClause
+	not     bool // negated with NOT
+}
+
+// Where adds a condition to the group (AND)
+func (g *ConditionGroup) Where(column string, operator string, value interface{}) *ConditionGroup {
+	g.clauses = append(g.clauses, WhereClause{
+		Column:   column,
+		Operator: operator,
+		Value:    value,
+		And:      true,
+	})
+	return g
+}
+
+// OrWhere adds a condition to the group (OR)
+func (g *ConditionGroup) OrWhere(column string, operator string, value interface{}) *ConditionGroup {
+	g.clauses = append(g.clauses, WhereClause{
+		Column:   column,
+		Operator: operator,
+		Value:    value,
+		And:      false,
+	})
+	return g
+}
+
+// WhereColumn adds a condition comparing two columns (AND)
+func (g *ConditionGroup) WhereColumn(column string, operator string, otherColumn string) *ConditionGroup {
+	return g.Where(column, operator, ColumnRef(otherColumn))
+}
+
+// WhereIn adds a column IN (...) condition (AND)
+func (g *ConditionGroup) WhereIn(column string, values ...interface{}) *ConditionGroup {
+	return g.Where(column, "IN", values)
+}
+
+// WhereRaw adds a raw predicate (AND), using "?" as placeholder for args
+func (g *ConditionGroup) WhereRaw(sql string, args ...interface{}) *ConditionGroup {
+	g.clauses = append(g.clauses, WhereClause{Value: rawExpr{sql: sql, args: args}, And: true})
+	return g
+}
+
+// OrWhereRaw adds a raw predicate (OR)
+func (g *ConditionGroup) OrWhereRaw(sql string, args ...interface{}) *ConditionGroup {
+	g.clauses = append(g.clauses, WhereClause{Value: rawExpr{sql: sql, args: args}, And: false})
+	return g
+}
+
+// WhereGroup adds a nested group (AND)
+func (g *ConditionGroup) WhereGroup(fn func(g *ConditionGroup)) *ConditionGroup {
+	g.clauses = append(g.clauses, groupClause(fn, true))
+	return g
+}
+
+// OrWhereGroup adds a nested group (OR)
+func (g *ConditionGroup) OrWhereGroup(fn func(g *ConditionGroup)) *ConditionGroup {
+	g.clauses = append(g.clauses, groupClause(fn, false))
+	return g
+}
+
+// groupClause builds the WHERE clause holding a condition group
+func groupClause(fn func(g *ConditionGroup), and bool) WhereClause {
+	group := &ConditionGroup{}
+	if fn != nil {
+		fn(group)
+	}
+	return WhereClause{Value: group, And: and}
+}
+
+// WhereGroup adds a parenthesized group of conditions (AND)
+func (qb *QueryBuilder[T]) WhereGroup(fn func(g *ConditionGroup)) *QueryBuilder[T] {
+	qb.whereClauses = append(qb.whereClauses, groupClause(fn, true))
+	return qb
+}
+
+// OrWhereGroup adds a parenthesized group of conditions (OR)
+func (qb *QueryBuilder[T]) OrWhereGroup(fn func(g *ConditionGroup)) *QueryBuilder[T] {
+	qb.whereClauses = append(qb.whereClauses, groupClause(fn, false))
+	return qb
+}
+
+// WhereGroup adds a parenthesized group of conditions (AND)
+func (ub *UpdateBuilder[T]) WhereGroup(fn func(g *ConditionGroup)) *UpdateBuilder[T] {
+	ub.whereClauses = append(ub.whereClauses, groupClause(fn, true))
+	return ub
+}
+
+// OrWhereGroup adds a parenthesized group of conditions (OR)
+func (ub *UpdateBuilder[T]) OrWhereGroup(fn func(g *ConditionGroup)) *UpdateBuilder[T] {
+	ub.whereClauses = append(ub.whereClauses, groupClause(fn, false))
+	return ub
+}
+
+// WhereGroup adds a parenthesized group of conditions (AND)
+func (db *DeleteBuilder[T]) WhereGroup(fn func(g *ConditionGroup)) *DeleteBuilder[T] {
+	db.whereClauses = append(db.whereClauses, groupClause(fn, true))
+	return db
+}
+
+// OrWhereGroup adds a parenthesized group of conditions (OR)
+func (db *DeleteBuilder[T]) OrWhereGroup(fn func(g *ConditionGroup)) *DeleteBuilder[T] {
+	db.whereClauses = append(db.whereClauses, groupClause(fn, false))
+	return db
+}
@@ -0,0 +1,112 @@
+package sqlblade
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"math"
+	"time"
+)
+
+// HashQuery returns a stable hex-encoded SHA-256 hash of a statement and its
+// arguments, e.g. for application cache keys or HTTP ETags. Arguments are
+// normalized to their driver values first, so int32(1) and int64(1), Null
+// wrappers and their values, or the same instant in different time zones
+// hash the same.
+func HashQuery(sqlStr string, args []interface{}) string {
+	h := sha256.New()
+	writeHashString(h, sqlStr)
+	for _, arg := range args {
+		writeHashArg(h, arg)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeHashString writes a length-prefixed string, so that adjacent values
+// can't run into each other
+func writeHashString(h hash.Hash, s string) {
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(s)))
+	h.Write(n[:])
+	h.Write([]byte(s))
+}
+
+// writeHashArg writes a type tag and the normalized value of an argument
+func writeHashArg(h hash.Hash, arg interface{}) {
+	v, err := driver.DefaultParameterConverter.ConvertValue(arg)
+	if err != nil {
+		// Values the driver converts itself (e.g. slices) are hashed as printed
+		h.Write([]byte{'?'})
+		writeHashString(h, fmt.Sprintf("%T:%#v", arg, arg))
+		return
+	}
+
+	switch x := v.(type) {
+	case nil:
+		h.Write([]byte{'n'})
+	case int64:
+		h.Write([]byte{'i'})
+		writeHashString(h, fmt.Sprint(x))
+	case float64:
+		h.Write([]byte{'f'})
+		writeHashString(h, fmt.Sprint(math.Float64bits(x)))
+	case bool:
+		h.Write([]byte{'b'})
+		writeHashString(h, fmt.Sprint(x))
+	case string:
+		h.Write([]byte{'s'})
+		writeHashString(h, x)
+	case []byte:
+		h.Write([]byte{'x'})
+		writeHashString(h, string(x))
+	case time.Time:
+		h.Write([]byte{'t'})
+		writeHashString(h, x.UTC().Format(time.RFC3339Nano))
+	default:
+		h.Write([]byte{'?'})
+		writeHashString(h, fmt.Sprintf("%T:%#v", x, x))
+	}
+}
+
+// Hash returns a stable hash of the query's SQL and arguments, see HashQuery
+func (qb *QueryBuilder[T]) Hash() string {
+	sqlStr, args := qb.buildSQL()
+	return HashQuery(sqlStr, args)
+}
+
+// Hash returns a stable hash of the query's SQL and arguments, see HashQuery
+func (qp *QueryPreview[T]) Hash() string {
+	return qp.builder.Hash()
+}
+
+// Hash returns a stable hash of the statement's SQL and arguments, see HashQuery
+func (ib *InsertBuilder[T]) Hash() (string, error) {
+	sqlStr, args, err := ib.buildSQL(ib.returning)
+	if err != nil {
+		return "", err
+	}
+	return HashQuery(sqlStr, args), nil
+}
+
+// Hash returns a stable hash of the statement's SQL and arguments, see HashQuery
+func (ub *UpdateBuilder[T]) Hash() (string, error) {
+	sqlStr, args, err := ub.buildSQL(ub.returning)
+	if err != nil {
+		return "", err
+	}
+	return HashQuery(sqlStr, args), nil
+}
+
+// Hash returns a stable hash of the statement's SQL and arguments, see HashQuery
+func (db *DeleteBuilder[T]) Hash() string {
+	sqlStr, args := db.buildSQL(db.returning)
+	return HashQuery(sqlStr, args)
+}
+
+// Hash returns a stable hash of the query and its arguments, see HashQuery
+func (rq *RawQuery[T]) Hash() string {
+	return HashQuery(rq.query, rq.args)
+}
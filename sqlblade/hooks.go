@@ -2,10 +2,59 @@ package sqlblade
 
 import (
 	"context"
+	"database/sql"
+	"reflect"
+	"sync"
+	"time"
 )
 
+// HookEvent describes a query passed to hooks. Before hooks get the statement
+// and StartTime; after hooks also get the Duration, the error and, for
+// INSERT/UPDATE/DELETE, the number of affected rows. For queries, Duration
+// includes ScanDuration.
+type HookEvent struct {
+	Operation    string // SELECT, INSERT, UPDATE, ...
+	Table        string
+	SQL          string
+	Args         []interface{}
+	StartTime    time.Time
+	Duration     time.Duration
+	Err          error
+	RowsAffected int64
+	ScanDuration time.Duration // time spent scanning the rows of queries
+	Tags         []string      // set with the builders' Tag
+
+	clock Clock
+}
+
+// newHookEvent returns the event of a statement that is about to be executed,
+// timed with the client's clock
+func (c *Client) newHookEvent(stmt *Statement) *HookEvent {
+	clock := c.Clock()
+	return &HookEvent{
+		Operation: stmt.Operation,
+		Table:     stmt.Table,
+		SQL:       stmt.SQL,
+		Args:      stmt.Args,
+		Tags:      stmt.Tags,
+		StartTime: clock.Now(),
+		clock:     clock,
+	}
+}
+
+// finish records the outcome of the statement
+func (e *HookEvent) finish(err error, result sql.Result) {
+	e.Duration = e.clock.Now().Sub(e.StartTime)
+	e.Err = err
+	if result != nil {
+		if n, err := result.RowsAffected(); err == nil {
+			e.RowsAffected = n
+		}
+	}
+}
+
 // QueryHook defines a hook function that can be called before or after queries
-type QueryHook func(ctx context.Context, query string, args []interface{}) error
+type QueryHook func(ctx context.Context, event *HookEvent) error
 
 // HookType represents the type of hook
 type HookType int
@@ -15,19 +64,23 @@ const (
 	BeforeQuery HookType = iota
 	// AfterQuery hook is called after executing a query successfully
 	AfterQuery
+	// AfterQueryWithError hook is called after executing a query, including failed ones
+	AfterQueryWithError
 )
 
 // Hooks manages query hooks
 type Hooks struct {
-	beforeQuery []QueryHook
-	afterQuery  []QueryHook
+	beforeQuery         []QueryHook
+	afterQuery          []QueryHook
+	afterQueryWithError []QueryHook
 }
 
 // NewHooks creates a new hooks manager
 func NewHooks() *Hooks {
 	return &Hooks{
-		beforeQuery: make([]QueryHook, 0),
-		afterQuery:  make([]QueryHook, 0),
+		beforeQuery:         make([]QueryHook, 0),
+		afterQuery:          make([]QueryHook, 0),
+		afterQueryWithError: make([]QueryHook, 0),
 	}
 }
 
@@ -36,25 +89,39 @@ func (h *Hooks) BeforeQuery(hook QueryHook) {
 	h.beforeQuery = append(h.beforeQuery, hook)
 }
 
-// AfterQuery adds a hook to be called after query execution
+// AfterQuery adds a hook to be called after successful query execution
 func (h *Hooks) AfterQuery(hook QueryHook) {
 	h.afterQuery = append(h.afterQuery, hook)
 }
 
+// AfterQueryWithError adds a hook to be called after every query execution,
+// with event.Err set for failed queries, e.g. for metrics
+func (h *Hooks) AfterQueryWithError(hook QueryHook) {
+	h.afterQueryWithError = append(h.afterQueryWithError, hook)
+}
+
 // ExecuteBeforeHooks executes all before query hooks
-func (h *Hooks) ExecuteBeforeHooks(ctx context.Context, query string, args []interface{}) error {
+func (h *Hooks) ExecuteBeforeHooks(ctx context.Context, event *HookEvent) error {
 	for _, hook := range h.beforeQuery {
-		if err := hook(ctx, query, args); err != nil {
+		if err := hook(ctx, event); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// ExecuteAfterHooks executes all after query hooks
-func (h *Hooks) ExecuteAfterHooks(ctx context.Context, query string, args []interface{}) error {
-	for _, hook := range h.afterQuery {
-		if err := hook(ctx, query, args); err != nil {
+// ExecuteAfterHooks executes the after query hooks; AfterQuery hooks only run
+// when event.Err is nil
+func (h *Hooks) ExecuteAfterHooks(ctx context.Context, event *HookEvent) error {
+	if event.Err == nil {
+		for _, hook := range h.afterQuery {
+			if err := hook(ctx, event); err != nil {
+				return err
+			}
+		}
+	}
+	for _, hook := range h.afterQueryWithError {
+		if err := hook(ctx, event); err != nil {
 			return err
 		}
 	}
@@ -63,3 +130,70 @@ func (h *Hooks) ExecuteAfterHooks(ctx context.Context, query string, args []inte
 
 // DefaultHooks is a global hooks instance
 var DefaultHooks = NewHooks()
+
+// ScanHook is called for every row of type T scanned by Execute or Iterate, before the
+// results are returned. It can mutate the row, e.g. to decrypt fields or
+// compute derived values.
+type ScanHook[T any] func(ctx context.Context, row *T) error
+
+var scanHooks = struct {
+	mu    sync.RWMutex
+	hooks map[reflect.Type][]interface{}
+}{hooks: make(map[reflect.Type][]interface{})}
+
+// AfterScan registers a hook that is called for every scanned row of type T
+func AfterScan[T any](hook ScanHook[T]) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	scanHooks.mu.Lock()
+	defer scanHooks.mu.Unlock()
+
+	hooks := make([]interface{}, len(scanHooks.hooks[typ]), len(scanHooks.hooks[typ])+1)
+	copy(hooks, scanHooks.hooks[typ])
+	scanHooks.hooks[typ] = append(hooks, hook)
+}
+
+// applyScanHooks runs the scan hooks registered for T on every row
+func applyScanHooks[T any](ctx context.Context, rows []T) error {
+	hooks := scanHooksFor[T]()
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	for i := range rows {
+		if err := runScanHooks(ctx, hooks, &rows[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanHooksFor returns the scan hooks registered for T
+func scanHooksFor[T any]() []ScanHook[T] {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	scanHooks.mu.RLock()
+	registered := scanHooks.hooks[typ]
+	scanHooks.mu.RUnlock()
+
+	if len(registered) == 0 {
+		return nil
+	}
+
+	hooks := make([]ScanHook[T], 0, len(registered))
+	for _, h := range registered {
+		if hook, ok := h.(ScanHook[T]); ok {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks
+}
+
+func runScanHooks[T any](ctx context.Context, hooks []ScanHook[T], row *T) error {
+	for _, hook := range hooks {
+		if err := hook(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
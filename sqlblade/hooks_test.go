@@ -0,0 +1,84 @@
+package sqlblade_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+)
+
+// eventRecorder collects the events passed to after hooks
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []sqlblade.HookEvent
+}
+
+func (r *eventRecorder) hooks() *sqlblade.Hooks {
+	hooks := sqlblade.NewHooks()
+	hooks.AfterQueryWithError(func(_ context.Context, event *sqlblade.HookEvent) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = append(r.events, *event)
+		return nil
+	})
+	return hooks
+}
+
+func (r *eventRecorder) recorded() []sqlblade.HookEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]sqlblade.HookEvent(nil), r.events...)
+}
+
+func TestDeleteFiresHooks(t *testing.T) {
+	ctx := context.Background()
+	db, _ := openRecorder(t)
+	var rec eventRecorder
+	client := sqlblade.Open(db, sqlblade.WithHooks(rec.hooks()))
+
+	if _, err := sqlblade.Delete[txUser](client).Where("id", "=", 1).Tag("feature:cleanup").Execute(ctx); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	events := rec.recorded()
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Operation != "DELETE" || event.Table != "users" || event.RowsAffected != 1 {
+		t.Errorf("event = %s %s affecting %d rows, want DELETE users affecting 1 row", event.Operation, event.Table, event.RowsAffected)
+	}
+	if len(event.Tags) != 1 || event.Tags[0] != "feature:cleanup" {
+		t.Errorf("event tags = %q, want [feature:cleanup]", event.Tags)
+	}
+}
+
+func TestStatementsFireHooks(t *testing.T) {
+	ctx := context.Background()
+	db, _ := openRecorder(t)
+	var rec eventRecorder
+	client := sqlblade.Open(db, sqlblade.WithHooks(rec.hooks()))
+
+	// The recorder returns no rows, so Count and Exists fail after running
+	_, _ = sqlblade.Query[txUser](client).Count(ctx)
+	_, _ = sqlblade.Query[txUser](client).Exists(ctx)
+	_, _ = sqlblade.Raw[txUser](client, "SELECT id, name FROM users").Execute(ctx)
+	_, _ = sqlblade.Raw[txUser](client, "UPDATE users SET name = ?", "x").Exec(ctx)
+	_ = sqlblade.Raw[txUser](client, "SELECT id FROM users").ScanInto(ctx, &[]int{})
+	_ = sqlblade.Raw[txUser](client, "SELECT id FROM users").ExecuteBatches(ctx, 10, func(*sqlblade.ColumnBatch) error { return nil })
+
+	want := []string{"SELECT", "SELECT", "RAW", "RAW", "RAW", "RAW"}
+	events := rec.recorded()
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d", len(want), len(events))
+	}
+	for i, event := range events {
+		if event.Operation != want[i] {
+			t.Errorf("event %d operation = %s, want %s", i, event.Operation, want[i])
+		}
+	}
+	if events[3].RowsAffected != 1 {
+		t.Errorf("Exec event RowsAffected = %d, want 1", events[3].RowsAffected)
+	}
+}
@@ -0,0 +1,78 @@
+package sqlblade
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// WithIdentifierLengthCheck makes statements fail with ErrIdentifierTooLong
+// when a quoted table, column or alias name is longer than limit, before the
+// database truncates it: PostgreSQL silently cuts names to 63 bytes, so two
+// long aliases may collide and break a join. A limit of 0 uses the dialect's
+// (63 bytes for PostgreSQL, 64 characters for MySQL); set a lower one to keep
+// the schema portable, e.g. 30 for databases such as older Oracle versions.
+func WithIdentifierLengthCheck(limit int) ClientOption {
+	return func(c *Client) {
+		c.maxIdentLength = limit
+		if limit <= 0 {
+			// the dialect's limit
+			c.maxIdentLength = -1
+		}
+	}
+}
+
+// checkIdentifiers returns ErrIdentifierTooLong when a quoted identifier of
+// sqlStr is longer than the client's limit. String literals are skipped.
+func (c *Client) checkIdentifiers(sqlStr string) error {
+	if c.maxIdentLength == 0 || c.dialect == nil {
+		return nil
+	}
+	limit := c.maxIdentLength
+	if limit < 0 {
+		limit = dialect.CapabilitiesOf(c.dialect).MaxIdentifierLength
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	quote := c.dialect.QuoteIdentifier("x")[0]
+	length, unit := func(name string) int { return len(name) }, "bytes"
+	if c.dialect.Name() == dialectMySQL {
+		length, unit = utf8.RuneCountInString, "characters"
+	}
+	for i := 0; i < len(sqlStr); i++ {
+		if sqlStr[i] != quote && sqlStr[i] != '\'' {
+			continue
+		}
+		end := closingQuote(sqlStr, i)
+		if sqlStr[i] == quote {
+			name := strings.ReplaceAll(sqlStr[i+1:end], string([]byte{quote, quote}), string(quote))
+			if n := length(name); n > limit {
+				return fmt.Errorf("%w: %s has %d %s, %s allows %d", ErrIdentifierTooLong, name, n, unit, c.dialect.Name(), limit)
+			}
+		}
+		i = end
+	}
+	return nil
+}
+
+// closingQuote returns the index of the quote closing the quoted text
+// starting at start, with doubled quotes escaping it, or len(s) when it is
+// unterminated
+func closingQuote(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		if s[i] != quote {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == quote {
+			i++
+			continue
+		}
+		return i
+	}
+	return len(s)
+}
@@ -0,0 +1,487 @@
+// Package importer bulk loads CSV or NDJSON data into SQLBlade models.
+//
+// Records are streamed from an io.Reader, mapped to the model's columns by
+// their db tags and inserted in chunks with InsertBatch, so large files never
+// have to be held in memory:
+//
+//	result, err := importer.Import[User](ctx, db, file, importer.Options{
+//	    Format:    importer.CSV,
+//	    ChunkSize: 1000,
+//	    Transformers: map[string]importer.Transformer{
+//	        "email": func(v string) (interface{}, error) { return strings.ToLower(v), nil },
+//	    },
+//	    OnProgress: func(p importer.Progress) { log.Printf("%d rows imported", p.Inserted) },
+//	})
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+)
+
+// Format is the format of the imported data
+type Format int
+
+const (
+	// CSV reads comma-separated values with a header row naming the columns
+	CSV Format = iota
+	// NDJSON reads one JSON object per line, keyed by column name
+	NDJSON
+)
+
+const defaultChunkSize = 500
+
+var (
+	// ErrUnknownColumn is returned when the data contains a column the model does not have
+	ErrUnknownColumn = errors.New("sqlblade/importer: unknown column")
+
+	// ErrTooManyErrors is returned when more than Options.MaxErrors rows failed
+	ErrTooManyErrors = errors.New("sqlblade/importer: too many row errors")
+)
+
+// Transformer converts the raw value of a column before it is stored in the
+// model. For NDJSON, JSON strings are passed unquoted and other values as
+// their JSON text. The returned value must be assignable or convertible to
+// the field's type.
+type Transformer func(value string) (interface{}, error)
+
+// Options configures an import
+type Options struct {
+	// Format of the input, CSV by default
+	Format Format
+	// ChunkSize is the number of rows per INSERT (default 500)
+	ChunkSize int
+	// Comma is the CSV field delimiter (default ',')
+	Comma rune
+	// Transformers convert raw values, keyed by column name
+	Transformers map[string]Transformer
+	// IgnoreUnknownColumns skips input columns the model does not have
+	// instead of failing the import
+	IgnoreUnknownColumns bool
+	// MaxErrors stops the import once more rows than this failed; 0 means no limit
+	MaxErrors int
+	// OnProgress is called after every chunk
+	OnProgress func(Progress)
+}
+
+// Progress reports the state of a running import
+type Progress struct {
+	Rows     int // records read
+	Inserted int // rows inserted
+	Failed   int // rows that failed to parse or insert
+}
+
+// RowError describes a record that could not be imported
+type RowError struct {
+	Line   int    // line of the record in the input, starting at 1
+	Record string // raw record, for reprocessing
+	Err    error
+}
+
+// Error implements the error interface
+func (e RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// Result holds the outcome of an import
+type Result struct {
+	Rows     int
+	Inserted int
+	Errors   []RowError
+}
+
+// Import reads records from r and inserts them into the table of T in chunks.
+// Records that fail to parse are collected in Result.Errors. When a chunk fails
+// to insert, its rows are retried one at a time so that only the offending
+// rows are reported.
+func Import[T any, C sqlblade.Conn](ctx context.Context, db C, r io.Reader, opts Options) (*Result, error) {
+	if ctx == nil {
+		return nil, sqlblade.ErrNilContext
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = defaultChunkSize
+	}
+
+	meta, err := sqlblade.Metadata[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	var src source
+	switch opts.Format {
+	case NDJSON:
+		src = newNDJSONSource(r)
+	default:
+		src, err = newCSVSource(r, opts.Comma)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m := &mapper{meta: meta, opts: opts}
+	imp := &run[T, C]{db: db, opts: opts, result: &Result{}}
+
+	for {
+		rec, err := src.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return imp.result, err
+		}
+		imp.result.Rows++
+
+		var value T
+		if err := m.fill(reflect.ValueOf(&value).Elem(), rec); err != nil {
+			if errors.Is(err, ErrUnknownColumn) {
+				return imp.result, err
+			}
+			if err := imp.fail(RowError{Line: rec.line, Record: rec.raw, Err: err}); err != nil {
+				return imp.result, err
+			}
+			continue
+		}
+
+		imp.chunk = append(imp.chunk, value)
+		imp.lines = append(imp.lines, rec)
+		if len(imp.chunk) >= opts.ChunkSize {
+			if err := imp.flush(ctx); err != nil {
+				return imp.result, err
+			}
+		}
+	}
+
+	if err := imp.flush(ctx); err != nil {
+		return imp.result, err
+	}
+	return imp.result, nil
+}
+
+// run holds the state of an import
+type run[T any, C sqlblade.Conn] struct {
+	db     C
+	opts   Options
+	result *Result
+	chunk  []T
+	lines  []record
+}
+
+// flush inserts the pending chunk
+func (r *run[T, C]) flush(ctx context.Context) error {
+	if len(r.chunk) == 0 {
+		return nil
+	}
+	defer func() {
+		r.chunk = r.chunk[:0]
+		r.lines = r.lines[:0]
+	}()
+
+	if _, err := sqlblade.InsertBatch(r.db, r.chunk).Execute(ctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Retry row by row to find the rows that cannot be inserted
+		for i := range r.chunk {
+			if _, err := sqlblade.Insert(r.db, r.chunk[i]).Execute(ctx); err != nil {
+				if err := r.fail(RowError{Line: r.lines[i].line, Record: r.lines[i].raw, Err: err}); err != nil {
+					return err
+				}
+				continue
+			}
+			r.result.Inserted++
+		}
+	} else {
+		r.result.Inserted += len(r.chunk)
+	}
+
+	if r.opts.OnProgress != nil {
+		r.opts.OnProgress(Progress{
+			Rows:     r.result.Rows,
+			Inserted: r.result.Inserted,
+			Failed:   len(r.result.Errors),
+		})
+	}
+	return nil
+}
+
+// fail records a row error, stopping the import once MaxErrors is exceeded
+func (r *run[T, C]) fail(rowErr RowError) error {
+	r.result.Errors = append(r.result.Errors, rowErr)
+	if r.opts.MaxErrors > 0 && len(r.result.Errors) > r.opts.MaxErrors {
+		return ErrTooManyErrors
+	}
+	return nil
+}
+
+// record is a parsed input record: column names mapped to raw values
+type record struct {
+	line   int
+	raw    string
+	values map[string]string
+	json   map[string]json.RawMessage
+	err    error // the record could not be parsed
+}
+
+type source interface {
+	next() (record, error)
+}
+
+type csvSource struct {
+	reader *csv.Reader
+	header []string
+	line   int
+}
+
+func newCSVSource(r io.Reader, comma rune) (*csvSource, error) {
+	reader := csv.NewReader(r)
+	if comma != 0 {
+		reader.Comma = comma
+	}
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("sqlblade/importer: missing CSV header")
+		}
+		return nil, err
+	}
+
+	return &csvSource{reader: reader, header: append([]string(nil), header...), line: 1}, nil
+}
+
+func (s *csvSource) next() (record, error) {
+	fields, err := s.reader.Read()
+	if err != nil {
+		return record{}, err
+	}
+	s.line, _ = s.reader.FieldPos(0)
+
+	values := make(map[string]string, len(s.header))
+	for i, name := range s.header {
+		if i < len(fields) {
+			values[name] = fields[i]
+		}
+	}
+
+	var raw bytes.Buffer
+	w := csv.NewWriter(&raw)
+	_ = w.Write(fields)
+	w.Flush()
+
+	return record{line: s.line, raw: string(bytes.TrimRight(raw.Bytes(), "\n")), values: values}, nil
+}
+
+type ndjsonSource struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONSource(r io.Reader) *ndjsonSource {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return &ndjsonSource{scanner: scanner}
+}
+
+func (s *ndjsonSource) next() (record, error) {
+	for s.scanner.Scan() {
+		s.line++
+		line := bytes.TrimSpace(s.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		rec := record{line: s.line, raw: string(line)}
+		// Malformed lines are reported as row errors instead of aborting
+		rec.err = json.Unmarshal(line, &rec.json)
+		return rec, nil
+	}
+	if err := s.scanner.Err(); err != nil {
+		return record{}, err
+	}
+	return record{}, io.EOF
+}
+
+// mapper fills models from records
+type mapper struct {
+	meta *sqlblade.ModelMeta
+	opts Options
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+func (m *mapper) fill(dst reflect.Value, rec record) error {
+	if rec.err != nil {
+		return rec.err
+	}
+
+	if rec.json != nil {
+		for name, raw := range rec.json {
+			if err := m.set(dst, name, func(field reflect.Value, transform Transformer) error {
+				if transform != nil {
+					return m.transform(field, transform, jsonText(raw))
+				}
+				return json.Unmarshal(raw, field.Addr().Interface())
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for name, value := range rec.values {
+		value := value
+		if err := m.set(dst, name, func(field reflect.Value, transform Transformer) error {
+			if transform != nil {
+				return m.transform(field, transform, value)
+			}
+			return parseString(field, value)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// set resolves the field of a column and calls assign for it
+func (m *mapper) set(dst reflect.Value, name string, assign func(field reflect.Value, transform Transformer) error) error {
+	col, ok := m.meta.Column(name)
+	if !ok {
+		if m.opts.IgnoreUnknownColumns {
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrUnknownColumn, name)
+	}
+
+	field := dst.FieldByName(col.Field)
+	if !field.IsValid() || !field.CanSet() {
+		return nil
+	}
+
+	if err := assign(field, m.opts.Transformers[name]); err != nil {
+		return fmt.Errorf("column %s: %w", name, err)
+	}
+	return nil
+}
+
+func (m *mapper) transform(field reflect.Value, transform Transformer, value string) error {
+	converted, err := transform(value)
+	if err != nil {
+		return err
+	}
+	if converted == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	v := reflect.ValueOf(converted)
+	switch {
+	case v.Type().AssignableTo(field.Type()):
+		field.Set(v)
+	case v.Type().ConvertibleTo(field.Type()):
+		field.Set(v.Convert(field.Type()))
+	default:
+		return fmt.Errorf("cannot use %T as %s", converted, field.Type())
+	}
+	return nil
+}
+
+// jsonText returns the unquoted content of a JSON string, or the raw JSON text
+func jsonText(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// parseString converts a CSV value to the field's type. Empty values leave
+// pointer and scanner fields NULL.
+func parseString(field reflect.Value, value string) error {
+	if reflect.PointerTo(field.Type()).Implements(scannerType) {
+		scanner, _ := field.Addr().Interface().(sql.Scanner)
+		if value == "" {
+			return scanner.Scan(nil)
+		}
+		return scanner.Scan(value)
+	}
+
+	if field.Kind() == reflect.Ptr {
+		if value == "" {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		ptr := reflect.New(field.Type().Elem())
+		if err := parseString(ptr.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	if field.Type() == timeType {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+		field.SetBytes([]byte(value))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package sqlblade
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// InListMode controls how IN / NOT IN value lists are expanded into placeholders
+type InListMode int32
+
+const (
+	// InListExact emits exactly one placeholder per value
+	InListExact InListMode = iota
+	// InListBucketed pads lists to the next power of two by repeating the last
+	// value, so lists of similar length share the same SQL string and prepared
+	// statement. Lists longer than maxInListBucket are padded to a multiple of
+	// it, and lists are left as they are when padding them would exceed the
+	// dialect's bind parameter limit.
+	InListBucketed
+)
+
+const maxInListBucket = 1024
+
+var inListMode atomic.Int32
+
+// SetInListMode sets how IN lists are expanded for all builders
+func SetInListMode(mode InListMode) {
+	inListMode.Store(int32(mode))
+}
+
+// normalizeInList pads the values of an IN list according to the current
+// mode, unless the padded list has more values than d accepts bind parameters
+func normalizeInList(d dialect.Dialect, values []interface{}) []interface{} {
+	if InListMode(inListMode.Load()) != InListBucketed || len(values) == 0 {
+		return values
+	}
+
+	size := inListBucketSize(len(values))
+	if limit := maxBindParams(d); size == len(values) || limit > 0 && size > limit {
+		return values
+	}
+
+	padded := make([]interface{}, size)
+	copy(padded, values)
+	last := values[len(values)-1]
+	for i := len(values); i < size; i++ {
+		padded[i] = last
+	}
+	return padded
+}
+
+// inListBucketSize returns the bucketed size for a list of n values
+func inListBucketSize(n int) int {
+	if n > maxInListBucket {
+		return (n + maxInListBucket - 1) / maxInListBucket * maxInListBucket
+	}
+	size := 1
+	for size < n {
+		size <<= 1
+	}
+	return size
+}
+
+// splitQueries counts the queries executed in parts by SplitLargeInLists
+var splitQueries atomic.Uint64
+
+// SplitQueries returns the number of queries that were executed in several
+// parts because of SplitLargeInLists, a sign of IN lists worth replacing by a
+// join or a temporary table
+func SplitQueries() uint64 {
+	return splitQueries.Load()
+}
+
+// SplitLargeInLists lets Execute split the query into several executions
+// when an IN list takes it beyond the dialect's bind parameter limit, instead
+// of failing with ErrTooManyParams, and merge their rows. Duplicate values are
+// dropped from the list so that no row is returned twice. Splitting is
+// counted by SplitQueries and logged, and only applies to queries whose
+// conditions are all joined with AND and that have no ORDER BY, LIMIT,
+// OFFSET, DISTINCT, GROUP BY or set operations, whose results would change.
+func (qb *QueryBuilder[T]) SplitLargeInLists() *QueryBuilder[T] {
+	qb.splitInLists = true
+	return qb
+}
+
+// executeSplit executes the query in parts of the largest IN list when it
+// has too many bind parameters and can be split. It reports whether it did.
+func (qb *QueryBuilder[T]) executeSplit(ctx context.Context, params int) ([]T, bool, error) {
+	limit := maxBindParams(qb.client.dialect)
+	if !qb.splitInLists || limit == 0 || params <= limit || !qb.splittable() {
+		return nil, false, nil
+	}
+
+	split, size := -1, 0
+	for i, clause := range qb.whereClauses {
+		if !strings.EqualFold(strings.TrimSpace(clause.Operator), "IN") {
+			continue
+		}
+		if values, ok := inValues(clause.Value); ok && len(values) > size {
+			split, size = i, len(values)
+		}
+	}
+	if split < 0 {
+		return nil, false, nil
+	}
+
+	values, _ := inValues(qb.whereClauses[split].Value)
+	chunk := inListChunkSize(limit - (params - len(normalizeInList(qb.client.dialect, values))))
+	values = uniqueValues(values)
+	if chunk <= 0 {
+		return nil, false, nil
+	}
+
+	splitQueries.Add(1)
+	log.Printf("sqlblade: IN list of %d values on %s.%s split into queries of %d values", len(values), qb.tableName, qb.whereClauses[split].Column, chunk)
+
+	var result []T
+	for start := 0; start < len(values); start += chunk {
+		part := *qb
+		part.splitInLists = false
+		part.whereClauses = append([]WhereClause(nil), qb.whereClauses...)
+		part.whereClauses[split].Value = values[start:min(start+chunk, len(values))]
+		rows, err := part.Execute(ctx)
+		if err != nil {
+			return nil, true, err
+		}
+		result = append(result, rows...)
+	}
+	return result, true, nil
+}
+
+// splittable reports whether splitting an IN list of the query into several
+// executions returns the same rows
+func (qb *QueryBuilder[T]) splittable() bool {
+	for i, clause := range qb.whereClauses {
+		if i > 0 && !clause.And {
+			return false
+		}
+	}
+	return len(qb.orderBy) == 0 && qb.limit == nil && qb.offset == nil && !qb.distinct &&
+		len(qb.groupBy) == 0 && len(qb.having) == 0 && len(qb.setOps) == 0
+}
+
+// uniqueValues returns the values without duplicates, in order
+func uniqueValues(values []interface{}) []interface{} {
+	seen := make(map[string]bool, len(values))
+	unique := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		key := formatKey([]interface{}{v})
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// inListChunkSize returns the largest number of values of an IN list whose
+// placeholders fit in budget, taking bucketing into account
+func inListChunkSize(budget int) int {
+	if InListMode(inListMode.Load()) != InListBucketed || budget <= 0 {
+		return budget
+	}
+	if budget >= maxInListBucket {
+		return budget / maxInListBucket * maxInListBucket
+	}
+	size := 1
+	for size*2 <= budget {
+		size *= 2
+	}
+	return size
+}
@@ -0,0 +1,45 @@
+package sqlblade_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+func TestBucketedInListStaysWithinBindLimit(t *testing.T) {
+	sqlblade.SetInListMode(sqlblade.InListBucketed)
+	t.Cleanup(func() { sqlblade.SetInListMode(sqlblade.InListExact) })
+
+	tests := []struct {
+		values int
+		args   int
+	}{
+		{3, 4},
+		{1500, 2048},
+		// Padding to 65536 would exceed PostgreSQL's 65535 parameters
+		{64600, 64600},
+	}
+	for _, tt := range tests {
+		db, _ := openRecorder(t)
+		client := sqlblade.Open(db, sqlblade.WithDialect(dialect.NewPostgreSQL()))
+		ctx, capture := sqlblade.WithCapture(context.Background())
+
+		ids := make([]interface{}, tt.values)
+		for i := range ids {
+			ids[i] = i
+		}
+		if _, err := sqlblade.Query[txUser](client).Where("id", "IN", ids).Execute(ctx); err != nil {
+			t.Fatalf("%d values: Execute: %v", tt.values, err)
+		}
+
+		statements := capture.Statements()
+		if len(statements) != 1 {
+			t.Fatalf("%d values: expected 1 statement, got %d", tt.values, len(statements))
+		}
+		if got := len(statements[0].Args); got != tt.args {
+			t.Errorf("%d values: %d args, want %d", tt.values, got, tt.args)
+		}
+	}
+}
@@ -3,10 +3,9 @@ package sqlblade
 import (
 	"context"
 	"database/sql"
-	"log"
+	"fmt"
 	"reflect"
 	"strings"
-	"time"
 
 	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
 )
@@ -15,20 +14,19 @@ import (
 type InsertBuilder[T any] struct {
 	db        *sql.DB
 	tx        *sql.Tx
+	client    *Client
 	dialect   dialect.Dialect
 	tableName string
 	values    []T
 	columns   []string
 	returning []string
+	conflict  *onConflict
+	tags      []string
 }
 
 // Insert creates a new INSERT builder
-func Insert[T any](db *sql.DB, value T) *InsertBuilder[T] {
-	if db == nil {
-		panic(ErrNilDB)
-	}
-
-	d := detectDialect(db.Driver())
+func Insert[T any, C Conn](db C, value T) *InsertBuilder[T] {
+	client := resolveConn(db)
 	typ := reflect.TypeOf(value)
 	if typ.Kind() == reflect.Ptr {
 		typ = typ.Elem()
@@ -42,8 +40,9 @@ func Insert[T any](db *sql.DB, value T) *InsertBuilder[T] {
 	}
 
 	return &InsertBuilder[T]{
-		db:        db,
-		dialect:   d,
+		db:        client.db,
+		client:    client,
+		dialect:   withColumnAliases(client.dialect, typ, info.tableName),
 		tableName: info.tableName,
 		values:    []T{value},
 		columns:   make([]string, 0),
@@ -52,12 +51,8 @@ func Insert[T any](db *sql.DB, value T) *InsertBuilder[T] {
 }
 
 // InsertTx creates a new INSERT builder with transaction
-func InsertTx[T any](tx *sql.Tx, value T) *InsertBuilder[T] {
-	if tx == nil {
-		panic(ErrNilDB)
-	}
-
-	d := detectDialect(nil)
+func InsertTx[T any, X TxConn](tx X, value T) *InsertBuilder[T] {
+	sqlTx, client := resolveTx(tx)
 	typ := reflect.TypeOf(value)
 	if typ.Kind() == reflect.Ptr {
 		typ = typ.Elem()
@@ -71,8 +66,9 @@ func InsertTx[T any](tx *sql.Tx, value T) *InsertBuilder[T] {
 	}
 
 	return &InsertBuilder[T]{
-		tx:        tx,
-		dialect:   d,
+		tx:        sqlTx,
+		client:    client,
+		dialect:   withColumnAliases(client.dialect, typ, info.tableName),
 		tableName: info.tableName,
 		values:    []T{value},
 		columns:   make([]string, 0),
@@ -81,15 +77,12 @@ func InsertTx[T any](tx *sql.Tx, value T) *InsertBuilder[T] {
 }
 
 // InsertBatch creates a new batch INSERT builder
-func InsertBatch[T any](db *sql.DB, values []T) *InsertBuilder[T] {
-	if db == nil {
-		panic(ErrNilDB)
-	}
+func InsertBatch[T any, C Conn](db C, values []T) *InsertBuilder[T] {
 	if len(values) == 0 {
 		panic(ErrEmptySet)
 	}
 
-	d := detectDialect(db.Driver())
+	client := resolveConn(db)
 	typ := reflect.TypeOf(values[0])
 	if typ.Kind() == reflect.Ptr {
 		typ = typ.Elem()
@@ -103,8 +96,9 @@ func InsertBatch[T any](db *sql.DB, values []T) *InsertBuilder[T] {
 	}
 
 	return &InsertBuilder[T]{
-		db:        db,
-		dialect:   d,
+		db:        client.db,
+		client:    client,
+		dialect:   withColumnAliases(client.dialect, typ, info.tableName),
 		tableName: info.tableName,
 		values:    values,
 		columns:   make([]string, 0),
@@ -118,78 +112,229 @@ func (ib *InsertBuilder[T]) Columns(columns ...string) *InsertBuilder[T] {
 	return ib
 }
 
-// Returning specifies columns to return (PostgreSQL)
+// Returning specifies columns to return (PostgreSQL, SQLite)
 func (ib *InsertBuilder[T]) Returning(columns ...string) *InsertBuilder[T] {
 	ib.returning = columns
 	return ib
 }
 
-// Execute executes the INSERT statement
+// Execute executes the INSERT statement. Batches exceeding the dialect's bind
+// parameter limit are split into several statements run in one transaction.
 func (ib *InsertBuilder[T]) Execute(ctx context.Context) (sql.Result, error) {
 	if ctx == nil {
 		return nil, ErrNilContext
 	}
 
-	if len(ib.values) == 0 {
-		return nil, ErrEmptySet
+	size, err := ib.chunkSize()
+	if err != nil {
+		return nil, err
 	}
-
-	typ := reflect.TypeOf(ib.values[0])
-	if typ.Kind() == reflect.Ptr {
-		typ = typ.Elem()
+	if size > 0 {
+		result := &batchResult{}
+		err := ib.inChunks(ctx, size, func(chunk *InsertBuilder[T]) error {
+			r, err := chunk.Execute(ctx)
+			if err != nil {
+				return err
+			}
+			result.results = append(result.results, r)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
 	}
 
-	info, err := getStructInfo(typ)
+	sqlStr, args, err := ib.buildSQL(ib.returning)
 	if err != nil {
 		return nil, err
 	}
 
-	columns := ib.resolveColumns(info)
-	sqlStr, args := ib.buildInsertSQL(info, columns)
-
-	startTime := time.Now()
-	if err := DefaultHooks.ExecuteBeforeHooks(ctx, sqlStr, args); err != nil {
+	stmt := &Statement{Operation: "INSERT", Table: ib.tableName, SQL: sqlStr, Args: args, Primary: true, Tags: ib.tags}
+	event := ib.client.newHookEvent(stmt)
+	startTime := event.StartTime
+	if err := ib.client.executeBeforeHooks(ctx, event); err != nil {
 		return nil, err
 	}
 
 	var result sql.Result
-	var execErr error
 
-	if globalDebugger.enabled {
+	if debugger := ib.client.queryDebugger(); debugger.enabled.Load() {
 		debugQuery := &DebugQuery{
 			SQL:       sqlStr,
 			Args:      args,
+			Tags:      stmt.Tags,
 			Table:     ib.tableName,
 			Operation: "INSERT",
 			Timestamp: startTime,
 		}
 		defer func() {
-			debugQuery.Duration = time.Since(startTime)
+			debugQuery.Duration = ib.client.since(startTime)
 			if result != nil {
 				rowsAffected, err := result.RowsAffected()
 				if err == nil {
 					debugQuery.RowsAffected = rowsAffected
 				}
 			}
-			globalDebugger.Log(debugQuery)
+			debugger.Log(debugQuery)
 		}()
 	}
 
-	if ib.tx != nil {
-		result, execErr = ib.tx.ExecContext(ctx, sqlStr, args...)
-	} else {
-		result, execErr = ib.db.ExecContext(ctx, sqlStr, args...)
+	err = ib.client.execute(ctx, stmt, func(ctx context.Context) error {
+		var execErr error
+		result, execErr = ib.client.execContext(ctx, ib.tx, true, stmt.SQL, stmt.Args)
+		if execErr != nil {
+			return wrapQueryError(execErr, stmt.SQL, stmt.Args)
+		}
+		return nil
+	})
+
+	event.finish(err, result)
+	ib.client.executeAfterHooks(ctx, event)
+	if err != nil {
+		return nil, err
 	}
 
-	if execErr != nil {
-		return nil, wrapQueryError(execErr, sqlStr, args)
+	return result, nil
+}
+
+// ExecuteReturning executes the INSERT statement and scans the inserted rows
+// returned by its RETURNING clause into T, including generated columns such as
+// ids and defaults. Without Returning, all columns are returned. Only dialects
+// supporting RETURNING (PostgreSQL, SQLite) are allowed.
+func (ib *InsertBuilder[T]) ExecuteReturning(ctx context.Context) ([]T, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+	if !supportsReturning(ib.dialect) {
+		return nil, ErrReturningUnsupported
 	}
 
-	if hookErr := DefaultHooks.ExecuteAfterHooks(ctx, sqlStr, args); hookErr != nil {
-		log.Printf("after query hook error: %v", hookErr)
+	size, err := ib.chunkSize()
+	if err != nil {
+		return nil, err
+	}
+	if size > 0 {
+		var returned []T
+		err := ib.inChunks(ctx, size, func(chunk *InsertBuilder[T]) error {
+			rows, err := chunk.ExecuteReturning(ctx)
+			returned = append(returned, rows...)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return returned, nil
 	}
 
-	return result, nil
+	sqlStr, args, err := ib.buildSQL(returningColumns(ib.returning))
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &Statement{Operation: "INSERT", Table: ib.tableName, SQL: sqlStr, Args: args, Primary: true, Tags: ib.tags}
+	return executeReturning[T](ctx, ib.client, ib.tx, stmt)
+}
+
+// ExecuteReturningAll executes the INSERT statement like ExecuteReturning and
+// writes the returned columns, e.g. generated ids, back into the inserted
+// values in order, so batch inserts need no follow-up SELECT:
+//
+//	_, err := sqlblade.InsertBatch(db, users).Returning("id").ExecuteReturningAll(ctx)
+//	// users[i].ID now holds the generated id of each row
+//
+// The slice given to InsertBatch is updated in place; Insert only updates the
+// caller's value when it is given a pointer.
+func (ib *InsertBuilder[T]) ExecuteReturningAll(ctx context.Context) ([]T, error) {
+	returned, err := ib.ExecuteReturning(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(returned) != len(ib.values) {
+		return returned, fmt.Errorf("%w: inserted %d rows, got %d back", ErrReturningMismatch, len(ib.values), len(returned))
+	}
+
+	info, err := getStructInfo(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return returned, err
+	}
+
+	fields := returnedFields(info, returningColumns(ib.returning))
+	values := reflect.ValueOf(ib.values)
+	for i := range returned {
+		dst := values.Index(i)
+		src := reflect.ValueOf(returned[i])
+		if dst.Kind() == reflect.Ptr {
+			if dst.IsNil() {
+				continue
+			}
+			dst = dst.Elem()
+			src = src.Elem()
+		}
+		for _, field := range fields {
+			if from := field.value(src); from.IsValid() {
+				if to := field.settable(dst); to.IsValid() {
+					to.Set(from)
+				}
+			}
+		}
+	}
+
+	return returned, nil
+}
+
+// returnedFields returns the fields of the RETURNING columns
+func returnedFields(info *structInfo, returning []string) []fieldInfo {
+	if len(returning) == 1 && returning[0] == "*" {
+		return info.fields
+	}
+
+	fields := make([]fieldInfo, 0, len(returning))
+	for _, col := range returning {
+		for _, field := range info.fields {
+			if field.dbColumn == col {
+				fields = append(fields, field)
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// buildSQL builds the INSERT statement with the given RETURNING columns
+func (ib *InsertBuilder[T]) buildSQL(returning []string) (string, []interface{}, error) {
+	if len(ib.values) == 0 {
+		return "", nil, ErrEmptySet
+	}
+
+	typ := reflect.TypeOf(ib.values[0])
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	info, err := getStructInfo(typ)
+	if err != nil {
+		return "", nil, err
+	}
+
+	columns := ib.resolveColumns(info)
+	val := reflect.ValueOf(ib.values[0])
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	for _, field := range info.fields {
+		if !containsFold(columns, field.dbColumn) {
+			continue
+		}
+		if fieldVal := field.value(val); fieldVal.IsValid() {
+			if err := ib.client.checkDecimal(info, field.dbColumn, fieldVal.Interface()); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	sqlStr, args := ib.buildInsertSQL(info, columns, returning)
+	return sqlStr, args, nil
 }
 
 func (ib *InsertBuilder[T]) resolveColumns(info *structInfo) []string {
@@ -210,20 +355,44 @@ func (ib *InsertBuilder[T]) resolveColumns(info *structInfo) []string {
 		valRef = valRef.Elem()
 	}
 
+	meta, err := metadataOf(valRef.Type())
+	if err != nil {
+		meta = &ModelMeta{}
+	}
+
+	// Zero-valued primary keys are left out so the database can generate them
 	columns := make([]string, 0, len(info.fields))
 	for _, field := range info.fields {
-		if strings.Contains(field.dbColumn, "id") {
-			fieldVal := valRef.Field(field.index)
+		if meta.IsPrimaryKey(field.dbColumn) {
+			fieldVal := field.value(valRef)
 			if fieldVal.IsValid() && fieldVal.IsZero() {
 				continue
 			}
 		}
+		if ib.omittedInAllRows(field) {
+			continue
+		}
 		columns = append(columns, field.dbColumn)
 	}
 	return columns
 }
 
-func (ib *InsertBuilder[T]) buildInsertSQL(info *structInfo, columns []string) (string, []interface{}) {
+// omittedInAllRows reports whether a field holds an unset Omit in every row
+func (ib *InsertBuilder[T]) omittedInAllRows(field fieldInfo) bool {
+	for _, val := range ib.values {
+		valRef := reflect.ValueOf(val)
+		if valRef.Kind() == reflect.Ptr {
+			valRef = valRef.Elem()
+		}
+		fieldVal := field.value(valRef)
+		if !fieldVal.IsValid() || !isOmitted(fieldVal.Interface()) {
+			return false
+		}
+	}
+	return true
+}
+
+func (ib *InsertBuilder[T]) buildInsertSQL(info *structInfo, columns []string, returning []string) (string, []interface{}) {
 	var buf strings.Builder
 	estimatedSize := insertBufferSize
 	if len(ib.values) > 1 {
@@ -244,27 +413,21 @@ func (ib *InsertBuilder[T]) buildInsertSQL(info *structInfo, columns []string) (
 	buf.WriteString(strings.Join(quotedCols, ", "))
 	buf.WriteString(") VALUES ")
 
-	fieldMap := make(map[string]int, len(info.fields))
-	for idx, field := range info.fields {
-		fieldMap[field.dbColumn] = idx
+	fieldMap := make(map[string]fieldInfo, len(info.fields))
+	for _, field := range info.fields {
+		fieldMap[field.dbColumn] = field
 	}
 
 	valueParts := ib.buildValueParts(columns, fieldMap, &paramIndex, &args)
 	buf.WriteString(strings.Join(valueParts, ", "))
 
-	if len(ib.returning) > 0 && ib.dialect.Name() == dialectPostgres {
-		buf.WriteString(" RETURNING ")
-		returningCols := make([]string, len(ib.returning))
-		for i, col := range ib.returning {
-			returningCols[i] = ib.dialect.QuoteIdentifier(col)
-		}
-		buf.WriteString(strings.Join(returningCols, ", "))
-	}
+	ib.writeConflict(&buf, columns)
+	writeReturning(&buf, ib.dialect, returning)
 
 	return buf.String(), args
 }
 
-func (ib *InsertBuilder[T]) buildValueParts(columns []string, fieldMap map[string]int, paramIndex *int, args *[]interface{}) []string {
+func (ib *InsertBuilder[T]) buildValueParts(columns []string, fieldMap map[string]fieldInfo, paramIndex *int, args *[]interface{}) []string {
 	valueParts := make([]string, len(ib.values))
 	for i, val := range ib.values {
 		valRef := reflect.ValueOf(val)
@@ -274,17 +437,27 @@ func (ib *InsertBuilder[T]) buildValueParts(columns []string, fieldMap map[strin
 
 		placeholders := make([]string, len(columns))
 		for j, col := range columns {
-			*paramIndex++
-			placeholders[j] = ib.dialect.Placeholder(*paramIndex)
-
 			var fieldValue interface{}
 			colLower := strings.ToLower(col)
-			if fieldIdx, ok := fieldMap[colLower]; ok {
-				fieldVal := valRef.Field(fieldIdx)
+			if field, ok := fieldMap[colLower]; ok {
+				fieldVal := field.value(valRef)
 				if fieldVal.IsValid() {
-					fieldValue = fieldVal.Interface()
+					fieldValue = fieldArg(field, fieldVal)
+				}
+			}
+
+			// Unset Omit values in batches fall back to the column default
+			// (SQLite has no DEFAULT keyword in VALUES, so NULL is bound)
+			if isOmitted(fieldValue) {
+				if ib.dialect.Name() != dialectSQLite {
+					placeholders[j] = "DEFAULT"
+					continue
 				}
+				fieldValue = nil
 			}
+
+			*paramIndex++
+			placeholders[j] = placeholder(ib.dialect, *paramIndex, fieldValue)
 			*args = append(*args, fieldValue)
 		}
 		valueParts[i] = "(" + strings.Join(placeholders, ", ") + ")"
@@ -0,0 +1,125 @@
+package sqlblade
+
+import (
+	"strings"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// JoinAs adds an INNER JOIN of table under alias, which condition and the
+// selected columns can reference
+func (qb *QueryBuilder[T]) JoinAs(table, alias, condition string) *QueryBuilder[T] {
+	qb.joins = append(qb.joins, dialect.Join{Type: dialect.InnerJoin, Table: table, Alias: alias, Condition: condition})
+	return qb
+}
+
+// LeftJoinAs adds a LEFT JOIN of table under alias
+func (qb *QueryBuilder[T]) LeftJoinAs(table, alias, condition string) *QueryBuilder[T] {
+	qb.joins = append(qb.joins, dialect.Join{Type: dialect.LeftJoin, Table: table, Alias: alias, Condition: condition})
+	return qb
+}
+
+// RightJoinAs adds a RIGHT JOIN of table under alias
+func (qb *QueryBuilder[T]) RightJoinAs(table, alias, condition string) *QueryBuilder[T] {
+	qb.joins = append(qb.joins, dialect.Join{Type: dialect.RightJoin, Table: table, Alias: alias, Condition: condition})
+	return qb
+}
+
+// JoinSubquery adds an INNER JOIN of the derived table of a subquery under
+// alias. The subquery's arguments are bound in place, its placeholders
+// numbered on from those before it:
+//
+//	totals := sqlblade.NewSubquery(sqlblade.Query[Order](db).
+//	    Select("user_id", "SUM(total) AS spent").
+//	    Where("status", "=", "paid").
+//	    GroupBy("user_id"))
+//	sqlblade.Query[User](db).
+//	    JoinSubquery(totals, "t", "t.user_id = users.id").
+//	    Where("users.active", "=", true)
+func (qb *QueryBuilder[T]) JoinSubquery(sub *Subquery, alias, condition string) *QueryBuilder[T] {
+	return qb.joinSubquery(dialect.InnerJoin, sub, alias, condition)
+}
+
+// LeftJoinSubquery adds a LEFT JOIN of the derived table of a subquery under
+// alias; see JoinSubquery
+func (qb *QueryBuilder[T]) LeftJoinSubquery(sub *Subquery, alias, condition string) *QueryBuilder[T] {
+	return qb.joinSubquery(dialect.LeftJoin, sub, alias, condition)
+}
+
+// RightJoinSubquery adds a RIGHT JOIN of the derived table of a subquery
+// under alias; see JoinSubquery
+func (qb *QueryBuilder[T]) RightJoinSubquery(sub *Subquery, alias, condition string) *QueryBuilder[T] {
+	return qb.joinSubquery(dialect.RightJoin, sub, alias, condition)
+}
+
+// joinSubquery adds a join of a derived table, recorded by its position
+func (qb *QueryBuilder[T]) joinSubquery(joinType dialect.JoinType, sub *Subquery, alias, condition string) *QueryBuilder[T] {
+	if qb.joinSubqueries == nil {
+		qb.joinSubqueries = make(map[int]*Subquery)
+	}
+	qb.joinSubqueries[len(qb.joins)] = sub
+	qb.joins = append(qb.joins, dialect.Join{Type: joinType, Alias: alias, Condition: condition})
+	return qb
+}
+
+// writeJoins writes the JOIN clauses, binding the arguments of derived tables
+func (qb *QueryBuilder[T]) writeJoins(buf *strings.Builder, paramIndex *int, args *[]interface{}) {
+	for i, join := range qb.joins {
+		buf.WriteString(" ")
+		sub := qb.joinSubqueries[i]
+		if sub == nil {
+			buf.WriteString(qb.dialect.BuildJoin(join))
+			continue
+		}
+		buf.WriteString(join.Type.String())
+		buf.WriteString(" ")
+		sub.writeTo(buf, paramIndex, args)
+		buf.WriteString(" AS ")
+		buf.WriteString(qb.dialect.QuoteIdentifier(join.Alias))
+		buf.WriteString(" ON ")
+		buf.WriteString(join.Condition)
+	}
+}
+
+// Join updates the rows matching the rows of another table by condition,
+// which the SET values and WHERE conditions can reference, e.g.
+//
+//	sqlblade.Update[Order](db).
+//	    Join("users", "users.id = orders.user_id").
+//	    SetExpr("region", "users.region").
+//	    Where("users.country", "=", "DE")
+//
+// MySQL renders it as UPDATE orders INNER JOIN users ON ..., PostgreSQL and
+// SQLite (3.33+) as UPDATE orders SET ... FROM users WHERE ....
+func (ub *UpdateBuilder[T]) Join(table string, condition string) *UpdateBuilder[T] {
+	return ub.JoinAs(table, "", condition)
+}
+
+// JoinAs updates the rows matching the rows of table under alias, see Join
+func (ub *UpdateBuilder[T]) JoinAs(table, alias, condition string) *UpdateBuilder[T] {
+	ub.joins = append(ub.joins, dialect.Join{Type: dialect.InnerJoin, Table: table, Alias: alias, Condition: condition})
+	return ub
+}
+
+// fromJoins writes the FROM list of the joined tables and returns the WHERE
+// conditions matching them, followed by the update's own conditions
+func (ub *UpdateBuilder[T]) fromJoins(buf *strings.Builder) []WhereClause {
+	clauses := make([]WhereClause, 0, len(ub.joins)+1)
+	buf.WriteString(" FROM ")
+	for i, join := range ub.joins {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(ub.dialect.QuoteIdentifier(join.Table))
+		if join.Alias != "" {
+			buf.WriteString(" AS ")
+			buf.WriteString(ub.dialect.QuoteIdentifier(join.Alias))
+		}
+		clauses = append(clauses, WhereClause{Value: rawExpr{sql: join.Condition}, And: true})
+	}
+	if len(ub.whereClauses) > 0 {
+		// Grouped, so that OR conditions don't escape the join conditions
+		clauses = append(clauses, WhereClause{Value: &ConditionGroup{clauses: ub.whereClauses}, And: true})
+	}
+	return clauses
+}
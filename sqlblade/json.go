@@ -0,0 +1,123 @@
+package sqlblade
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// JSONValue is a value bound as a JSON document. Fields tagged with the json
+// option, e.g. `db:"payload,json"`, are bound as JSONValue on INSERT and
+// UPDATE; wrap values given to Set or Where with JSON.
+type JSONValue struct {
+	V interface{}
+}
+
+// JSON wraps v to be bound as a JSON document
+func JSON(v interface{}) JSONValue {
+	return JSONValue{V: v}
+}
+
+// Value implements driver.Valuer. Nil values, including nil pointers, maps and
+// slices, are bound as NULL.
+func (j JSONValue) Value() (driver.Value, error) {
+	if isNilValue(j.V) {
+		return nil, nil
+	}
+	data, err := json.Marshal(j.V)
+	if err != nil {
+		return nil, fmt.Errorf("sqlblade: marshaling JSON value: %w", err)
+	}
+	return string(data), nil
+}
+
+// isNilValue reports whether v is nil or a nil pointer, map, slice or interface
+func isNilValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
+// placeholder returns the placeholder of the argument at index, cast to
+// jsonb on PostgreSQL for JSON values
+func placeholder(d dialect.Dialect, index int, value interface{}) string {
+	if _, ok := value.(JSONValue); ok && d.Name() == dialectPostgres {
+		return d.Placeholder(index) + "::jsonb"
+	}
+	return d.Placeholder(index)
+}
+
+// fieldArg returns the value of a field to bind, wrapping JSON fields and
+// fields with a codec
+func fieldArg(field fieldInfo, value reflect.Value) interface{} {
+	v := value.Interface()
+	if field.codec != nil && !isOmitted(v) {
+		return codecValue{codec: field.codec, v: v}
+	}
+	if field.isJSON && !isOmitted(v) {
+		return JSON(v)
+	}
+	return v
+}
+
+// jsonDest is a scan destination unmarshaling a JSON column
+type jsonDest struct {
+	v reflect.Value
+}
+
+// JSONDest returns a scan destination unmarshaling a JSON column into the
+// value ptr points to, as used by code generated for json fields
+func JSONDest(ptr interface{}) sql.Scanner {
+	return jsonDest{v: reflect.ValueOf(ptr).Elem()}
+}
+
+// Scan implements sql.Scanner
+func (d jsonDest) Scan(src interface{}) error {
+	return scanJSON(d.v, src)
+}
+
+// scanJSON unmarshals a JSON column value into a field; NULL resets the field
+func scanJSON(field reflect.Value, src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("sqlblade: cannot unmarshal %T as JSON", src)
+	}
+
+	target := reflect.New(field.Type())
+	if err := json.Unmarshal(data, target.Interface()); err != nil {
+		return err
+	}
+	field.Set(target.Elem())
+	return nil
+}
+
+// jsonColumnType returns the column type of JSON fields for the dialect
+func jsonColumnType(d dialect.Dialect) string {
+	switch d.Name() {
+	case dialectPostgres:
+		return "JSONB"
+	case dialectMySQL:
+		return "JSON"
+	default:
+		return "TEXT"
+	}
+}
@@ -0,0 +1,33 @@
+package sqlblade
+
+import (
+	"sync/atomic"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// bindLimitOffset controls whether LIMIT/OFFSET values are bound as parameters
+var bindLimitOffset atomic.Bool
+
+func init() {
+	bindLimitOffset.Store(true)
+}
+
+// SetLimitOffsetBinding enables or disables binding LIMIT and OFFSET values as
+// query parameters. Binding is enabled by default so that queries which only
+// differ in page size share the same SQL and prepared statement; disable it to
+// format the values into the SQL string instead.
+func SetLimitOffsetBinding(enabled bool) {
+	bindLimitOffset.Store(enabled)
+}
+
+// buildLimitOffset builds LIMIT and OFFSET clauses, binding the values when
+// enabled and supported by the dialect
+func buildLimitOffset(d dialect.Dialect, limit, offset *int, paramIndex *int) (string, []interface{}) {
+	if bindLimitOffset.Load() {
+		if binder, ok := d.(dialect.LimitOffsetBinder); ok {
+			return binder.BuildLimitOffsetParams(limit, offset, paramIndex)
+		}
+	}
+	return d.BuildLimitOffset(limit, offset), nil
+}
@@ -0,0 +1,175 @@
+package sqlblade
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// LimiterOptions configures a ConcurrencyLimiter
+type LimiterOptions struct {
+	// MaxInFlight caps the number of statements running at once (default 1)
+	MaxInFlight int
+	// MaxLowPriority caps the low-priority statements running at once
+	// (default half of MaxInFlight)
+	MaxLowPriority int
+	// ReservedHigh is the number of slots only high-priority statements may use
+	ReservedHigh int
+}
+
+// limiterWaiter is a statement waiting for a slot
+type limiterWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// ConcurrencyLimiter caps the number of statements running at once, giving
+// slots to high-priority statements first. Normal and high-priority
+// statements wait for a slot; low-priority statements are shed with
+// ErrOverloaded when no slot is free, so batch jobs can't starve interactive
+// traffic. It is a Plugin:
+//
+//	client.Use(sqlblade.NewConcurrencyLimiter(sqlblade.LimiterOptions{MaxInFlight: 20}))
+type ConcurrencyLimiter struct {
+	mu          sync.Mutex
+	opts        LimiterOptions
+	inFlight    int
+	lowInFlight int
+	high        []*limiterWaiter
+	normal      []*limiterWaiter
+}
+
+// NewConcurrencyLimiter creates a concurrency limiter
+func NewConcurrencyLimiter(opts LimiterOptions) *ConcurrencyLimiter {
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = 1
+	}
+	if opts.ReservedHigh >= opts.MaxInFlight {
+		opts.ReservedHigh = opts.MaxInFlight - 1
+	}
+	if opts.MaxLowPriority <= 0 {
+		opts.MaxLowPriority = opts.MaxInFlight / 2
+		if opts.MaxLowPriority == 0 {
+			opts.MaxLowPriority = 1
+		}
+	}
+	return &ConcurrencyLimiter{opts: opts}
+}
+
+// InFlight returns the number of statements currently running
+func (l *ConcurrencyLimiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+// acquire takes a slot for a statement of priority p, waiting unless it is low priority
+func (l *ConcurrencyLimiter) acquire(ctx context.Context, p Priority) error {
+	l.mu.Lock()
+
+	shared := l.opts.MaxInFlight - l.opts.ReservedHigh
+	var queue *[]*limiterWaiter
+	switch {
+	case p < PriorityNormal:
+		waiting := len(l.high) + len(l.normal)
+		if l.inFlight >= shared || l.lowInFlight >= l.opts.MaxLowPriority || waiting > 0 {
+			inFlight := l.inFlight
+			l.mu.Unlock()
+			return fmt.Errorf("%w: %d statements in flight", ErrOverloaded, inFlight)
+		}
+		l.inFlight++
+		l.lowInFlight++
+		l.mu.Unlock()
+		return nil
+	case p > PriorityNormal:
+		if l.inFlight < l.opts.MaxInFlight && len(l.high) == 0 {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		queue = &l.high
+	default:
+		if l.inFlight < shared && len(l.high)+len(l.normal) == 0 {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		queue = &l.normal
+	}
+
+	w := &limiterWaiter{ready: make(chan struct{})}
+	*queue = append(*queue, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	if w.granted {
+		// The slot was handed over while the context was cancelled
+		l.mu.Unlock()
+		l.release(p)
+		return ctx.Err()
+	}
+	for i, queued := range *queue {
+		if queued == w {
+			*queue = append((*queue)[:i], (*queue)[i+1:]...)
+			break
+		}
+	}
+	l.mu.Unlock()
+	return ctx.Err()
+}
+
+// release frees the slot of a statement of priority p and hands it to the
+// next waiting statement, high priority first
+func (l *ConcurrencyLimiter) release(p Priority) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	if p < PriorityNormal {
+		l.lowInFlight--
+	}
+
+	switch {
+	case len(l.high) > 0 && l.inFlight < l.opts.MaxInFlight:
+		l.grant(&l.high)
+	case len(l.normal) > 0 && l.inFlight < l.opts.MaxInFlight-l.opts.ReservedHigh:
+		l.grant(&l.normal)
+	}
+}
+
+// grant hands a slot to the first waiter of a queue
+func (l *ConcurrencyLimiter) grant(queue *[]*limiterWaiter) {
+	w := (*queue)[0]
+	*queue = (*queue)[1:]
+	w.granted = true
+	l.inFlight++
+	close(w.ready)
+}
+
+// Init implements Plugin
+func (l *ConcurrencyLimiter) Init(*Client) error {
+	return nil
+}
+
+// Hooks implements Plugin
+func (l *ConcurrencyLimiter) Hooks() *Hooks {
+	return nil
+}
+
+// Middleware implements Plugin, running each statement in a slot
+func (l *ConcurrencyLimiter) Middleware() Middleware {
+	return func(ctx context.Context, stmt *Statement, next func(ctx context.Context) error) error {
+		p := PriorityFromContext(ctx)
+		if err := l.acquire(ctx, p); err != nil {
+			return err
+		}
+		defer l.release(p)
+		return next(ctx)
+	}
+}
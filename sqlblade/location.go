@@ -0,0 +1,121 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"time"
+)
+
+type locationKey struct{}
+
+// WithTimeLocation makes the client convert the time.Time fields it scans to
+// loc and bind time.Time arguments as UTC, so that times round-trip the same
+// whatever the time zone of the server, the session or the driver. With MySQL,
+// keep the driver's loc parameter at its UTC default. WithScanLocation
+// overrides loc for the statements of a context.
+func WithTimeLocation(loc *time.Location) ClientOption {
+	return func(c *Client) {
+		c.location = loc
+	}
+}
+
+// WithScanLocation returns a context whose statements convert the time.Time
+// fields they scan to loc, e.g. the time zone of the user of a request, and
+// bind time.Time arguments as UTC
+func WithScanLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// ScanLocationFromContext returns the location set with WithScanLocation, or nil
+func ScanLocationFromContext(ctx context.Context) *time.Location {
+	loc, _ := ctx.Value(locationKey{}).(*time.Location)
+	return loc
+}
+
+// timeLocation returns the location the statements of ctx scan times in, nil
+// to keep the times returned by the driver
+func (c *Client) timeLocation(ctx context.Context) *time.Location {
+	if loc := ScanLocationFromContext(ctx); loc != nil {
+		return loc
+	}
+	if c == nil {
+		return nil
+	}
+	return c.location
+}
+
+// bindUTC returns the arguments with their times converted to UTC. The slice
+// is only copied when an argument changes.
+func bindUTC(args []interface{}) []interface{} {
+	var bound []interface{}
+	for i, arg := range args {
+		var utc interface{}
+		switch v := arg.(type) {
+		case time.Time:
+			if v.Location() == time.UTC {
+				continue
+			}
+			utc = v.UTC()
+		case *time.Time:
+			if v == nil {
+				continue
+			}
+			utc = v.UTC()
+		case sql.NullTime:
+			if !v.Valid || v.Time.Location() == time.UTC {
+				continue
+			}
+			utc = sql.NullTime{Time: v.Time.UTC(), Valid: true}
+		default:
+			continue
+		}
+		if bound == nil {
+			bound = append([]interface{}(nil), args...)
+		}
+		bound[i] = utc
+	}
+	if bound == nil {
+		return args
+	}
+	return bound
+}
+
+// timeFields returns the time.Time and sql.NullTime fields of a struct
+func timeFields(info *structInfo) []fieldInfo {
+	var fields []fieldInfo
+	for _, field := range info.fields {
+		if field.fieldType == timeType || field.fieldType == nullTimeType {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// localizeTimes converts the times of the fields of the struct v to loc.
+// Zero times are left as is.
+func localizeTimes(v reflect.Value, fields []fieldInfo, loc *time.Location) {
+	for _, f := range fields {
+		field := f.value(v)
+		if !field.IsValid() {
+			continue
+		}
+		if f.isPtr {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+
+		switch t := field.Addr().Interface().(type) {
+		case *time.Time:
+			if !t.IsZero() {
+				*t = t.In(loc)
+			}
+		case *sql.NullTime:
+			if t.Valid && !t.Time.IsZero() {
+				t.Time = t.Time.In(loc)
+			}
+		}
+	}
+}
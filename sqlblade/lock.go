@@ -0,0 +1,223 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// ForUpdate locks the selected rows for writing until the end of the
+// transaction, with SELECT ... FOR UPDATE; build the query with QueryTx. The
+// lock is written by the dialect: SQLite, which locks the whole database for
+// writes, has no locking clauses and ignores it.
+func (qb *QueryBuilder[T]) ForUpdate() *QueryBuilder[T] {
+	qb.lock.Strength = dialect.ForUpdate
+	return qb
+}
+
+// ForShare locks the selected rows against writes by other transactions
+// until the end of the transaction, with SELECT ... FOR SHARE; see ForUpdate
+func (qb *QueryBuilder[T]) ForShare() *QueryBuilder[T] {
+	qb.lock.Strength = dialect.ForShare
+	return qb
+}
+
+// NoWait makes the locking SELECT fail instead of waiting for rows locked by
+// other transactions. Without ForShare it locks the rows FOR UPDATE.
+func (qb *QueryBuilder[T]) NoWait() *QueryBuilder[T] {
+	return qb.lockWait(dialect.NoWait)
+}
+
+// SkipLocked leaves the rows locked by other transactions out of the result,
+// so that concurrent workers can claim jobs from a queue table:
+//
+//	jobs, err := sqlblade.QueryTx[Job](tx).
+//	    Where("status", "=", "pending").
+//	    OrderBy("id", dialect.ASC).
+//	    Limit(10).
+//	    SkipLocked().
+//	    Execute(ctx)
+//
+// Without ForShare it locks the rows FOR UPDATE.
+func (qb *QueryBuilder[T]) SkipLocked() *QueryBuilder[T] {
+	return qb.lockWait(dialect.SkipLocked)
+}
+
+// lockWait sets how the lock waits, locking FOR UPDATE by default
+func (qb *QueryBuilder[T]) lockWait(wait dialect.LockWait) *QueryBuilder[T] {
+	if qb.lock.Strength == dialect.NoLock {
+		qb.lock.Strength = dialect.ForUpdate
+	}
+	qb.lock.Wait = wait
+	return qb
+}
+
+// lockSQL returns the locking clause of the query, empty when the dialect
+// has none
+func (qb *QueryBuilder[T]) lockSQL() string {
+	if qb.lock.Strength == dialect.NoLock {
+		return ""
+	}
+	d := qb.dialect
+	if aliased, ok := unwrapAliasDialect(d); ok {
+		d = aliased.Dialect
+	}
+	if builder, ok := d.(dialect.LockBuilder); ok {
+		return builder.BuildLock(qb.lock)
+	}
+	return ""
+}
+
+// RowLockOption configures the lock taken by LockRow
+type RowLockOption interface {
+	applyRowLock(lock *rowLock)
+}
+
+// rowLock is the lock taken by LockRow
+type rowLock struct {
+	mode    RowLockMode
+	noWait  bool
+	timeout time.Duration
+}
+
+// RowLockMode is the strength of the lock taken by LockRow
+type RowLockMode int
+
+const (
+	// LockForUpdate locks the row for writing, the default
+	LockForUpdate RowLockMode = iota
+	// LockForShare locks the row against writes by other transactions
+	LockForShare
+)
+
+func (m RowLockMode) applyRowLock(lock *rowLock) {
+	lock.mode = m
+}
+
+// waitTimeout is the RowLockOption of WaitTimeout
+type waitTimeout time.Duration
+
+func (w waitTimeout) applyRowLock(lock *rowLock) {
+	lock.noWait = w <= 0
+	lock.timeout = time.Duration(w)
+}
+
+// WaitTimeout bounds how long LockRow waits for a row locked by another
+// transaction; zero fails at once with NOWAIT. PostgreSQL waits in
+// milliseconds, MySQL in whole seconds, rounded up.
+func WaitTimeout(d time.Duration) RowLockOption {
+	return waitTimeout(d)
+}
+
+// LockRow reads the row of T with the given primary key and locks it until
+// the end of tx, for read-modify-write code:
+//
+//	user, err := sqlblade.LockRow[User](ctx, tx, id, sqlblade.LockForUpdate, sqlblade.WaitTimeout(2*time.Second))
+//	if errors.Is(err, sqlblade.ErrRowLocked) {
+//	    // another transaction holds the row
+//	}
+//
+// A row that stays locked by another transaction beyond the timeout gives
+// ErrRowLocked, and a missing row ErrNoRows. SQLite, which locks the whole
+// database for writes, takes no row lock.
+func LockRow[T any, X TxConn](ctx context.Context, tx X, pk interface{}, opts ...RowLockOption) (row T, err error) {
+	if ctx == nil {
+		return row, ErrNilContext
+	}
+	meta, err := metadataOf(modelType[T]())
+	if err != nil {
+		return row, err
+	}
+	if len(meta.PrimaryKey) != 1 {
+		return row, fmt.Errorf("%w: %s needs a single primary key column", ErrInvalidModel, meta.Table)
+	}
+
+	lock := rowLock{}
+	for _, opt := range opts {
+		opt.applyRowLock(&lock)
+	}
+
+	qb := QueryTx[T](tx).Where(meta.PrimaryKey[0], "=", pk)
+	qb.lock.Strength = dialect.ForUpdate
+	if lock.mode == LockForShare {
+		qb.lock.Strength = dialect.ForShare
+	}
+	if lock.noWait {
+		qb.lock.Wait = dialect.NoWait
+	}
+
+	if lock.timeout > 0 {
+		sqlTx, client := resolveTx(tx)
+		restore, setErr := setLockTimeout(ctx, sqlTx, client.dialect, lock.timeout)
+		if setErr != nil {
+			return row, setErr
+		}
+		defer func() {
+			// After a failed statement PostgreSQL rejects the restore until
+			// the rollback, which discards the timeout anyway
+			if restoreErr := restore(); restoreErr != nil && err == nil {
+				err = restoreErr
+			}
+		}()
+	}
+
+	row, err = qb.First(ctx)
+	if err != nil && isLockError(err) {
+		return row, fmt.Errorf("%w: %s %s = %v: %w", ErrRowLocked, meta.Table, meta.PrimaryKey[0], pk, err)
+	}
+	return row, err
+}
+
+// setLockTimeout sets how long the statements of tx wait for row locks,
+// returning a function restoring the previous timeout
+func setLockTimeout(ctx context.Context, tx *sql.Tx, d dialect.Dialect, timeout time.Duration) (func() error, error) {
+	var getSQL, setSQL string
+	var value interface{}
+	switch d.Name() {
+	case dialectPostgres:
+		getSQL = "SELECT current_setting('lock_timeout')"
+		setSQL = "SELECT set_config('lock_timeout', $1, true)"
+		value = fmt.Sprintf("%dms", (timeout+time.Millisecond-1)/time.Millisecond)
+	case dialectMySQL:
+		getSQL = "SELECT @@SESSION.innodb_lock_wait_timeout"
+		setSQL = "SET SESSION innodb_lock_wait_timeout = ?"
+		value = int64((timeout + time.Second - 1) / time.Second)
+	default:
+		return func() error { return nil }, nil
+	}
+
+	var previous string
+	if err := tx.QueryRowContext(ctx, getSQL).Scan(&previous); err != nil {
+		return nil, wrapQueryError(err, getSQL, nil)
+	}
+	if _, err := tx.ExecContext(ctx, setSQL, value); err != nil {
+		return nil, wrapQueryError(err, setSQL, []interface{}{value})
+	}
+	return func() error {
+		if _, err := tx.ExecContext(ctx, setSQL, previous); err != nil {
+			return wrapQueryError(err, setSQL, []interface{}{previous})
+		}
+		return nil
+	}, nil
+}
+
+// isLockError reports whether err is the error of PostgreSQL, MySQL or
+// SQLite for a lock that could not be acquired in time
+func isLockError(err error) bool {
+	var queryErr *QueryError
+	if errors.As(err, &queryErr) {
+		err = queryErr.Err
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "55p03") ||
+		strings.Contains(msg, "could not obtain lock") ||
+		strings.Contains(msg, "lock timeout") ||
+		strings.Contains(msg, "lock wait timeout exceeded") ||
+		strings.Contains(msg, "nowait is set") ||
+		strings.Contains(msg, "database is locked")
+}
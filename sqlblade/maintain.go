@@ -0,0 +1,156 @@
+package sqlblade
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MaintenanceOp is a set of maintenance operations run by Maintain
+type MaintenanceOp int
+
+const (
+	// Analyze refreshes the planner statistics of the table
+	Analyze MaintenanceOp = 1 << iota
+	// Vacuum reclaims the space of deleted rows: VACUUM on PostgreSQL and
+	// SQLite, OPTIMIZE TABLE on MySQL
+	Vacuum
+	// Optimize rebuilds the table and its indexes: OPTIMIZE TABLE on MySQL,
+	// PRAGMA optimize on SQLite. It is not supported on PostgreSQL, whose
+	// equivalent VACUUM FULL locks the table exclusively for the whole rebuild.
+	Optimize
+)
+
+// String returns the operations joined by "|", e.g. "Analyze|Vacuum"
+func (op MaintenanceOp) String() string {
+	var names []string
+	for _, o := range []struct {
+		op   MaintenanceOp
+		name string
+	}{{Analyze, "Analyze"}, {Vacuum, "Vacuum"}, {Optimize, "Optimize"}} {
+		if op&o.op != 0 {
+			names = append(names, o.name)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Sprintf("MaintenanceOp(%d)", int(op))
+	}
+	return strings.Join(names, "|")
+}
+
+// maintenanceOps holds every valid MaintenanceOp bit
+const maintenanceOps = Analyze | Vacuum | Optimize
+
+// Maintain runs maintenance operations on a model's table, e.g. an Analyze
+// after a retention job deleted many rows:
+//
+//	err := client.Maintain(ctx, Event{}, sqlblade.Vacuum|sqlblade.Analyze)
+//
+// The commands run outside of any transaction, as VACUUM requires, and fail
+// with ErrReadOnly on a client in read-only mode. Operations without an
+// equivalent in the dialect fail with ErrDialectUnsupported before anything
+// is run. On SQLite, Vacuum rebuilds the whole database file.
+func (c *Client) Maintain(ctx context.Context, model interface{}, ops MaintenanceOp) error {
+	if ctx == nil {
+		return ErrNilContext
+	}
+	if ops == 0 || ops&^maintenanceOps != 0 {
+		return fmt.Errorf("sqlblade: invalid maintenance operations %s", ops)
+	}
+
+	typ := reflect.TypeOf(model)
+	if typ == nil {
+		return ErrInvalidModel
+	}
+	meta, err := metadataOf(typ)
+	if err != nil {
+		return err
+	}
+
+	commands, err := maintenanceSQL(c.dialect.Name(), c.dialect.QuoteIdentifier(meta.Table), ops)
+	if err != nil {
+		return err
+	}
+
+	for _, sqlStr := range commands {
+		stmt := &Statement{Operation: "MAINTAIN", Table: meta.Table, SQL: sqlStr}
+		err := c.execute(ctx, stmt, func(ctx context.Context) error {
+			if c.dialect.Name() == dialectMySQL {
+				return c.runMySQLMaintenance(ctx, stmt.SQL)
+			}
+			if _, err := c.db.ExecContext(ctx, stmt.SQL); err != nil {
+				return wrapQueryError(err, stmt.SQL, nil)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maintenanceSQL returns the commands running ops on the quoted table
+func maintenanceSQL(dialectName, table string, ops MaintenanceOp) ([]string, error) {
+	switch dialectName {
+	case dialectPostgres:
+		if ops&Optimize != 0 {
+			return nil, fmt.Errorf("%w: Optimize on %s; use Vacuum", ErrDialectUnsupported, dialectName)
+		}
+		switch {
+		case ops&Vacuum != 0 && ops&Analyze != 0:
+			return []string{"VACUUM (ANALYZE) " + table}, nil
+		case ops&Vacuum != 0:
+			return []string{"VACUUM " + table}, nil
+		default:
+			return []string{"ANALYZE " + table}, nil
+		}
+
+	case dialectMySQL:
+		// OPTIMIZE TABLE also refreshes the statistics, so a separate ANALYZE
+		// is only needed without it
+		if ops&(Vacuum|Optimize) != 0 {
+			return []string{"OPTIMIZE TABLE " + table}, nil
+		}
+		return []string{"ANALYZE TABLE " + table}, nil
+
+	case dialectSQLite:
+		var commands []string
+		if ops&Vacuum != 0 {
+			commands = append(commands, "VACUUM")
+		}
+		if ops&Analyze != 0 {
+			commands = append(commands, "ANALYZE "+table)
+		}
+		if ops&Optimize != 0 {
+			commands = append(commands, "PRAGMA optimize")
+		}
+		return commands, nil
+
+	default:
+		return nil, fmt.Errorf("%w: Maintain on %s", ErrDialectUnsupported, dialectName)
+	}
+}
+
+// runMySQLMaintenance runs an ANALYZE or OPTIMIZE TABLE statement. MySQL
+// reports their failures as result rows rather than errors, so the rows are
+// checked for an error message.
+func (c *Client) runMySQLMaintenance(ctx context.Context, sqlStr string) error {
+	rows, err := c.db.QueryContext(ctx, sqlStr)
+	if err != nil {
+		return wrapQueryError(err, sqlStr, nil)
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var table, op, msgType, msgText string
+		if err := rows.Scan(&table, &op, &msgType, &msgText); err != nil {
+			return fmt.Errorf("sqlblade: failed to scan row: %w", err)
+		}
+		if strings.EqualFold(msgType, "error") {
+			return wrapQueryError(fmt.Errorf("%s %s: %s", op, table, msgText), sqlStr, nil)
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,127 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExecuteMaps executes the query and returns its rows as maps keyed by column
+// name, for dynamic admin and reporting screens whose columns aren't known at
+// compile time. Values are normalized across drivers:
+//
+//   - integers are int64, floats float64 and booleans bool, also when the
+//     driver returns them as text (MySQL)
+//   - DECIMAL/NUMERIC values are their exact text, e.g. "12.30"
+//   - binary columns (bytea, BLOB, BINARY) stay []byte, other text is string
+//   - times are in the client's time zone; NULL is nil
+//
+// Scan hooks of T are not run.
+func (qb *QueryBuilder[T]) ExecuteMaps(ctx context.Context) ([]map[string]interface{}, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+
+	var result []map[string]interface{}
+	sqlStr, args := qb.buildSQL()
+	err := qb.query(ctx, sqlStr, args, func(rows *sql.Rows) error {
+		var err error
+		result, err = scanMaps(rows, qb.client.timeLocation(ctx))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RawMaps creates a raw query whose Execute returns its rows as maps keyed by
+// column name, normalized as by QueryBuilder.ExecuteMaps
+func RawMaps[C Conn](db C, query string, args ...interface{}) *RawQuery[map[string]interface{}] {
+	return Raw[map[string]interface{}](db, query, args...)
+}
+
+// scanMaps scans rows into normalized maps keyed by column name
+func scanMaps(rows *sql.Rows, loc *time.Location) ([]map[string]interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	typeNames := make([]string, len(types))
+	for i, ct := range types {
+		typeNames[i] = strings.ToUpper(ct.DatabaseTypeName())
+	}
+
+	buf := globalScanBufferPool.Get(len(columns))
+	defer globalScanBufferPool.Put(buf)
+
+	result := make([]map[string]interface{}, 0, resultInitialCapacity)
+	for rows.Next() {
+		if err := rows.Scan(buf.ptrs...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			row[col] = normalizeMapValue(buf.values[i], typeNames[i], loc)
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// normalizeMapValue returns a scanned value in the form documented by
+// ExecuteMaps, given the database type name of its column
+func normalizeMapValue(value interface{}, typeName string, loc *time.Location) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		if isBinaryType(typeName) {
+			return append([]byte(nil), v...)
+		}
+		return parseMapText(string(v), typeName)
+	case string:
+		return parseMapText(v, typeName)
+	case int32:
+		return int64(v)
+	case float32:
+		return float64(v)
+	case time.Time:
+		if loc != nil {
+			return v.In(loc)
+		}
+	}
+	return value
+}
+
+// parseMapText converts a value returned as text to the type of its column,
+// keeping the text when it doesn't parse
+func parseMapText(text, typeName string) interface{} {
+	switch kind, _ := columnKindOf(typeName); kind {
+	case ColumnInt:
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+	case ColumnFloat:
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	case ColumnBool:
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b
+		}
+	}
+	return text
+}
+
+// isBinaryType reports whether a database type name is of a binary column
+func isBinaryType(typeName string) bool {
+	return typeName == "BYTEA" || strings.Contains(typeName, "BLOB") || strings.HasSuffix(typeName, "BINARY")
+}
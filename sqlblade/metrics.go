@@ -0,0 +1,222 @@
+package sqlblade
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDurationBuckets are the upper bounds of the duration histogram buckets
+var DefaultDurationBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// MetricKey identifies the statements counted together
+type MetricKey struct {
+	Operation string
+	Table     string
+	Tag       string // the statement's tags joined with ",", see QueryBuilder.Tag
+}
+
+// Histogram is a distribution of durations. Counts[i] is the number of
+// observations up to Bounds[i] and above the previous bound; the last count
+// holds the observations above the last bound.
+type Histogram struct {
+	Bounds []time.Duration
+	Counts []uint64
+	Count  uint64
+	Sum    time.Duration
+}
+
+func newHistogram(bounds []time.Duration) *Histogram {
+	return &Histogram{Bounds: bounds, Counts: make([]uint64, len(bounds)+1)}
+}
+
+func (h *Histogram) observe(d time.Duration) {
+	i := 0
+	for i < len(h.Bounds) && d > h.Bounds[i] {
+		i++
+	}
+	h.Counts[i]++
+	h.Count++
+	h.Sum += d
+}
+
+func (h *Histogram) clone() Histogram {
+	return Histogram{
+		Bounds: h.Bounds,
+		Counts: append([]uint64(nil), h.Counts...),
+		Count:  h.Count,
+		Sum:    h.Sum,
+	}
+}
+
+// MetricsSnapshot is a point-in-time copy of the collected metrics
+type MetricsSnapshot struct {
+	Queries        map[MetricKey]uint64
+	Errors         map[MetricKey]uint64
+	SlowQueries    map[MetricKey]uint64
+	QueryDurations map[MetricKey]Histogram
+	ScanDurations  Histogram
+	StmtCache      StmtCacheStats
+	SplitQueries   uint64 // queries split by SplitLargeInLists, see SplitQueries
+}
+
+// MetricsCollector collects machine-readable query metrics from hooks:
+// counts, errors and slow queries by operation and table, query and scan
+// duration histograms, and the statement cache hit rate. It is a Plugin:
+//
+//	metrics := sqlblade.NewMetricsCollector().SetSlowQueryThreshold(200 * time.Millisecond)
+//	client.Use(metrics)
+//	snapshot := metrics.Snapshot()
+type MetricsCollector struct {
+	mu             sync.Mutex
+	slowThreshold  time.Duration
+	buckets        []time.Duration
+	queries        map[MetricKey]uint64
+	errors         map[MetricKey]uint64
+	slowQueries    map[MetricKey]uint64
+	queryDurations map[MetricKey]*Histogram
+	scanDurations  *Histogram
+}
+
+// NewMetricsCollector creates a collector with DefaultDurationBuckets and a
+// slow query threshold of 100ms
+func NewMetricsCollector() *MetricsCollector {
+	mc := &MetricsCollector{
+		slowThreshold: 100 * time.Millisecond,
+		buckets:       DefaultDurationBuckets,
+	}
+	mc.Reset()
+	return mc
+}
+
+// SetSlowQueryThreshold sets the duration above which queries count as slow
+func (mc *MetricsCollector) SetSlowQueryThreshold(threshold time.Duration) *MetricsCollector {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.slowThreshold = threshold
+	return mc
+}
+
+// SetBuckets sets the upper bounds of the histogram buckets, in increasing
+// order, and resets the collected metrics
+func (mc *MetricsCollector) SetBuckets(bounds ...time.Duration) *MetricsCollector {
+	mc.mu.Lock()
+	mc.buckets = append([]time.Duration(nil), bounds...)
+	mc.mu.Unlock()
+	mc.Reset()
+	return mc
+}
+
+// Reset clears the collected metrics
+func (mc *MetricsCollector) Reset() {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.queries = make(map[MetricKey]uint64)
+	mc.errors = make(map[MetricKey]uint64)
+	mc.slowQueries = make(map[MetricKey]uint64)
+	mc.queryDurations = make(map[MetricKey]*Histogram)
+	mc.scanDurations = newHistogram(mc.buckets)
+}
+
+// Snapshot returns a copy of the collected metrics
+func (mc *MetricsCollector) Snapshot() MetricsSnapshot {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	snapshot := MetricsSnapshot{
+		Queries:        make(map[MetricKey]uint64, len(mc.queries)),
+		Errors:         make(map[MetricKey]uint64, len(mc.errors)),
+		SlowQueries:    make(map[MetricKey]uint64, len(mc.slowQueries)),
+		QueryDurations: make(map[MetricKey]Histogram, len(mc.queryDurations)),
+		ScanDurations:  mc.scanDurations.clone(),
+		StmtCache:      StatementCacheStats(),
+		SplitQueries:   SplitQueries(),
+	}
+	for k, v := range mc.queries {
+		snapshot.Queries[k] = v
+	}
+	for k, v := range mc.errors {
+		snapshot.Errors[k] = v
+	}
+	for k, v := range mc.slowQueries {
+		snapshot.SlowQueries[k] = v
+	}
+	for k, h := range mc.queryDurations {
+		snapshot.QueryDurations[k] = h.clone()
+	}
+	return snapshot
+}
+
+// observe records a finished statement
+func (mc *MetricsCollector) observe(_ context.Context, event *HookEvent) error {
+	key := MetricKey{Operation: event.Operation, Table: event.Table, Tag: strings.Join(event.Tags, ",")}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.queries[key]++
+	if event.Err != nil {
+		mc.errors[key]++
+	}
+	if mc.slowThreshold > 0 && event.Duration >= mc.slowThreshold {
+		mc.slowQueries[key]++
+	}
+
+	h, ok := mc.queryDurations[key]
+	if !ok {
+		h = newHistogram(mc.buckets)
+		mc.queryDurations[key] = h
+	}
+	h.observe(event.Duration)
+	if event.ScanDuration > 0 {
+		mc.scanDurations.observe(event.ScanDuration)
+	}
+	return nil
+}
+
+// Init implements Plugin
+func (mc *MetricsCollector) Init(*Client) error {
+	return nil
+}
+
+// Middleware implements Plugin
+func (mc *MetricsCollector) Middleware() Middleware {
+	return nil
+}
+
+// Hooks implements Plugin
+func (mc *MetricsCollector) Hooks() *Hooks {
+	hooks := NewHooks()
+	hooks.AfterQueryWithError(mc.observe)
+	return hooks
+}
+
+var (
+	globalMetrics     *MetricsCollector
+	globalMetricsOnce sync.Once
+)
+
+// Metrics returns a process-wide collector for the queries that run
+// DefaultHooks. It is registered with DefaultHooks on first use.
+func Metrics() *MetricsCollector {
+	globalMetricsOnce.Do(func() {
+		globalMetrics = NewMetricsCollector()
+		DefaultHooks.AfterQueryWithError(globalMetrics.observe)
+	})
+	return globalMetrics
+}
@@ -0,0 +1,34 @@
+package sqlblade_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+)
+
+func TestMetricsCountTaggedDeletesAndAggregates(t *testing.T) {
+	ctx := context.Background()
+	db, _ := openRecorder(t)
+	client := sqlblade.Open(db, sqlblade.WithHooks(sqlblade.NewHooks()))
+	metrics := sqlblade.NewMetricsCollector()
+	if err := client.Use(metrics); err != nil {
+		t.Fatalf("Use: %v", err)
+	}
+
+	if _, err := sqlblade.Delete[txUser](client).Where("id", "=", 1).Tag("feature:cleanup").Execute(ctx); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	// The recorder returns no rows, so the count fails after running
+	_, _ = sqlblade.Query[txUser](client).Tag("feature:report").Count(ctx)
+
+	snapshot := metrics.Snapshot()
+	deletes := sqlblade.MetricKey{Operation: "DELETE", Table: "users", Tag: "feature:cleanup"}
+	if n := snapshot.Queries[deletes]; n != 1 {
+		t.Errorf("queries of %+v = %d, want 1", deletes, n)
+	}
+	counts := sqlblade.MetricKey{Operation: "SELECT", Table: "users", Tag: "feature:report"}
+	if n := snapshot.Queries[counts]; n != 1 {
+		t.Errorf("queries of %+v = %d, want 1", counts, n)
+	}
+}
@@ -0,0 +1,151 @@
+package sqlblade
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// AutoMigrate creates the tables for the given models if they don't exist yet.
+// Columns are derived from the model metadata; a single integer primary key
+// becomes an auto-incrementing column.
+func (c *Client) AutoMigrate(ctx context.Context, models ...interface{}) error {
+	if ctx == nil {
+		return ErrNilContext
+	}
+
+	for _, model := range models {
+		typ := reflect.TypeOf(model)
+		if typ == nil {
+			return ErrInvalidModel
+		}
+
+		meta, err := metadataOf(typ)
+		if err != nil {
+			return err
+		}
+
+		sqlStr := buildCreateTableSQL(c.dialect, meta)
+		if _, err := c.db.ExecContext(ctx, sqlStr); err != nil {
+			return wrapQueryError(err, sqlStr, nil)
+		}
+	}
+
+	return nil
+}
+
+// buildCreateTableSQL builds a CREATE TABLE IF NOT EXISTS statement for a model
+func buildCreateTableSQL(d dialect.Dialect, meta *ModelMeta) string {
+	var buf strings.Builder
+	buf.Grow(sqlBuilderBufferSize)
+
+	buf.WriteString("CREATE TABLE IF NOT EXISTS ")
+	buf.WriteString(d.QuoteIdentifier(meta.Table))
+	buf.WriteString(" (")
+
+	singlePK := len(meta.PrimaryKey) == 1
+	for i, col := range meta.Columns {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(d.QuoteIdentifier(col.Name))
+		buf.WriteString(" ")
+
+		if singlePK && meta.PrimaryKey[0] == col.Name {
+			if isIntKind(baseType(col.Type).Kind()) {
+				buf.WriteString(autoIncrementType(d))
+			} else {
+				buf.WriteString(columnType(d, col.Type))
+				buf.WriteString(" PRIMARY KEY")
+			}
+			continue
+		}
+		switch {
+		case hasTagOption(col.Options, "json"):
+			buf.WriteString(jsonColumnType(d))
+		case hasTagOption(col.Options, "decimal"):
+			buf.WriteString(decimalColumnType(d))
+		default:
+			buf.WriteString(columnType(d, col.Type))
+		}
+	}
+
+	if len(meta.PrimaryKey) > 1 {
+		quoted := make([]string, len(meta.PrimaryKey))
+		for i, col := range meta.PrimaryKey {
+			quoted[i] = d.QuoteIdentifier(col)
+		}
+		buf.WriteString(", PRIMARY KEY (")
+		buf.WriteString(strings.Join(quoted, ", "))
+		buf.WriteString(")")
+	}
+
+	buf.WriteString(")")
+	return buf.String()
+}
+
+// autoIncrementType returns the column definition of an auto-incrementing primary key
+func autoIncrementType(d dialect.Dialect) string {
+	switch d.Name() {
+	case dialectMySQL:
+		return "BIGINT AUTO_INCREMENT PRIMARY KEY"
+	case dialectSQLite:
+		return "INTEGER PRIMARY KEY AUTOINCREMENT"
+	default:
+		return "BIGSERIAL PRIMARY KEY"
+	}
+}
+
+// columnType maps a Go field type to a column type for the dialect
+func columnType(d dialect.Dialect, typ reflect.Type) string {
+	name := d.Name()
+	typ = baseType(typ)
+
+	switch {
+	case typ == timeType:
+		if name == dialectPostgres {
+			return "TIMESTAMP"
+		}
+		return "DATETIME"
+	case isDecimalType(typ):
+		return decimalColumnType(d)
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8:
+		if name == dialectPostgres {
+			return "BYTEA"
+		}
+		return "BLOB"
+	case typ.Kind() == reflect.Bool:
+		return "BOOLEAN"
+	case isIntKind(typ.Kind()):
+		if name == dialectSQLite {
+			return "INTEGER"
+		}
+		return "BIGINT"
+	case typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64:
+		switch name {
+		case dialectMySQL:
+			return "DOUBLE"
+		case dialectSQLite:
+			return "REAL"
+		default:
+			return "DOUBLE PRECISION"
+		}
+	default:
+		return "TEXT"
+	}
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
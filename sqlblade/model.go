@@ -0,0 +1,281 @@
+package sqlblade
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// RelationKind describes how two models are related
+type RelationKind int
+
+const (
+	// HasOne means the related table holds a foreign key to this model
+	HasOne RelationKind = iota
+	// HasMany means many rows of the related table reference this model
+	HasMany
+	// BelongsTo means this model holds a foreign key to the related table
+	BelongsTo
+	// ManyToMany means the models are related through a join table
+	ManyToMany
+)
+
+// String returns the name of the relation kind
+func (k RelationKind) String() string {
+	switch k {
+	case HasOne:
+		return "has_one"
+	case HasMany:
+		return "has_many"
+	case BelongsTo:
+		return "belongs_to"
+	case ManyToMany:
+		return "many_to_many"
+	default:
+		return "unknown"
+	}
+}
+
+// Relation describes a relation between a model and another table
+type Relation struct {
+	Name       string
+	Kind       RelationKind
+	Table      string
+	ForeignKey string
+	References string
+	JoinTable  string // only used by ManyToMany
+}
+
+// ColumnMeta describes a mapped column of a model
+type ColumnMeta struct {
+	Name     string
+	Field    string
+	Type     reflect.Type
+	Nullable bool
+	Options  []string
+}
+
+// ModelMeta holds the metadata of a model, collected from its struct tags and
+// the options given to RegisterModel
+type ModelMeta struct {
+	Type       reflect.Type
+	Table      string
+	PrimaryKey []string
+	SoftDelete string
+	Columns    []ColumnMeta
+	Relations  []Relation
+	Registered bool
+
+	validators []func(value interface{}) error
+	aliases    []*columnAlias
+}
+
+// ModelOption configures a model registration
+type ModelOption func(*ModelMeta)
+
+// WithTable overrides the table name of the model
+func WithTable(name string) ModelOption {
+	return func(m *ModelMeta) {
+		m.Table = name
+	}
+}
+
+// WithPrimaryKey sets the primary key columns of the model
+func WithPrimaryKey(columns ...string) ModelOption {
+	return func(m *ModelMeta) {
+		m.PrimaryKey = columns
+	}
+}
+
+// WithSoftDelete sets the column used to mark rows as deleted
+func WithSoftDelete(column string) ModelOption {
+	return func(m *ModelMeta) {
+		m.SoftDelete = column
+	}
+}
+
+// WithRelation adds a relation to the model
+func WithRelation(relation Relation) ModelOption {
+	return func(m *ModelMeta) {
+		m.Relations = append(m.Relations, relation)
+	}
+}
+
+// WithValidator adds a validator that is run by ModelMeta.Validate
+func WithValidator[T any](fn func(value *T) error) ModelOption {
+	return func(m *ModelMeta) {
+		m.validators = append(m.validators, func(value interface{}) error {
+			switch v := value.(type) {
+			case *T:
+				return fn(v)
+			case T:
+				return fn(&v)
+			default:
+				return ErrInvalidModel
+			}
+		})
+	}
+}
+
+// Validate runs the registered validators against a value of the model
+func (m *ModelMeta) Validate(value interface{}) error {
+	for _, validate := range m.validators {
+		if err := validate(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Column returns the metadata of a column by name
+func (m *ModelMeta) Column(name string) (ColumnMeta, bool) {
+	for _, col := range m.Columns {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return ColumnMeta{}, false
+}
+
+// IsPrimaryKey reports whether the column is part of the primary key
+func (m *ModelMeta) IsPrimaryKey(column string) bool {
+	for _, pk := range m.PrimaryKey {
+		if pk == column {
+			return true
+		}
+	}
+	return false
+}
+
+var modelRegistry = struct {
+	mu     sync.RWMutex
+	models map[reflect.Type]*ModelMeta
+}{models: make(map[reflect.Type]*ModelMeta)}
+
+// derivedModels caches the metadata of unregistered models
+var derivedModels sync.Map // map[reflect.Type]*ModelMeta
+
+// RegisterModel registers a model and its metadata. Registration is optional;
+// unregistered models fall back to the metadata derived from their struct tags.
+func RegisterModel[T any](opts ...ModelOption) (*ModelMeta, error) {
+	typ := modelType[T]()
+	if typ.Kind() != reflect.Struct {
+		return nil, ErrInvalidModel
+	}
+
+	// The table name may be overridden, so derive the metadata from scratch
+	structCache.Delete(typ)
+	derivedModels.Delete(typ)
+	globalTableNameCache.delete(typ.String())
+
+	meta := &ModelMeta{Type: typ, Registered: true}
+	for _, opt := range opts {
+		opt(meta)
+	}
+
+	modelRegistry.mu.Lock()
+	modelRegistry.models[typ] = meta
+	modelRegistry.mu.Unlock()
+
+	if meta.Table != "" {
+		globalTableNameCache.set(typ.String(), meta.Table)
+	}
+
+	info, err := getStructInfo(typ)
+	if err != nil {
+		return nil, err
+	}
+	fillModelMeta(meta, info)
+
+	return meta, nil
+}
+
+// Metadata returns the metadata of a model, registered or not
+func Metadata[T any]() (*ModelMeta, error) {
+	return metadataOf(modelType[T]())
+}
+
+// RegisteredModels returns the metadata of all registered models, sorted by table name
+func RegisteredModels() []*ModelMeta {
+	modelRegistry.mu.RLock()
+	models := make([]*ModelMeta, 0, len(modelRegistry.models))
+	for _, meta := range modelRegistry.models {
+		models = append(models, meta)
+	}
+	modelRegistry.mu.RUnlock()
+
+	sort.Slice(models, func(i, j int) bool { return models[i].Table < models[j].Table })
+	return models
+}
+
+// registeredModel returns the registration of a type, if any
+func registeredModel(typ reflect.Type) *ModelMeta {
+	modelRegistry.mu.RLock()
+	defer modelRegistry.mu.RUnlock()
+	return modelRegistry.models[typ]
+}
+
+// metadataOf returns the metadata of a type, registered or derived from tags
+func metadataOf(typ reflect.Type) (*ModelMeta, error) {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if meta := registeredModel(typ); meta != nil {
+		return meta, nil
+	}
+
+	if cached, ok := derivedModels.Load(typ); ok {
+		if meta, ok := cached.(*ModelMeta); ok {
+			return meta, nil
+		}
+	}
+
+	info, err := getStructInfo(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &ModelMeta{Type: typ}
+	fillModelMeta(meta, info)
+	derivedModels.Store(typ, meta)
+	return meta, nil
+}
+
+// fillModelMeta completes a model's metadata from its struct info
+func fillModelMeta(meta *ModelMeta, info *structInfo) {
+	meta.Table = info.tableName
+	meta.Columns = make([]ColumnMeta, 0, len(info.fields))
+
+	var tagged []string
+	for _, field := range info.fields {
+		meta.Columns = append(meta.Columns, ColumnMeta{
+			Name:     field.dbColumn,
+			Field:    field.name,
+			Type:     field.fieldType,
+			Nullable: field.isPtr,
+			Options:  field.options,
+		})
+		if field.hasOption("pk") {
+			tagged = append(tagged, field.dbColumn)
+		}
+	}
+
+	if len(meta.PrimaryKey) == 0 {
+		meta.PrimaryKey = tagged
+	}
+	if len(meta.PrimaryKey) == 0 {
+		if _, ok := meta.Column("id"); ok {
+			meta.PrimaryKey = []string{"id"}
+		}
+	}
+}
+
+// modelType returns the struct type of T, dereferencing pointers
+func modelType[T any]() reflect.Type {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
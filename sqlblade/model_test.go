@@ -0,0 +1,24 @@
+package sqlblade_test
+
+import (
+	"testing"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+)
+
+type renamedModel struct {
+	ID int `db:"id"`
+}
+
+func TestRegisterModelResetsTableName(t *testing.T) {
+	if _, err := sqlblade.RegisterModel[renamedModel](sqlblade.WithTable("people")); err != nil {
+		t.Fatalf("RegisterModel: %v", err)
+	}
+	meta, err := sqlblade.RegisterModel[renamedModel]()
+	if err != nil {
+		t.Fatalf("RegisterModel: %v", err)
+	}
+	if meta.Table != "renamed_model" {
+		t.Errorf("Table = %q, want %q", meta.Table, "renamed_model")
+	}
+}
@@ -0,0 +1,304 @@
+// Package mongoquery translates simple SQLBlade queries into MongoDB filter
+// and aggregation documents, for repositories backed by both a SQL database
+// and MongoDB. It is experimental.
+//
+// Queries are translated from their AST, without a MongoDB driver dependency:
+// filters are plain maps, usable as bson.M, and ordered documents (sort
+// specifications and pipeline stages) are D values, whose elements map one to
+// one to the elements of a bson.D:
+//
+//	q, err := mongoquery.Translate(sqlblade.Query[User](db).
+//	    Where("age", ">=", 18).
+//	    OrWhere("role", "=", "admin").
+//	    Limit(10).
+//	    AST())
+//	// q.Filter: {"$or": [{"age": {"$gte": 18}}, {"role": {"$eq": "admin"}}]}
+//	cursor, err := users.Find(ctx, bson.M(q.Filter), options.Find().SetLimit(*q.Limit))
+//
+// Comparisons, IN, BETWEEN, LIKE, NULL checks, column comparisons and AND/OR
+// groups are supported, together with the selected columns, ordering, limit
+// and offset. Joins, raw SQL, subqueries, GROUP BY, HAVING, DISTINCT, window
+// functions, locking clauses and set operations fail with ErrUnsupported.
+package mongoquery
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// ErrUnsupported is returned for queries using features without a translation
+var ErrUnsupported = errors.New("sqlblade/mongoquery: unsupported query")
+
+// M is an unordered document, like bson.M
+type M = map[string]interface{}
+
+// E is an element of an ordered document, like bson.E
+type E struct {
+	Key   string
+	Value interface{}
+}
+
+// D is an ordered document, like bson.D
+type D []E
+
+// Query is a translated query
+type Query struct {
+	Collection string
+	Filter     M
+	Projection M // nil when every field is selected
+	Sort       D // 1 for ascending, -1 for descending fields
+	Skip       *int64
+	Limit      *int64
+}
+
+// Translate converts the AST of a query into a MongoDB query on the
+// collection named after the table
+func Translate(ast *sqlblade.QueryAST) (*Query, error) {
+	if ast == nil {
+		return nil, fmt.Errorf("%w: nil query", ErrUnsupported)
+	}
+	switch {
+	case len(ast.Joins) > 0:
+		return nil, fmt.Errorf("%w: joins", ErrUnsupported)
+	case len(ast.GroupBy) > 0 || len(ast.Having) > 0:
+		return nil, fmt.Errorf("%w: GROUP BY and HAVING", ErrUnsupported)
+	case ast.Distinct:
+		return nil, fmt.Errorf("%w: DISTINCT", ErrUnsupported)
+	case len(ast.Exprs) > 0:
+		return nil, fmt.Errorf("%w: selected expressions", ErrUnsupported)
+	case len(ast.Windows) > 0:
+		return nil, fmt.Errorf("%w: window functions", ErrUnsupported)
+	case ast.Lock.Strength != dialect.NoLock:
+		return nil, fmt.Errorf("%w: locking clauses", ErrUnsupported)
+	case len(ast.SetOps) > 0:
+		return nil, fmt.Errorf("%w: set operations", ErrUnsupported)
+	}
+
+	t := translator{table: ast.Table, alias: ast.Alias}
+	filter, err := t.conditions(ast.Where)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{Collection: ast.Table, Filter: filter}
+	if len(ast.Columns) > 0 {
+		q.Projection = M{}
+		for _, col := range ast.Columns {
+			q.Projection[t.field(col)] = 1
+		}
+		if _, ok := q.Projection["_id"]; !ok {
+			q.Projection["_id"] = 0
+		}
+	}
+	for _, ob := range ast.OrderBy {
+		if ob.Raw {
+			return nil, fmt.Errorf("%w: raw ORDER BY %s", ErrUnsupported, ob.Column)
+		}
+		direction := 1
+		if ob.Order == dialect.DESC {
+			direction = -1
+		}
+		q.Sort = append(q.Sort, E{Key: t.field(ob.Column), Value: direction})
+	}
+	if ast.Offset != nil {
+		skip := int64(*ast.Offset)
+		q.Skip = &skip
+	}
+	if ast.Limit != nil {
+		limit := int64(*ast.Limit)
+		q.Limit = &limit
+	}
+	return q, nil
+}
+
+// Pipeline returns the query as the stages of an aggregation pipeline
+func (q *Query) Pipeline() []D {
+	pipeline := []D{{{Key: "$match", Value: q.Filter}}}
+	if len(q.Sort) > 0 {
+		pipeline = append(pipeline, D{{Key: "$sort", Value: q.Sort}})
+	}
+	if q.Skip != nil {
+		pipeline = append(pipeline, D{{Key: "$skip", Value: *q.Skip}})
+	}
+	if q.Limit != nil {
+		pipeline = append(pipeline, D{{Key: "$limit", Value: *q.Limit}})
+	}
+	if q.Projection != nil {
+		pipeline = append(pipeline, D{{Key: "$project", Value: q.Projection}})
+	}
+	return pipeline
+}
+
+// translator converts the conditions of a query on a table
+type translator struct {
+	table string
+	alias string
+}
+
+// field returns the document field of a column, without a qualifier naming
+// the query's table or alias; other dotted names are nested fields
+func (t translator) field(column string) string {
+	if i := strings.IndexByte(column, '.'); i >= 0 {
+		if q := column[:i]; q == t.table || (t.alias != "" && q == t.alias) {
+			return column[i+1:]
+		}
+	}
+	return column
+}
+
+// conditions converts a list of conditions, in which AND binds tighter than
+// OR as in SQL
+func (t translator) conditions(conditions []sqlblade.Condition) (M, error) {
+	var (
+		alternatives []M
+		run          []M
+	)
+	for i := range conditions {
+		c := &conditions[i]
+		if c.Or && len(run) > 0 {
+			alternatives = append(alternatives, all(run))
+			run = nil
+		}
+		filter, err := t.condition(c)
+		if err != nil {
+			return nil, err
+		}
+		run = append(run, filter)
+	}
+	if len(run) > 0 {
+		alternatives = append(alternatives, all(run))
+	}
+
+	switch len(alternatives) {
+	case 0:
+		return M{}, nil
+	case 1:
+		return alternatives[0], nil
+	default:
+		return M{"$or": alternatives}, nil
+	}
+}
+
+// all combines filters that must all match
+func all(filters []M) M {
+	if len(filters) == 1 {
+		return filters[0]
+	}
+	return M{"$and": filters}
+}
+
+// condition converts a single condition
+func (t translator) condition(c *sqlblade.Condition) (M, error) {
+	switch c.Kind {
+	case sqlblade.GroupCondition:
+		filter, err := t.conditions(c.Children)
+		if err != nil || !c.Not {
+			return filter, err
+		}
+		return M{"$nor": []M{filter}}, nil
+	case sqlblade.RawCondition:
+		return nil, fmt.Errorf("%w: raw condition %s", ErrUnsupported, c.SQL)
+	}
+	if c.Subquery != "" {
+		return nil, fmt.Errorf("%w: subquery on %s", ErrUnsupported, c.Column)
+	}
+	if c.SQL != "" {
+		return nil, fmt.Errorf("%w: expression compared with %s", ErrUnsupported, c.Column)
+	}
+
+	field := t.field(c.Column)
+	if c.OtherColumn != "" {
+		op, ok := comparisons[c.Operator]
+		if !ok {
+			return nil, fmt.Errorf("%w: operator %s on columns", ErrUnsupported, c.Operator)
+		}
+		return M{"$expr": M{op: []interface{}{"$" + field, "$" + t.field(c.OtherColumn)}}}, nil
+	}
+
+	switch c.Operator {
+	case "IS NULL":
+		return M{field: M{"$eq": nil}}, nil
+	case "IS NOT NULL":
+		return M{field: M{"$ne": nil}}, nil
+	case "IN":
+		return M{field: M{"$in": values(c.Values)}}, nil
+	case "NOT IN":
+		return M{field: M{"$nin": values(c.Values)}}, nil
+	case "BETWEEN", "NOT BETWEEN":
+		if len(c.Values) != 2 {
+			return nil, fmt.Errorf("%w: %s on %s needs two values", ErrUnsupported, c.Operator, c.Column)
+		}
+		between := M{field: M{"$gte": c.Values[0], "$lte": c.Values[1]}}
+		if c.Operator == "NOT BETWEEN" {
+			return M{"$nor": []M{between}}, nil
+		}
+		return between, nil
+	case "LIKE", "NOT LIKE":
+		pattern, ok := singleString(c.Values)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s on %s needs a string pattern", ErrUnsupported, c.Operator, c.Column)
+		}
+		regex := M{"$regex": likeToRegex(pattern)}
+		if c.Operator == "NOT LIKE" {
+			return M{field: M{"$not": regex}}, nil
+		}
+		return M{field: regex}, nil
+	}
+
+	op, ok := comparisons[c.Operator]
+	if !ok || len(c.Values) != 1 {
+		return nil, fmt.Errorf("%w: operator %s on %s", ErrUnsupported, c.Operator, c.Column)
+	}
+	return M{field: M{op: c.Values[0]}}, nil
+}
+
+// comparisons maps SQL comparison operators to MongoDB ones
+var comparisons = map[string]string{
+	"=":  "$eq",
+	"!=": "$ne",
+	"<>": "$ne",
+	">":  "$gt",
+	">=": "$gte",
+	"<":  "$lt",
+	"<=": "$lte",
+}
+
+// values returns the values of an IN list, never nil
+func values(vals []interface{}) []interface{} {
+	if vals == nil {
+		return []interface{}{}
+	}
+	return vals
+}
+
+// singleString returns the only value of a condition when it is a string
+func singleString(vals []interface{}) (string, bool) {
+	if len(vals) != 1 {
+		return "", false
+	}
+	s, ok := vals[0].(string)
+	return s, ok
+}
+
+// likeToRegex converts a LIKE pattern into an anchored regular expression
+func likeToRegex(pattern string) string {
+	var buf strings.Builder
+	buf.WriteByte('^')
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			buf.WriteString(".*")
+		case '_':
+			buf.WriteByte('.')
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	buf.WriteByte('$')
+	return buf.String()
+}
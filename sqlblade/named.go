@@ -0,0 +1,190 @@
+package sqlblade
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// NamedParam is a value left open in a condition, bound by name later
+type NamedParam struct {
+	name string
+}
+
+// Param returns a parameter standing for a value bound later by name, so
+// that one fragment can be reused with different values:
+//
+//	byStatus := sqlblade.NewQueryFragment().
+//	    Where("status", "=", sqlblade.Param("status")).
+//	    Where("team_id", "IN", sqlblade.Param("teams"))
+//	sqlblade.Query[User](db).
+//	    Apply(byStatus).
+//	    Bind(sqlblade.Params{"status": "active", "teams": []int64{1, 2}}).
+//	    Execute(ctx)
+//
+// A parameter compared with IN, also as the only value of WhereIn, is bound
+// to the whole list. Parameters in the arguments of Expr values and ORDER BY
+// expressions and in the conditions of subqueries are bound too. Statements
+// executed with parameters left unbound fail with ErrUnboundParam.
+func Param(name string) NamedParam {
+	return NamedParam{name: name}
+}
+
+// Name returns the name of the parameter
+func (p NamedParam) Name() string {
+	return p.name
+}
+
+// MarshalJSON encodes the parameter as {"param": name}, the form fragments
+// decode back into a parameter
+func (p NamedParam) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"param": p.name})
+}
+
+// Params are the values of named parameters, by name
+type Params map[string]interface{}
+
+// Bind returns a copy of the fragment with the parameters of params replaced
+// by their values; the others stay open
+func (qf *QueryFragment) Bind(params Params) *QueryFragment {
+	bound := qf.Clone()
+	bound.whereClauses, _ = bindParamClauses(bound.whereClauses, params)
+	bound.having, _ = bindParamClauses(bound.having, params)
+	bound.orderBy, _ = bindOrderByParams(bound.orderBy, params)
+	return bound
+}
+
+// Bind binds named parameters of the conditions added so far and of the
+// fragments applied from then on
+func (qb *QueryBuilder[T]) Bind(params Params) *QueryBuilder[T] {
+	if qb.params == nil {
+		qb.params = make(Params, len(params))
+	}
+	for name, value := range params {
+		qb.params[name] = value
+	}
+	qb.whereClauses, _ = bindParamClauses(qb.whereClauses, params)
+	qb.having, _ = bindParamClauses(qb.having, params)
+	qb.orderBy, _ = bindOrderByParams(qb.orderBy, params)
+	return qb
+}
+
+// bindParamClauses returns the clauses with the parameters of params replaced by
+// their values, and whether any was replaced
+func bindParamClauses(clauses []WhereClause, params Params) ([]WhereClause, bool) {
+	var result []WhereClause
+	for i, clause := range clauses {
+		value := clause.Value
+		if list, ok := value.([]interface{}); ok && len(list) == 1 {
+			// WhereIn(column, Param("ids")) binds the whole list
+			if _, isParam := list[0].(NamedParam); isParam && isInOperator(clause.Operator) {
+				value = list[0]
+			}
+		}
+		value, changed := bindParamValue(value, params)
+		if !changed {
+			continue
+		}
+		if result == nil {
+			result = append([]WhereClause(nil), clauses...)
+		}
+		result[i].Value = value
+	}
+	if result == nil {
+		return clauses, false
+	}
+	return result, true
+}
+
+// bindParamValue returns a condition value with the parameters of params replaced
+// by their values, and whether any was replaced
+func bindParamValue(value interface{}, params Params) (interface{}, bool) {
+	switch v := value.(type) {
+	case NamedParam:
+		bound, ok := params[v.name]
+		if !ok {
+			return value, false
+		}
+		return bound, true
+	case []interface{}:
+		values, changed := bindParamValues(v, params)
+		return values, changed
+	case rawExpr:
+		args, changed := bindParamValues(v.args, params)
+		return rawExpr{sql: v.sql, args: args}, changed
+	case Expression:
+		args, changed := bindParamValues(v.args, params)
+		return Expression{sql: v.sql, args: args}, changed
+	case *Subquery:
+		if v.bind != nil {
+			if bound, changed := v.bind(params); changed {
+				return bound, true
+			}
+		}
+	case *ConditionGroup:
+		clauses, changed := bindParamClauses(v.clauses, params)
+		if !changed {
+			return value, false
+		}
+		return &ConditionGroup{clauses: clauses, not: v.not}, true
+	}
+	return value, false
+}
+
+// bindParamValues returns values with the parameters of params replaced by their
+// values, and whether any was replaced
+func bindParamValues(values []interface{}, params Params) ([]interface{}, bool) {
+	var result []interface{}
+	for i, value := range values {
+		bound, changed := bindParamValue(value, params)
+		if !changed {
+			continue
+		}
+		if result == nil {
+			result = append([]interface{}(nil), values...)
+		}
+		result[i] = bound
+	}
+	if result == nil {
+		return values, false
+	}
+	return result, true
+}
+
+// bindOrderByParams returns the ORDER BY items with the parameters of raw
+// expressions replaced by their values, and whether any was replaced
+func bindOrderByParams(orderBy []dialect.OrderBy, params Params) ([]dialect.OrderBy, bool) {
+	var result []dialect.OrderBy
+	for i, ob := range orderBy {
+		args, changed := bindParamValues(ob.Args, params)
+		if !changed {
+			continue
+		}
+		if result == nil {
+			result = append([]dialect.OrderBy(nil), orderBy...)
+		}
+		result[i].Args = args
+	}
+	if result == nil {
+		return orderBy, false
+	}
+	return result, true
+}
+
+// checkNamedParams returns ErrUnboundParam for a parameter left in args
+func checkNamedParams(args []interface{}) error {
+	for _, arg := range args {
+		if param, ok := arg.(NamedParam); ok {
+			return fmt.Errorf("%w: %s", ErrUnboundParam, param.name)
+		}
+	}
+	return nil
+}
+
+// isInOperator reports whether operator is IN or NOT IN
+func isInOperator(operator string) bool {
+	op := strings.ToUpper(strings.TrimSpace(operator))
+	return op == "IN" || op == "NOT IN"
+}
@@ -0,0 +1,50 @@
+package sqlblade_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+func TestBindNestedParams(t *testing.T) {
+	db, _ := openRecorder(t)
+	client := sqlblade.Open(db, sqlblade.WithDialect(dialect.NewPostgreSQL()))
+	ctx, capture := sqlblade.WithCapture(context.Background())
+
+	admins := sqlblade.Query[txUser](client).Select("id").Where("name", "=", sqlblade.Param("name"))
+	_, err := sqlblade.Query[txUser](client).
+		Where("id", "IN", sqlblade.NewSubquery(admins)).
+		Where("id", ">", sqlblade.Expr("? + 1", sqlblade.Param("min"))).
+		OrderByExpr(sqlblade.Expr("abs(id - ?)", sqlblade.Param("near")), dialect.ASC).
+		Bind(sqlblade.Params{"name": "admin", "min": 10, "near": 20}).
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	statements := capture.Statements()
+	if len(statements) != 1 {
+		t.Fatalf("expected 1 statement, got %d", len(statements))
+	}
+	if want := []interface{}{"admin", 10, 20}; !reflect.DeepEqual(statements[0].Args, want) {
+		t.Errorf("args = %v, want %v", statements[0].Args, want)
+	}
+}
+
+func TestUnboundSubqueryParam(t *testing.T) {
+	db, _ := openRecorder(t)
+	client := sqlblade.Open(db, sqlblade.WithDialect(dialect.NewPostgreSQL()))
+
+	admins := sqlblade.Query[txUser](client).Select("id").Where("name", "=", sqlblade.Param("name"))
+	_, err := sqlblade.Query[txUser](client).
+		Where("id", "IN", sqlblade.NewSubquery(admins)).
+		Bind(sqlblade.Params{"other": 1}).
+		Execute(context.Background())
+	if !errors.Is(err, sqlblade.ErrUnboundParam) {
+		t.Errorf("err = %v, want ErrUnboundParam", err)
+	}
+}
@@ -0,0 +1,195 @@
+package sqlblade
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+)
+
+// Null is a value that may be NULL. It can be used as a model field, a WHERE
+// value or a SET value, and scans NULL columns without pointer fields.
+type Null[T any] struct {
+	V     T
+	Valid bool
+}
+
+// NullOf returns a valid Null holding v
+func NullOf[T any](v T) Null[T] {
+	return Null[T]{V: v, Valid: true}
+}
+
+// NullValue returns an invalid Null, written as SQL NULL
+func NullValue[T any]() Null[T] {
+	return Null[T]{}
+}
+
+// Ptr returns a pointer to the value, or nil when it is NULL
+func (n Null[T]) Ptr() *T {
+	if !n.Valid {
+		return nil
+	}
+	v := n.V
+	return &v
+}
+
+// Value implements driver.Valuer
+func (n Null[T]) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return valueOf(n.V)
+}
+
+// Scan implements sql.Scanner
+func (n *Null[T]) Scan(src interface{}) error {
+	if src == nil {
+		var zero T
+		n.V, n.Valid = zero, false
+		return nil
+	}
+	if err := scanInto(&n.V, src); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding NULL as null
+func (n Null[T]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.V)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding null as NULL
+func (n *Null[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		var zero T
+		n.V, n.Valid = zero, false
+		return nil
+	}
+	if err := json.Unmarshal(data, &n.V); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Omit is a value that is left out of INSERT statements and Set/SetModel
+// updates while unset, so the column keeps its default or current value.
+// Combined with Null it gives tri-state semantics: Omit[Null[T]]{} leaves the
+// column unchanged, OmitOf(NullValue[T]()) sets it to NULL.
+type Omit[T any] struct {
+	V   T
+	Set bool
+}
+
+// OmitOf returns an Omit that is set to v
+func OmitOf[T any](v T) Omit[T] {
+	return Omit[T]{V: v, Set: true}
+}
+
+// Value implements driver.Valuer
+func (o Omit[T]) Value() (driver.Value, error) {
+	return valueOf(o.V)
+}
+
+// valueOf converts a wrapped value to a driver value, using its
+// driver.Valuer implementation when it or a pointer to it has one
+func valueOf(v interface{}) (driver.Value, error) {
+	v, _ = bindValue(v)
+	if valuer, ok := v.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+// Scan implements sql.Scanner
+func (o *Omit[T]) Scan(src interface{}) error {
+	if err := scanInto(&o.V, src); err != nil {
+		return err
+	}
+	o.Set = true
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (o Omit[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.V)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. A decoded value is always set,
+// so keys missing from a JSON object leave the column untouched.
+func (o *Omit[T]) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &o.V); err != nil {
+		return err
+	}
+	o.Set = true
+	return nil
+}
+
+func (o Omit[T]) omitted() bool {
+	return !o.Set
+}
+
+func (n Null[T]) valueType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (o Omit[T]) valueType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// valueTyper is implemented by the Null and Omit wrappers
+type valueTyper interface {
+	valueType() reflect.Type
+}
+
+// baseType returns the type stored in a column field, unwrapping pointers and
+// Null/Omit wrappers
+func baseType(typ reflect.Type) reflect.Type {
+	for {
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+			continue
+		}
+		if wrapper, ok := reflect.Zero(typ).Interface().(valueTyper); ok {
+			typ = wrapper.valueType()
+			continue
+		}
+		return typ
+	}
+}
+
+// omitter is implemented by Omit values
+type omitter interface {
+	omitted() bool
+}
+
+// isOmitted reports whether a value is an unset Omit
+func isOmitted(value interface{}) bool {
+	o, ok := value.(omitter)
+	return ok && o.omitted()
+}
+
+// scanInto stores a scanned database value into dest
+func scanInto(dest interface{}, src interface{}) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+
+	field := reflect.ValueOf(dest).Elem()
+	if src == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	fieldType := field.Type()
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return setFieldValue(field, src, fieldType)
+}
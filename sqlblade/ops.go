@@ -0,0 +1,114 @@
+package sqlblade
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TableBloatStats reports the dead tuples and size of a PostgreSQL table
+type TableBloatStats struct {
+	Schema      string     `db:"schema_name"`
+	Table       string     `db:"table_name"`
+	LiveTuples  int64      `db:"live_tuples"`
+	DeadTuples  int64      `db:"dead_tuples"`
+	DeadRatio   float64    `db:"dead_ratio"`  // dead / (live + dead) tuples
+	TableBytes  int64      `db:"table_bytes"` // heap only
+	TotalBytes  int64      `db:"total_bytes"` // including indexes and TOAST
+	LastVacuum  *time.Time `db:"last_vacuum"` // manual or autovacuum, whichever is later
+	LastAnalyze *time.Time `db:"last_analyze"`
+}
+
+// IndexUsageStats reports how often a PostgreSQL index is used
+type IndexUsageStats struct {
+	Schema        string `db:"schema_name"`
+	Table         string `db:"table_name"`
+	Index         string `db:"index_name"`
+	Scans         int64  `db:"scans"`
+	TuplesRead    int64  `db:"tuples_read"`
+	TuplesFetched int64  `db:"tuples_fetched"`
+	Bytes         int64  `db:"index_bytes"`
+	Unique        bool   `db:"is_unique"`
+}
+
+// RunningQuery is a statement running on a PostgreSQL server
+type RunningQuery struct {
+	PID             int        `db:"pid"`
+	User            *string    `db:"user_name"`
+	Database        *string    `db:"database_name"`
+	Application     string     `db:"application_name"`
+	ClientAddr      *string    `db:"client_addr"`
+	State           *string    `db:"state"`
+	WaitEventType   *string    `db:"wait_event_type"`
+	WaitEvent       *string    `db:"wait_event"`
+	QueryStart      *time.Time `db:"query_start"`
+	DurationSeconds float64    `db:"duration_seconds"`
+	Query           string     `db:"query"`
+}
+
+// Duration returns how long the statement has been running
+func (q RunningQuery) Duration() time.Duration {
+	return time.Duration(q.DurationSeconds * float64(time.Second))
+}
+
+const tableBloatSQL = `SELECT schemaname AS schema_name, relname AS table_name,
+	n_live_tup AS live_tuples, n_dead_tup AS dead_tuples,
+	CASE WHEN n_live_tup + n_dead_tup = 0 THEN 0
+		ELSE n_dead_tup::float8 / (n_live_tup + n_dead_tup) END AS dead_ratio,
+	pg_relation_size(relid) AS table_bytes, pg_total_relation_size(relid) AS total_bytes,
+	GREATEST(last_vacuum, last_autovacuum) AS last_vacuum,
+	GREATEST(last_analyze, last_autoanalyze) AS last_analyze
+FROM pg_stat_user_tables
+ORDER BY n_dead_tup DESC, relname`
+
+const indexUsageSQL = `SELECT s.schemaname AS schema_name, s.relname AS table_name, s.indexrelname AS index_name,
+	s.idx_scan AS scans, s.idx_tup_read AS tuples_read, s.idx_tup_fetch AS tuples_fetched,
+	pg_relation_size(s.indexrelid) AS index_bytes, i.indisunique AS is_unique
+FROM pg_stat_user_indexes s
+JOIN pg_index i ON i.indexrelid = s.indexrelid
+ORDER BY s.idx_scan, index_bytes DESC, s.indexrelname`
+
+const longRunningQueriesSQL = `SELECT pid, usename AS user_name, datname AS database_name,
+	application_name, client_addr::text AS client_addr, state,
+	wait_event_type, wait_event, query_start,
+	EXTRACT(EPOCH FROM now() - query_start)::float8 AS duration_seconds, query
+FROM pg_stat_activity
+WHERE state <> 'idle' AND pid <> pg_backend_pid()
+	AND query_start <= now() - make_interval(secs => $1)
+ORDER BY query_start`
+
+// TableBloat returns the dead-tuple statistics of the user tables, most dead
+// tuples first, for spotting tables that need vacuuming. PostgreSQL only.
+func (c *Client) TableBloat(ctx context.Context) ([]TableBloatStats, error) {
+	if err := c.requirePostgres("TableBloat"); err != nil {
+		return nil, err
+	}
+	return Raw[TableBloatStats](c, tableBloatSQL).Execute(ctx)
+}
+
+// IndexUsage returns the usage statistics of the user indexes, least scanned
+// first, for spotting unused indexes. PostgreSQL only.
+func (c *Client) IndexUsage(ctx context.Context) ([]IndexUsageStats, error) {
+	if err := c.requirePostgres("IndexUsage"); err != nil {
+		return nil, err
+	}
+	return Raw[IndexUsageStats](c, indexUsageSQL).Execute(ctx)
+}
+
+// LongRunningQueries returns the statements that have been running for at
+// least minDuration, oldest first, excluding the calling connection.
+// PostgreSQL only.
+func (c *Client) LongRunningQueries(ctx context.Context, minDuration time.Duration) ([]RunningQuery, error) {
+	if err := c.requirePostgres("LongRunningQueries"); err != nil {
+		return nil, err
+	}
+	return Raw[RunningQuery](c, longRunningQueriesSQL, minDuration.Seconds()).Execute(ctx)
+}
+
+// requirePostgres returns ErrDialectUnsupported unless the client uses PostgreSQL
+func (c *Client) requirePostgres(helper string) error {
+	if c.dialect.Name() != dialectPostgres {
+		return fmt.Errorf("%w: %s requires PostgreSQL, not %s", ErrDialectUnsupported, helper, c.dialect.Name())
+	}
+	return nil
+}
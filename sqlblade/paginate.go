@@ -0,0 +1,141 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// paginateTotalColumn is the window function column holding the total row count
+const paginateTotalColumn = "sqlblade_total"
+
+// Page is a page of query results with pagination metadata
+type Page[T any] struct {
+	Items      []T
+	Total      int64
+	Page       int
+	PerPage    int
+	TotalPages int
+	HasNext    bool
+	HasPrev    bool
+}
+
+// Paginate returns the given page (starting at 1) of the query results,
+// together with the total number of rows. On PostgreSQL the total is computed
+// with COUNT(*) OVER() in the same round-trip; elsewhere, or when the page is
+// past the end, a separate COUNT query is run. The builder's own LIMIT and
+// OFFSET are ignored.
+func (qb *QueryBuilder[T]) Paginate(ctx context.Context, page, perPage int) (*Page[T], error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+	if perPage <= 0 {
+		return nil, fmt.Errorf("%w: perPage must be positive", ErrInvalidPage)
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	prevLimit, prevOffset := qb.limit, qb.offset
+	defer func() { qb.limit, qb.offset = prevLimit, prevOffset }()
+
+	limit, offset := perPage, (page-1)*perPage
+	qb.limit, qb.offset = &limit, &offset
+
+	result := &Page[T]{Page: page, PerPage: perPage}
+	total := int64(-1)
+
+	var err error
+	if qb.dialect.Name() == dialectPostgres && !qb.distinct {
+		result.Items, total, err = qb.executeWithTotal(ctx)
+	} else {
+		result.Items, err = qb.Execute(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if total < 0 {
+		qb.limit, qb.offset = nil, nil
+		if total, err = qb.countAll(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Total = total
+	result.TotalPages = int((total + int64(perPage) - 1) / int64(perPage))
+	result.HasNext = page < result.TotalPages
+	result.HasPrev = page > 1
+	return result, nil
+}
+
+// executeWithTotal executes the query with a COUNT(*) OVER() column and returns
+// the rows and the total, or -1 when no row was returned
+func (qb *QueryBuilder[T]) executeWithTotal(ctx context.Context) ([]T, int64, error) {
+	sqlStr, args := qb.buildSelectSQL("COUNT(*) OVER() AS " + qb.dialect.QuoteIdentifier(paginateTotalColumn))
+
+	items := make([]T, 0, *qb.limit)
+	total := int64(-1)
+	err := qb.query(ctx, sqlStr, args, func(rows *sql.Rows) error {
+		rs, err := newRowScanner[T](rows, qb.client.timeLocation(ctx))
+		if err != nil {
+			return err
+		}
+		defer rs.release()
+
+		for rows.Next() {
+			var val T
+			if err := rs.scan(&val); err != nil {
+				return err
+			}
+			if total < 0 {
+				if v, ok := rs.column(paginateTotalColumn); ok {
+					if total, err = toInt64(v); err != nil {
+						return err
+					}
+				}
+			}
+			items = append(items, val)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return applyScanHooks(ctx, items)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// countAll counts the rows of the query, wrapping it in a subquery so that
+// GROUP BY and DISTINCT queries are counted correctly
+func (qb *QueryBuilder[T]) countAll(ctx context.Context) (int64, error) {
+	prevOrderBy := qb.orderBy
+	qb.orderBy = nil
+	innerSQL, args := qb.buildSQL()
+	qb.orderBy = prevOrderBy
+
+	sqlStr := "SELECT COUNT(*) FROM (" + innerSQL + ") AS " + qb.dialect.QuoteIdentifier("sqlblade_count")
+
+	var total int64
+	err := qb.query(ctx, sqlStr, args, func(rows *sql.Rows) error {
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return err
+			}
+			return ErrNoRows
+		}
+		return rows.Scan(&total)
+	})
+	return total, err
+}
+
+// toInt64 converts a scanned integer value
+func toInt64(v interface{}) (int64, error) {
+	var n int64
+	if err := scanInto(&n, v); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
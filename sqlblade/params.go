@@ -0,0 +1,103 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// maxBindParams returns the bind parameter limit of a dialect, 0 when unknown
+func maxBindParams(d dialect.Dialect) int {
+	if d == nil {
+		return 0
+	}
+	return dialect.CapabilitiesOf(d).MaxBindParams
+}
+
+// checkBindParams returns ErrTooManyParams when a statement has more bind
+// parameters than the dialect allows
+func checkBindParams(d dialect.Dialect, n int) error {
+	if limit := maxBindParams(d); limit > 0 && n > limit {
+		return fmt.Errorf("%w: %d parameters, %s allows %d", ErrTooManyParams, n, d.Name(), limit)
+	}
+	return nil
+}
+
+// chunkSize returns the number of rows per statement that keeps a batch
+// insert within the dialect's bind parameter limit, or 0 when the rows fit in
+// one statement
+func (ib *InsertBuilder[T]) chunkSize() (int, error) {
+	limit := maxBindParams(ib.dialect)
+	if limit == 0 || len(ib.values) < 2 {
+		return 0, nil
+	}
+
+	typ := reflect.TypeOf(ib.values[0])
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	info, err := getStructInfo(typ)
+	if err != nil {
+		return 0, err
+	}
+
+	columns := len(ib.resolveColumns(info))
+	if columns == 0 || columns*len(ib.values) <= limit || columns > limit {
+		return 0, nil
+	}
+	return limit / columns, nil
+}
+
+// inChunks calls fn with a builder for each chunk of rows. The chunks are
+// inserted in one transaction, unless the builder already runs in one.
+func (ib *InsertBuilder[T]) inChunks(ctx context.Context, size int, fn func(chunk *InsertBuilder[T]) error) error {
+	run := func(tx *sql.Tx) error {
+		for start := 0; start < len(ib.values); start += size {
+			end := min(start+size, len(ib.values))
+			chunk := *ib
+			chunk.values = ib.values[start:end:end]
+			chunk.tx = tx
+			if err := fn(&chunk); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if ib.tx != nil {
+		return run(ib.tx)
+	}
+	return ib.client.WithTransaction(ctx, func(tx *Tx) error {
+		return run(tx.tx)
+	})
+}
+
+// batchResult is the combined sql.Result of a chunked batch insert
+type batchResult struct {
+	results []sql.Result
+}
+
+// LastInsertId returns the id of the first chunk, as drivers return the id of
+// the first row of a multi-row insert
+func (r *batchResult) LastInsertId() (int64, error) {
+	if len(r.results) == 0 {
+		return 0, nil
+	}
+	return r.results[0].LastInsertId()
+}
+
+// RowsAffected returns the rows affected by all chunks
+func (r *batchResult) RowsAffected() (int64, error) {
+	var total int64
+	for _, result := range r.results {
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
@@ -0,0 +1,121 @@
+package sqlblade
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UpdateFromPatch creates an UPDATE builder from a JSON patch, e.g. the body of
+// a PATCH request. Keys are matched against the model's columns by column name
+// or by the json tag of the field; unknown keys and primary key columns are
+// rejected with ErrInvalidColumn. Each value is decoded into the field's type,
+// so malformed values, and nulls for fields that cannot hold NULL, are reported
+// with ErrInvalidPatchValue before any SQL is run.
+//
+//	ub, err := sqlblade.UpdateFromPatch[User](db, patch)
+//	if err != nil {
+//	    return err
+//	}
+//	_, err = ub.Where("id", "=", id).Execute(ctx)
+func UpdateFromPatch[T any, C Conn](db C, patch map[string]json.RawMessage) (*UpdateBuilder[T], error) {
+	ub := Update[T](db)
+	if err := ub.applyPatch(patch); err != nil {
+		return nil, err
+	}
+	return ub, nil
+}
+
+// UpdateFromPatchTx is like UpdateFromPatch but runs within a transaction
+func UpdateFromPatchTx[T any, X TxConn](tx X, patch map[string]json.RawMessage) (*UpdateBuilder[T], error) {
+	ub := UpdateTx[T](tx)
+	if err := ub.applyPatch(patch); err != nil {
+		return nil, err
+	}
+	return ub, nil
+}
+
+// applyPatch validates a JSON patch and turns its entries into SET values
+func (ub *UpdateBuilder[T]) applyPatch(patch map[string]json.RawMessage) error {
+	if len(patch) == 0 {
+		return ErrEmptySet
+	}
+
+	typ := modelType[T]()
+	meta, err := metadataOf(typ)
+	if err != nil {
+		return err
+	}
+	columns := patchColumns(typ, meta)
+
+	// Sort the keys so errors are reported deterministically
+	keys := make([]string, 0, len(patch))
+	for key := range patch {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		col, ok := columns[key]
+		if !ok || meta.IsPrimaryKey(col.Name) {
+			return fmt.Errorf("%w: %s", ErrInvalidColumn, key)
+		}
+
+		if isJSONNull(patch[key]) && !nullableType(col.Type) {
+			return fmt.Errorf("%w: %s: null is not allowed", ErrInvalidPatchValue, key)
+		}
+
+		value := reflect.New(col.Type)
+		if err := json.Unmarshal(patch[key], value.Interface()); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidPatchValue, key, err)
+		}
+		if hasTagOption(col.Options, "json") {
+			ub.Set(col.Name, JSON(value.Elem().Interface()))
+		} else {
+			ub.Set(col.Name, value.Elem().Interface())
+		}
+	}
+
+	return nil
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// nullableType reports whether a JSON null can be stored in a field of the type
+func nullableType(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	return reflect.PointerTo(typ).Implements(jsonUnmarshalerType)
+}
+
+func isJSONNull(data json.RawMessage) bool {
+	return strings.TrimSpace(string(data)) == "null"
+}
+
+// patchColumns indexes the columns of a model by column name and json name
+func patchColumns(typ reflect.Type, meta *ModelMeta) map[string]ColumnMeta {
+	columns := make(map[string]ColumnMeta, len(meta.Columns)*2)
+	for _, col := range meta.Columns {
+		columns[col.Name] = col
+	}
+
+	for _, col := range meta.Columns {
+		field, ok := typ.FieldByName(col.Field)
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if _, exists := columns[name]; !exists {
+			columns[name] = col
+		}
+	}
+
+	return columns
+}
@@ -76,3 +76,9 @@ func (tnc *tableNameCache) set(structTypeName, tableName string) {
 	defer tnc.mu.Unlock()
 	tnc.cache[structTypeName] = tableName
 }
+
+func (tnc *tableNameCache) delete(structTypeName string) {
+	tnc.mu.Lock()
+	defer tnc.mu.Unlock()
+	delete(tnc.cache, structTypeName)
+}
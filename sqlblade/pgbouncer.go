@@ -0,0 +1,63 @@
+package sqlblade
+
+import (
+	"log"
+	"strings"
+)
+
+// WithPgBouncerCompat disables server-side prepared statements for the client.
+// Transaction-pooling proxies such as PgBouncer hand each transaction a
+// different server connection, so statements prepared on one connection do
+// not exist on the next. Statements are sent unprepared instead of going
+// through the prepared statement cache.
+func WithPgBouncerCompat() ClientOption {
+	return func(c *Client) {
+		c.noPrepare.Store(true)
+	}
+}
+
+// PreparedStatementsDisabled reports whether the client sends statements
+// unprepared, either because of WithPgBouncerCompat or because a pooling
+// proxy was detected
+func (c *Client) PreparedStatementsDisabled() bool {
+	return c.noPrepare.Load()
+}
+
+// cachedStmts returns the prepared statement cache to use for a statement, or
+// nil when the statement should be sent unprepared
+func (c *Client) cachedStmts(useStmtCache bool) *stmtCache {
+	if !useStmtCache || c.noPrepare.Load() {
+		return nil
+	}
+	sc := stmtCacheFor(c.db)
+	if sc == nil || sc.disabled.Load() {
+		return nil
+	}
+	return sc
+}
+
+// disablePreparedStatements switches the client and the statement cache of its
+// database to unprepared statements after a pooling proxy was detected
+func (c *Client) disablePreparedStatements(sc *stmtCache, cause error) {
+	c.noPrepare.Store(true)
+	if sc.disabled.CompareAndSwap(false, true) {
+		log.Printf("sqlblade: prepared statement error, disabling the statement cache (transaction-pooling proxy?): %v", cause)
+		sc.clear()
+	}
+}
+
+// isPreparedStatementError reports whether an error indicates that prepared
+// statements don't survive between calls, the symptom of running behind a
+// transaction-pooling proxy: the statement is missing on the server
+// connection (SQLSTATE 26000) or its name is already taken (SQLSTATE 42P05)
+func isPreparedStatementError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	if strings.Contains(msg, "26000") || strings.Contains(msg, "42P05") {
+		return true
+	}
+	return strings.Contains(msg, "prepared statement") &&
+		(strings.Contains(msg, "does not exist") || strings.Contains(msg, "already exists"))
+}
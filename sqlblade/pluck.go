@@ -0,0 +1,57 @@
+package sqlblade
+
+import (
+	"context"
+)
+
+// Pluck executes the query selecting only column and returns its values,
+// e.g. Pluck[string](ctx, sqlblade.Query[User](db).Where("active", "=", true), "email").
+// V is any type the driver can scan the column into; NULL values need a
+// pointer or sql.Null type. Scan hooks of T are not run.
+func Pluck[V any, T any](ctx context.Context, qb *QueryBuilder[T], column string) ([]V, error) {
+	var values []V
+	if err := qb.selectOnly(column).ScanInto(ctx, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// PluckString executes the query selecting only column and returns its
+// values as strings, see Pluck
+func (qb *QueryBuilder[T]) PluckString(ctx context.Context, column string) ([]string, error) {
+	return Pluck[string](ctx, qb, column)
+}
+
+// PluckInt64 executes the query selecting only column and returns its values
+// as int64s, see Pluck
+func (qb *QueryBuilder[T]) PluckInt64(ctx context.Context, column string) ([]int64, error) {
+	return Pluck[int64](ctx, qb, column)
+}
+
+// Value executes the query selecting only column of the first row and
+// returns its value, normalized as by ExecuteMaps, or ErrNoRows when no row
+// matches
+func (qb *QueryBuilder[T]) Value(ctx context.Context, column string) (interface{}, error) {
+	rows, err := qb.selectOnly(column).Limit(1).ExecuteMaps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrNoRows
+	}
+	for _, value := range rows[0] {
+		return value, nil
+	}
+	return nil, nil
+}
+
+// selectOnly returns a copy of the query selecting only column. Queries
+// combined with Union and the like keep their own columns.
+func (qb *QueryBuilder[T]) selectOnly(column string) *QueryBuilder[T] {
+	clone := *qb
+	clone.selectCols = []string{column}
+	clone.selectMapped = false
+	clone.selectExprs = nil
+	clone.windows = nil
+	return &clone
+}
@@ -0,0 +1,210 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Statement describes a statement passed through the middleware chain
+type Statement struct {
+	Operation string // SELECT, INSERT, UPDATE, DELETE, RAW, MAINTAIN
+	Table     string
+	SQL       string
+	Args      []interface{}
+	Primary   bool     // must run on the primary: writes, raw SQL, transactions, ForcePrimary
+	Tags      []string // set with the builders' Tag, e.g. "feature:checkout"
+}
+
+// Middleware wraps the execution of a statement. It may inspect or rewrite the
+// statement before calling next, and observe the error next returns.
+type Middleware func(ctx context.Context, stmt *Statement, next func(ctx context.Context) error) error
+
+// Plugin packages a reusable extension such as tracing, caching or auditing
+type Plugin interface {
+	// Init is called once when the plugin is registered with a client
+	Init(client *Client) error
+	// Middleware returns the middleware to install, or nil
+	Middleware() Middleware
+	// Hooks returns the hooks to install, or nil
+	Hooks() *Hooks
+}
+
+// Use registers plugins with the client. Plugins should be registered before
+// the client is used concurrently; middleware runs in registration order.
+func (c *Client) Use(plugins ...Plugin) error {
+	if c.hooks == nil {
+		c.hooks = NewHooks()
+	}
+	for _, p := range plugins {
+		if err := p.Init(c); err != nil {
+			return fmt.Errorf("sqlblade: plugin init failed: %w", err)
+		}
+		if mw := p.Middleware(); mw != nil {
+			c.middleware = append(c.middleware, mw)
+		}
+		if hooks := p.Hooks(); hooks != nil {
+			c.hooks.beforeQuery = append(c.hooks.beforeQuery, hooks.beforeQuery...)
+			c.hooks.afterQuery = append(c.hooks.afterQuery, hooks.afterQuery...)
+			c.hooks.afterQueryWithError = append(c.hooks.afterQueryWithError, hooks.afterQueryWithError...)
+		}
+		c.plugins = append(c.plugins, p)
+	}
+	return nil
+}
+
+// UseMiddleware installs middleware directly, without a plugin
+func (c *Client) UseMiddleware(mw ...Middleware) {
+	c.middleware = append(c.middleware, mw...)
+}
+
+// Plugins returns the plugins registered with the client
+func (c *Client) Plugins() []Plugin {
+	return c.plugins
+}
+
+// execute runs fn through the client's middleware chain
+func (c *Client) execute(ctx context.Context, stmt *Statement, fn func(ctx context.Context) error) error {
+	if c != nil && c.readOnly.Load() && isWriteOperation(stmt.Operation) {
+		return fmt.Errorf("%w: %s %s", ErrReadOnly, stmt.Operation, stmt.Table)
+	}
+	if c != nil {
+		if err := checkBindParams(c.dialect, len(stmt.Args)); err != nil {
+			return err
+		}
+		if err := checkNamedParams(stmt.Args); err != nil {
+			return err
+		}
+		if err := c.checkIdentifiers(stmt.SQL); err != nil {
+			return err
+		}
+	}
+
+	if capture := captureFromContext(ctx); capture != nil {
+		run := fn
+		fn = func(ctx context.Context) error {
+			capture.record(stmt)
+			return run(ctx)
+		}
+	}
+	if c != nil && c.routingHints {
+		run := fn
+		fn = func(ctx context.Context) error {
+			stmt.SQL = routingMarker(ctx, stmt) + stmt.SQL
+			return run(ctx)
+		}
+	}
+	if len(stmt.Tags) > 0 {
+		// Runs before the routing marker is prepended, which stays first
+		run, comment := fn, tagComment(stmt.Tags)
+		fn = func(ctx context.Context) error {
+			if !strings.Contains(stmt.SQL, comment) {
+				stmt.SQL = comment + stmt.SQL
+			}
+			return run(ctx)
+		}
+	}
+
+	if c == nil || len(c.middleware) == 0 {
+		return fn(ctx)
+	}
+
+	handler := fn
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		mw := c.middleware[i]
+		next := handler
+		handler = func(ctx context.Context) error {
+			return mw(ctx, stmt, next)
+		}
+	}
+	return handler(ctx)
+}
+
+// executeBeforeHooks runs the global hooks followed by the client's hooks. Clients
+// opened WithHooks only run their own hooks.
+func (c *Client) executeBeforeHooks(ctx context.Context, event *HookEvent) error {
+	if c == nil || !c.scopedHooks {
+		if err := DefaultHooks.ExecuteBeforeHooks(ctx, event); err != nil {
+			return err
+		}
+	}
+	if c == nil || c.hooks == nil {
+		return nil
+	}
+	return c.hooks.ExecuteBeforeHooks(ctx, event)
+}
+
+// executeAfterHooks runs the global hooks followed by the client's hooks.
+// Errors are logged, as the statement has already been executed.
+func (c *Client) executeAfterHooks(ctx context.Context, event *HookEvent) {
+	var err error
+	if c == nil || !c.scopedHooks {
+		err = DefaultHooks.ExecuteAfterHooks(ctx, event)
+	}
+	if err == nil && c != nil && c.hooks != nil {
+		err = c.hooks.ExecuteAfterHooks(ctx, event)
+	}
+	if err != nil {
+		log.Printf("after query hook error: %v", err)
+	}
+}
+
+// executeHooked runs a statement through the hooks, the query debugger and
+// execute. The sql.Result returned by fn, if any, gives the affected rows.
+func (c *Client) executeHooked(ctx context.Context, stmt *Statement, fn func(ctx context.Context, event *HookEvent) (sql.Result, error)) error {
+	event := c.newHookEvent(stmt)
+	startTime := event.StartTime
+
+	if err := c.executeBeforeHooks(ctx, event); err != nil {
+		return err
+	}
+
+	var result sql.Result
+	if debugger := c.queryDebugger(); debugger.enabled.Load() {
+		debugQuery := &DebugQuery{
+			SQL:       stmt.SQL,
+			Args:      stmt.Args,
+			Tags:      stmt.Tags,
+			Table:     stmt.Table,
+			Operation: stmt.Operation,
+			Timestamp: startTime,
+		}
+		defer func() {
+			debugQuery.Duration = c.since(startTime)
+			if result != nil {
+				if rowsAffected, err := result.RowsAffected(); err == nil {
+					debugQuery.RowsAffected = rowsAffected
+				}
+			}
+			debugger.Log(debugQuery)
+		}()
+	}
+
+	err := c.execute(ctx, stmt, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx, event)
+		return err
+	})
+
+	event.finish(err, result)
+	c.executeAfterHooks(ctx, event)
+	return err
+}
+
+// queryHooked runs a statement returning rows through executeHooked, passing
+// its rows to scan
+func (c *Client) queryHooked(ctx context.Context, tx *sql.Tx, stmt *Statement, scan func(rows *sql.Rows) error) error {
+	return c.executeHooked(ctx, stmt, func(ctx context.Context, event *HookEvent) (sql.Result, error) {
+		rows, err := c.queryContext(ctx, tx, true, stmt.SQL, stmt.Args)
+		if err != nil {
+			return nil, wrapQueryError(err, stmt.SQL, stmt.Args)
+		}
+		defer closeRows(rows)
+
+		scanStart := event.clock.Now()
+		defer func() { event.ScanDuration = c.since(scanStart) }()
+		return nil, scan(rows)
+	})
+}
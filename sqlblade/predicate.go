@@ -0,0 +1,245 @@
+package sqlblade
+
+import "github.com/alicanli1995/sqlblade/sqlblade/dialect"
+
+// Predicate is a condition built apart from any query, that can be stored,
+// combined with others and attached to queries, updates and deletes, e.g. to
+// keep the filters of a domain in one tested place:
+//
+//	var Visible = sqlblade.P.And(
+//	    sqlblade.P.Eq("status", "published"),
+//	    sqlblade.P.Or(sqlblade.P.IsNull("deleted_at"), sqlblade.P.Gt("restored_at", since)),
+//	)
+//	sqlblade.Query[Post](db).WherePred(Visible).Where("author_id", "=", id)
+//
+// Predicates are immutable; combining them returns new ones. The zero
+// Predicate and P.And() match all rows, P.Or() none.
+type Predicate struct {
+	clause WhereClause
+}
+
+// predicates builds predicates, see P
+type predicates struct{}
+
+// P builds predicates: P.Eq("a", 1), P.Or(P.Gt("b", 2), P.IsNull("c"))
+var P predicates
+
+// Cmp matches rows where column compares to value with operator, as Where does
+func (predicates) Cmp(column, operator string, value interface{}) Predicate {
+	return Predicate{clause: WhereClause{Column: column, Operator: operator, Value: value}}
+}
+
+// Eq matches rows where column = value
+func (predicates) Eq(column string, value interface{}) Predicate {
+	return P.Cmp(column, "=", value)
+}
+
+// Ne matches rows where column != value
+func (predicates) Ne(column string, value interface{}) Predicate {
+	return P.Cmp(column, "!=", value)
+}
+
+// Gt matches rows where column > value
+func (predicates) Gt(column string, value interface{}) Predicate {
+	return P.Cmp(column, ">", value)
+}
+
+// Gte matches rows where column >= value
+func (predicates) Gte(column string, value interface{}) Predicate {
+	return P.Cmp(column, ">=", value)
+}
+
+// Lt matches rows where column < value
+func (predicates) Lt(column string, value interface{}) Predicate {
+	return P.Cmp(column, "<", value)
+}
+
+// Lte matches rows where column <= value
+func (predicates) Lte(column string, value interface{}) Predicate {
+	return P.Cmp(column, "<=", value)
+}
+
+// Like matches rows where column LIKE pattern
+func (predicates) Like(column string, pattern string) Predicate {
+	return P.Cmp(column, "LIKE", pattern)
+}
+
+// In matches rows where column is one of values; an empty list matches no
+// rows. A single typed slice, e.g. []int64, is used as the list.
+func (predicates) In(column string, values ...interface{}) Predicate {
+	return P.Cmp(column, "IN", inList(values))
+}
+
+// NotIn matches rows where column is none of values
+func (predicates) NotIn(column string, values ...interface{}) Predicate {
+	return P.Cmp(column, "NOT IN", inList(values))
+}
+
+// Between matches rows where column is between low and high, inclusive
+func (predicates) Between(column string, low, high interface{}) Predicate {
+	return P.Cmp(column, "BETWEEN", []interface{}{low, high})
+}
+
+// IsNull matches rows where column is NULL
+func (predicates) IsNull(column string) Predicate {
+	return P.Cmp(column, "IS NULL", nil)
+}
+
+// IsNotNull matches rows where column is not NULL
+func (predicates) IsNotNull(column string) Predicate {
+	return P.Cmp(column, "IS NOT NULL", nil)
+}
+
+// Column matches rows where column compares to otherColumn with operator
+func (predicates) Column(column, operator, otherColumn string) Predicate {
+	return P.Cmp(column, operator, ColumnRef(otherColumn))
+}
+
+// Raw matches rows by a raw SQL predicate, using "?" as placeholder for args
+func (predicates) Raw(sql string, args ...interface{}) Predicate {
+	return Predicate{clause: WhereClause{Value: rawExpr{sql: sql, args: args}}}
+}
+
+// And matches rows matching all of preds
+func (predicates) And(preds ...Predicate) Predicate {
+	return combine(preds, true)
+}
+
+// Or matches rows matching any of preds
+func (predicates) Or(preds ...Predicate) Predicate {
+	if len(preds) == 0 {
+		return P.Not(P.And())
+	}
+	return combine(preds, false)
+}
+
+// Not matches rows not matching pred
+func (predicates) Not(pred Predicate) Predicate {
+	group := &ConditionGroup{clauses: []WhereClause{pred.where(true)}, not: true}
+	return Predicate{clause: WhereClause{Value: group}}
+}
+
+// And matches rows matching p and all of others
+func (p Predicate) And(others ...Predicate) Predicate {
+	return P.And(append([]Predicate{p}, others...)...)
+}
+
+// Or matches rows matching p or any of others
+func (p Predicate) Or(others ...Predicate) Predicate {
+	return P.Or(append([]Predicate{p}, others...)...)
+}
+
+// Not matches rows not matching p
+func (p Predicate) Not() Predicate {
+	return P.Not(p)
+}
+
+// SQL renders the predicate for a dialect, without the WHERE keyword, e.g.
+// to test a filter library
+func (p Predicate) SQL(d dialect.Dialect) (string, []interface{}) {
+	paramIndex := 0
+	var args []interface{}
+	return buildConditions(d, []WhereClause{p.where(true)}, &paramIndex, &args), args
+}
+
+// where returns the clause of the predicate joined with AND or OR
+func (p Predicate) where(and bool) WhereClause {
+	clause := p.clause
+	if clause.Value == nil && clause.Column == "" && clause.Operator == "" {
+		// The zero Predicate, an empty group
+		clause.Value = &ConditionGroup{}
+	}
+	clause.And = and
+	return clause
+}
+
+// combine returns the group of preds joined with AND or OR
+func combine(preds []Predicate, and bool) Predicate {
+	clauses := make([]WhereClause, len(preds))
+	for i, pred := range preds {
+		clauses[i] = pred.where(and)
+	}
+	return Predicate{clause: WhereClause{Value: &ConditionGroup{clauses: clauses}}}
+}
+
+// inList returns the IN list of variadic values, or the slice given alone
+func inList(values []interface{}) interface{} {
+	if len(values) == 1 {
+		if _, ok := inValues(values[0]); ok {
+			return values[0]
+		}
+	}
+	return values
+}
+
+// WherePred adds a predicate (AND)
+func (qb *QueryBuilder[T]) WherePred(pred Predicate) *QueryBuilder[T] {
+	qb.whereClauses = append(qb.whereClauses, pred.where(true))
+	return qb
+}
+
+// OrWherePred adds a predicate (OR)
+func (qb *QueryBuilder[T]) OrWherePred(pred Predicate) *QueryBuilder[T] {
+	qb.whereClauses = append(qb.whereClauses, pred.where(false))
+	return qb
+}
+
+// WhereAll adds a group of predicates that must all match (AND)
+func (qb *QueryBuilder[T]) WhereAll(preds ...Predicate) *QueryBuilder[T] {
+	return qb.WherePred(P.And(preds...))
+}
+
+// WhereAny adds a group of predicates of which one must match (AND)
+func (qb *QueryBuilder[T]) WhereAny(preds ...Predicate) *QueryBuilder[T] {
+	return qb.WherePred(P.Or(preds...))
+}
+
+// WherePred adds a predicate (AND)
+func (ub *UpdateBuilder[T]) WherePred(pred Predicate) *UpdateBuilder[T] {
+	ub.whereClauses = append(ub.whereClauses, pred.where(true))
+	return ub
+}
+
+// OrWherePred adds a predicate (OR)
+func (ub *UpdateBuilder[T]) OrWherePred(pred Predicate) *UpdateBuilder[T] {
+	ub.whereClauses = append(ub.whereClauses, pred.where(false))
+	return ub
+}
+
+// WhereAll adds a group of predicates that must all match (AND)
+func (ub *UpdateBuilder[T]) WhereAll(preds ...Predicate) *UpdateBuilder[T] {
+	return ub.WherePred(P.And(preds...))
+}
+
+// WhereAny adds a group of predicates of which one must match (AND)
+func (ub *UpdateBuilder[T]) WhereAny(preds ...Predicate) *UpdateBuilder[T] {
+	return ub.WherePred(P.Or(preds...))
+}
+
+// WherePred adds a predicate (AND)
+func (db *DeleteBuilder[T]) WherePred(pred Predicate) *DeleteBuilder[T] {
+	db.whereClauses = append(db.whereClauses, pred.where(true))
+	return db
+}
+
+// OrWherePred adds a predicate (OR)
+func (db *DeleteBuilder[T]) OrWherePred(pred Predicate) *DeleteBuilder[T] {
+	db.whereClauses = append(db.whereClauses, pred.where(false))
+	return db
+}
+
+// WhereAll adds a group of predicates that must all match (AND)
+func (db *DeleteBuilder[T]) WhereAll(preds ...Predicate) *DeleteBuilder[T] {
+	return db.WherePred(P.And(preds...))
+}
+
+// WhereAny adds a group of predicates of which one must match (AND)
+func (db *DeleteBuilder[T]) WhereAny(preds ...Predicate) *DeleteBuilder[T] {
+	return db.WherePred(P.Or(preds...))
+}
+
+// WherePred adds a predicate to the group (AND)
+func (g *ConditionGroup) WherePred(pred Predicate) *ConditionGroup {
+	g.clauses = append(g.clauses, pred.where(true))
+	return g
+}
@@ -64,6 +64,7 @@ func (qp *QueryPreview[T]) PrettyPrint() {
 		Args:      args,
 		Table:     qp.builder.tableName,
 		Operation: "SELECT",
+		Tags:      qp.builder.tags,
 	}
 
 	fmt.Print(formatQuery(debugQuery))
@@ -80,7 +81,7 @@ type QueryFragment struct {
 	joins        []dialect.Join
 	orderBy      []dialect.OrderBy
 	selectCols   []string
-	groupBy      []string
+	groupBy      []groupByExpr
 	having       []WhereClause
 	distinct     bool
 	limit        *int
@@ -94,7 +95,7 @@ func NewQueryFragment() *QueryFragment {
 		joins:        make([]dialect.Join, 0),
 		orderBy:      make([]dialect.OrderBy, 0),
 		selectCols:   make([]string, 0),
-		groupBy:      make([]string, 0),
+		groupBy:      make([]groupByExpr, 0),
 		having:       make([]WhereClause, 0),
 	}
 }
@@ -121,6 +122,20 @@ func (qf *QueryFragment) OrWhere(column string, operator string, value interface
 	return qf
 }
 
+// WhereColumn adds a WHERE condition comparing two columns to the fragment
+func (qf *QueryFragment) WhereColumn(column string, operator string, otherColumn string) *QueryFragment {
+	return qf.Where(column, operator, ColumnRef(otherColumn))
+}
+
+// WhereRaw adds a raw WHERE predicate to the fragment
+func (qf *QueryFragment) WhereRaw(sql string, args ...interface{}) *QueryFragment {
+	qf.whereClauses = append(qf.whereClauses, WhereClause{
+		Value: rawExpr{sql: sql, args: args},
+		And:   true,
+	})
+	return qf
+}
+
 // Join adds a JOIN to the fragment
 func (qf *QueryFragment) Join(table string, condition string) *QueryFragment {
 	qf.joins = append(qf.joins, dialect.Join{
@@ -158,7 +173,24 @@ func (qf *QueryFragment) Select(columns ...string) *QueryFragment {
 
 // GroupBy adds a GROUP BY clause
 func (qf *QueryFragment) GroupBy(columns ...string) *QueryFragment {
-	qf.groupBy = append(qf.groupBy, columns...)
+	for _, col := range columns {
+		qf.groupBy = append(qf.groupBy, groupByExpr{expr: col})
+	}
+	return qf
+}
+
+// GroupByRaw adds a raw GROUP BY expression
+func (qf *QueryFragment) GroupByRaw(expr string) *QueryFragment {
+	qf.groupBy = append(qf.groupBy, groupByExpr{expr: expr, raw: true})
+	return qf
+}
+
+// OrderByRaw adds a raw ORDER BY expression to the fragment
+func (qf *QueryFragment) OrderByRaw(expr string) *QueryFragment {
+	qf.orderBy = append(qf.orderBy, dialect.OrderBy{
+		Column: expr,
+		Raw:    true,
+	})
 	return qf
 }
 
@@ -193,6 +225,10 @@ func (qf *QueryFragment) Offset(offset int) *QueryFragment {
 
 // Apply applies the fragment to a query builder (method on QueryBuilder)
 func (qb *QueryBuilder[T]) Apply(qf *QueryFragment) *QueryBuilder[T] {
+	if qb.params != nil {
+		qf = qf.Bind(qb.params)
+	}
+
 	// Apply where clauses
 	qb.whereClauses = append(qb.whereClauses, qf.whereClauses...)
 
@@ -239,15 +275,62 @@ func (qb *QueryBuilder[T]) Apply(qf *QueryFragment) *QueryBuilder[T] {
 type Subquery struct {
 	sql  string
 	args []interface{}
+
+	// write renders the subquery into a statement, numbering its placeholders
+	// on from paramIndex; shape returns its SQL cache key and arguments; bind
+	// returns a copy with named parameters bound, and whether any was
+	write func(buf *strings.Builder, paramIndex *int, args *[]interface{})
+	shape func() (string, []interface{})
+	bind  func(params Params) (*Subquery, bool)
 }
 
-// NewSubquery creates a new subquery from a QueryBuilder
+// NewSubquery creates a new subquery from a QueryBuilder. Later changes to
+// the builder don't affect the subquery.
 func NewSubquery[T any](qb *QueryBuilder[T]) *Subquery {
 	sql, args := qb.buildSQL()
+	frozen := *qb
 	return &Subquery{
 		sql:  sql,
 		args: args,
+		write: func(buf *strings.Builder, paramIndex *int, args *[]interface{}) {
+			frozen.writeSelect(buf, "", paramIndex, args)
+		},
+		shape: func() (string, []interface{}) {
+			return frozen.shape("")
+		},
+		bind: func(params Params) (*Subquery, bool) {
+			bound := frozen
+			var where, having, orderBy bool
+			bound.whereClauses, where = bindParamClauses(frozen.whereClauses, params)
+			bound.having, having = bindParamClauses(frozen.having, params)
+			bound.orderBy, orderBy = bindOrderByParams(frozen.orderBy, params)
+			if !where && !having && !orderBy {
+				return nil, false
+			}
+			return NewSubquery(&bound), true
+		},
+	}
+}
+
+// shapeKey returns the SQL cache key of the subquery and its arguments
+func (sq *Subquery) shapeKey() (string, []interface{}) {
+	if sq.shape != nil {
+		return sq.shape()
 	}
+	return sq.sql, sq.args
+}
+
+// writeTo writes the parenthesized subquery into a statement, numbering its
+// placeholders on from paramIndex
+func (sq *Subquery) writeTo(buf *strings.Builder, paramIndex *int, args *[]interface{}) {
+	buf.WriteString("(")
+	if sq.write != nil {
+		sq.write(buf, paramIndex, args)
+	} else {
+		buf.WriteString(sq.sql)
+		*args = append(*args, sq.args...)
+	}
+	buf.WriteString(")")
 }
 
 // SQL returns the SQL of the subquery
@@ -273,6 +356,20 @@ func (qb *QueryBuilder[T]) WhereSubquery(column string, operator string, subquer
 	return qb
 }
 
+// WhereExists adds a WHERE EXISTS condition on a subquery, typically
+// correlated with the outer query through Cor:
+//
+//	sqlblade.Query[User](db).As("u").WhereExists(sqlblade.NewSubquery(
+//	    sqlblade.Query[Order](db).Where("orders.user_id", "=", sqlblade.Cor("u.id"))))
+func (qb *QueryBuilder[T]) WhereExists(subquery *Subquery) *QueryBuilder[T] {
+	return qb.WhereSubquery("", "EXISTS", subquery)
+}
+
+// WhereNotExists adds a WHERE NOT EXISTS condition on a subquery
+func (qb *QueryBuilder[T]) WhereNotExists(subquery *Subquery) *QueryBuilder[T] {
+	return qb.WhereSubquery("", "NOT EXISTS", subquery)
+}
+
 // OrWhereSubquery adds an OR WHERE condition using a subquery
 func (qb *QueryBuilder[T]) OrWhereSubquery(column string, operator string, subquery *Subquery) *QueryBuilder[T] {
 	qb.whereClauses = append(qb.whereClauses, WhereClause{
@@ -0,0 +1,42 @@
+package sqlblade
+
+import "context"
+
+// Priority tags the statements of a context. It is used by the Scheduler, the
+// ConcurrencyLimiter and the routing hints, so that batch jobs can't starve
+// interactive traffic.
+type Priority int
+
+const (
+	// PriorityLow marks non-essential statements, e.g. reports and batch jobs
+	PriorityLow Priority = -1
+	// PriorityNormal is the priority of untagged statements
+	PriorityNormal Priority = 0
+	// PriorityHigh marks statements that must run first, even during maintenance
+	PriorityHigh Priority = 1
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose statements have the given priority
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the priority of a context, PriorityNormal when untagged
+func PriorityFromContext(ctx context.Context) Priority {
+	p, _ := ctx.Value(priorityKey{}).(Priority)
+	return p
+}
+
+// String returns the name of the priority
+func (p Priority) String() string {
+	switch {
+	case p < PriorityNormal:
+		return "low"
+	case p > PriorityNormal:
+		return "high"
+	default:
+		return "normal"
+	}
+}
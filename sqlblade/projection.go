@@ -0,0 +1,91 @@
+package sqlblade
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// mappedColumns returns the columns mapped by the fields of T, qualified with
+// the table or its alias when the query has joins
+func (qb *QueryBuilder[T]) mappedColumns() []string {
+	info, err := getStructInfo(modelType[T]())
+	if err != nil {
+		return nil
+	}
+
+	qualifier := ""
+	if len(qb.joins) > 0 {
+		qualifier = qb.tableName + "."
+		if qb.alias != "" {
+			qualifier = qb.alias + "."
+		}
+	}
+
+	columns := make([]string, len(info.fields))
+	for i, field := range info.fields {
+		columns[i] = qualifier + field.dbColumn
+	}
+	return columns
+}
+
+// SelectFields selects the columns of the fields fn points to, so that
+// projections are checked by the compiler rather than spelled as strings:
+//
+//	sqlblade.Query[User](db).SelectFields(func(u *User) []interface{} {
+//	    return []interface{}{&u.ID, &u.Email}
+//	})
+//
+// The other fields of the results are left zero. It panics when a pointer is
+// not to a mapped field of the model passed to fn.
+func (qb *QueryBuilder[T]) SelectFields(fn func(model *T) []interface{}) *QueryBuilder[T] {
+	columns, err := fieldColumns(fn)
+	if err != nil {
+		panic(err)
+	}
+	return qb.Select(columns...)
+}
+
+// fieldColumns returns the columns of the fields of a model that fn points to
+func fieldColumns[T any](fn func(model *T) []interface{}) ([]string, error) {
+	info, err := getStructInfo(modelType[T]())
+	if err != nil {
+		return nil, err
+	}
+
+	model := new(T)
+	root := reflect.ValueOf(model).Elem()
+	if root.Kind() == reflect.Ptr {
+		return nil, fmt.Errorf("%w: SelectFields requires a struct model", ErrInvalidModel)
+	}
+
+	// Allocate the embedded pointers first, so that fn can point into them
+	addrs := make(map[uintptr]int, len(info.fields))
+	for i, field := range info.fields {
+		if v := field.settable(root); v.IsValid() && v.CanAddr() {
+			addrs[v.Addr().Pointer()] = i
+		}
+	}
+
+	ptrs := fn(model)
+	columns := make([]string, 0, len(ptrs))
+	for i, ptr := range ptrs {
+		v := reflect.ValueOf(ptr)
+		if v.Kind() != reflect.Ptr || v.IsNil() {
+			return nil, fmt.Errorf("%w: SelectFields argument %d is not a field pointer", ErrInvalidColumn, i)
+		}
+		idx, ok := addrs[v.Pointer()]
+		if !ok || info.fields[idx].fieldType != derefType(v.Type().Elem()) {
+			return nil, fmt.Errorf("%w: SelectFields argument %d does not point to a mapped field", ErrInvalidColumn, i)
+		}
+		columns = append(columns, info.fields[idx].dbColumn)
+	}
+	return columns, nil
+}
+
+// derefType returns the element type of pointer types
+func derefType(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Ptr {
+		return typ.Elem()
+	}
+	return typ
+}
@@ -0,0 +1,74 @@
+package sqlblade
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// protoColumn returns the column of a field of a message generated by
+// protoc-gen-go, the name given in its protobuf tag, e.g. user_id for
+// `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3"`. Fields without a
+// db tag are mapped this way, so that query results can be scanned straight
+// into response messages, e.g. ScanInto(ctx, &resp.Users).
+func protoColumn(field reflect.StructField) (string, bool) {
+	tag, ok := field.Tag.Lookup("protobuf")
+	if !ok {
+		return "", false
+	}
+	for _, part := range strings.Split(tag, ",") {
+		if name, ok := strings.CutPrefix(part, "name="); ok && name != "" {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// scanProtoValue sets a field of a protobuf well-known type from a scanned
+// value: a google.protobuf.Timestamp from a time, or a wrapper such as
+// google.protobuf.StringValue from its value. It reports whether the field
+// is of such a type.
+func scanProtoValue(field reflect.Value, value interface{}) (bool, error) {
+	typ := field.Type()
+	if typ.Kind() != reflect.Struct {
+		return false, nil
+	}
+
+	if typ.Name() == "Timestamp" {
+		seconds, nanos := protoField(typ, "Seconds", reflect.Int64), protoField(typ, "Nanos", reflect.Int32)
+		if seconds == nil || nanos == nil {
+			return false, nil
+		}
+		t, ok := value.(time.Time)
+		if !ok {
+			if err := setTimeField(reflect.ValueOf(&t).Elem(), reflect.ValueOf(value)); err != nil {
+				return true, err
+			}
+		}
+		field.FieldByIndex(seconds.Index).SetInt(t.Unix())
+		field.FieldByIndex(nanos.Index).SetInt(int64(t.Nanosecond()))
+		return true, nil
+	}
+
+	if strings.HasSuffix(typ.Name(), "Value") {
+		if inner, ok := typ.FieldByName("Value"); ok {
+			if _, tagged := inner.Tag.Lookup("protobuf"); tagged {
+				return true, setFieldValue(field.FieldByIndex(inner.Index), value, inner.Type)
+			}
+		}
+	}
+	return false, nil
+}
+
+// protoField returns the field of a protobuf message type with the given
+// name and kind, or nil
+func protoField(typ reflect.Type, name string, kind reflect.Kind) *reflect.StructField {
+	field, ok := typ.FieldByName(name)
+	if !ok || field.Type.Kind() != kind {
+		return nil
+	}
+	if _, tagged := field.Tag.Lookup("protobuf"); !tagged {
+		return nil
+	}
+	return &field
+}
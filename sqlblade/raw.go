@@ -3,7 +3,6 @@ package sqlblade
 import (
 	"context"
 	"database/sql"
-	"log"
 
 	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
 )
@@ -12,36 +11,32 @@ import (
 type RawQuery[T any] struct {
 	db      *sql.DB
 	tx      *sql.Tx
+	client  *Client
 	dialect dialect.Dialect
 	query   string
 	args    []interface{}
+	tags    []string
 }
 
 // Raw creates a new raw query builder
-func Raw[T any](db *sql.DB, query string, args ...interface{}) *RawQuery[T] {
-	if db == nil {
-		panic(ErrNilDB)
-	}
-
-	d := detectDialect(db.Driver())
+func Raw[T any, C Conn](db C, query string, args ...interface{}) *RawQuery[T] {
+	client := resolveConn(db)
 	return &RawQuery[T]{
-		db:      db,
-		dialect: d,
+		db:      client.db,
+		client:  client,
+		dialect: client.dialect,
 		query:   query,
 		args:    args,
 	}
 }
 
 // RawTx creates a new raw query builder with transaction
-func RawTx[T any](tx *sql.Tx, query string, args ...interface{}) *RawQuery[T] {
-	if tx == nil {
-		panic(ErrNilDB)
-	}
-
-	d := detectDialect(nil)
+func RawTx[T any, X TxConn](tx X, query string, args ...interface{}) *RawQuery[T] {
+	sqlTx, client := resolveTx(tx)
 	return &RawQuery[T]{
-		tx:      tx,
-		dialect: d,
+		tx:      sqlTx,
+		client:  client,
+		dialect: client.dialect,
 		query:   query,
 		args:    args,
 	}
@@ -53,26 +48,24 @@ func (rq *RawQuery[T]) Execute(ctx context.Context) ([]T, error) {
 		return nil, ErrNilContext
 	}
 
-	var rows *sql.Rows
-	var err error
-
-	if rq.tx != nil {
-		rows, err = rq.tx.QueryContext(ctx, rq.query, rq.args...)
-	} else {
-		rows, err = rq.db.QueryContext(ctx, rq.query, rq.args...)
-	}
-
+	var result []T
+	stmt := &Statement{Operation: "RAW", SQL: rq.query, Args: rq.args, Primary: true, Tags: rq.tags}
+	err := rq.client.queryHooked(ctx, rq.tx, stmt, func(rows *sql.Rows) error {
+		var err error
+		if maps, ok := any(&result).(*[]map[string]interface{}); ok {
+			*maps, err = scanMaps(rows, rq.client.timeLocation(ctx))
+			return err
+		}
+		result, err = scanRows[T](rows, rq.client.timeLocation(ctx))
+		return err
+	})
 	if err != nil {
-		return nil, wrapQueryError(err, rq.query, rq.args)
+		return nil, err
 	}
-	defer func(rows *sql.Rows) {
-		closeErr := rows.Close()
-		if closeErr != nil {
-			log.Printf("failed to close rows: %v", closeErr)
-		}
-	}(rows)
-
-	return scanRows[T](rows)
+	if err := applyScanHooks(ctx, result); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // First executes the raw query and returns the first result
@@ -95,16 +88,17 @@ func (rq *RawQuery[T]) Exec(ctx context.Context) (sql.Result, error) {
 	}
 
 	var result sql.Result
-	var err error
-
-	if rq.tx != nil {
-		result, err = rq.tx.ExecContext(ctx, rq.query, rq.args...)
-	} else {
-		result, err = rq.db.ExecContext(ctx, rq.query, rq.args...)
-	}
-
+	stmt := &Statement{Operation: "RAW", SQL: rq.query, Args: rq.args, Primary: true, Tags: rq.tags}
+	err := rq.client.executeHooked(ctx, stmt, func(ctx context.Context, _ *HookEvent) (sql.Result, error) {
+		var execErr error
+		result, execErr = rq.client.execContext(ctx, rq.tx, true, stmt.SQL, stmt.Args)
+		if execErr != nil {
+			return nil, wrapQueryError(execErr, stmt.SQL, stmt.Args)
+		}
+		return result, nil
+	})
 	if err != nil {
-		return nil, wrapQueryError(err, rq.query, rq.args)
+		return nil, err
 	}
 
 	return result, nil
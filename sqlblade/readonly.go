@@ -0,0 +1,62 @@
+package sqlblade
+
+import (
+	"context"
+	"fmt"
+)
+
+// SetReadOnly puts the client in read-only mode, e.g. during failovers and
+// maintenance windows. INSERT, UPDATE and DELETE statements and Maintain then
+// fail fast with ErrReadOnly instead of reaching the database; raw SQL is not
+// checked. It is safe to call while the client is in use.
+func (c *Client) SetReadOnly(readOnly bool) {
+	c.readOnly.Store(readOnly)
+}
+
+// ReadOnly reports whether the client is in read-only mode
+func (c *Client) ReadOnly() bool {
+	return c.readOnly.Load()
+}
+
+// DetectReadOnly asks the database whether it only accepts reads and sets the
+// client's read-only mode accordingly: pg_is_in_recovery() on PostgreSQL
+// (a standby), @@global.read_only on MySQL. Other dialects leave the mode
+// unchanged. It returns the resulting mode.
+func (c *Client) DetectReadOnly(ctx context.Context) (bool, error) {
+	if ctx == nil {
+		return false, ErrNilContext
+	}
+
+	var sqlStr string
+	switch c.dialect.Name() {
+	case dialectPostgres:
+		sqlStr = "SELECT pg_is_in_recovery()"
+	case dialectMySQL:
+		sqlStr = "SELECT @@global.read_only"
+	default:
+		return c.ReadOnly(), nil
+	}
+
+	var value interface{}
+	if err := c.db.QueryRowContext(ctx, sqlStr).Scan(&value); err != nil {
+		return false, wrapQueryError(err, sqlStr, nil)
+	}
+
+	var readOnly bool
+	if err := scanInto(&readOnly, value); err != nil {
+		return false, fmt.Errorf("sqlblade: unexpected read-only value %v: %w", value, err)
+	}
+
+	c.SetReadOnly(readOnly)
+	return readOnly, nil
+}
+
+// isWriteOperation reports whether statements of an operation modify data
+func isWriteOperation(operation string) bool {
+	switch operation {
+	case "INSERT", "UPDATE", "DELETE", "MAINTAIN":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,487 @@
+package sqlblade
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RowStatus is the outcome of a single row of an INSERT
+type RowStatus int
+
+const (
+	// RowInserted means the row was inserted
+	RowInserted RowStatus = iota
+	// RowUpdated means the row conflicted and the existing row was updated
+	RowUpdated
+	// RowUpserted means the row was inserted or updated, but the database does
+	// not report which (SQLite with DoUpdate)
+	RowUpserted
+	// RowSkipped means the row conflicted and was left out
+	RowSkipped
+	// RowFailed means inserting the row failed, see RowResult.Err
+	RowFailed
+)
+
+// String returns the name of the row status
+func (s RowStatus) String() string {
+	switch s {
+	case RowInserted:
+		return "inserted"
+	case RowUpdated:
+		return "updated"
+	case RowUpserted:
+		return "upserted"
+	case RowSkipped:
+		return "skipped"
+	case RowFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// RowResult is the outcome of the row at Index of the inserted values
+type RowResult struct {
+	Index  int
+	Status RowStatus
+	Err    error
+}
+
+// InsertReport holds the per-row outcomes of ExecuteReport
+type InsertReport struct {
+	Rows     []RowResult
+	Inserted int
+	Updated  int
+	Upserted int
+	Skipped  int
+	Failed   int
+}
+
+// ExecuteReport executes the INSERT and reports the outcome of every row, for
+// import pipelines that need per-record results.
+//
+// On PostgreSQL with a conflict target the rows are inserted in one statement
+// and matched back through RETURNING by their target values, using xmax to
+// tell inserts from updates. That needs target columns that are inserted
+// rather than generated, holding strings, integers, booleans or bytes whose
+// values come back unchanged; for others, e.g. times, the rows are reported
+// one at a time. Otherwise the rows are inserted one statement at a time and
+// the outcome is derived from the affected row count (MySQL reports 2 for an
+// updated row and 0 for a skipped one, unless the connection reports found
+// rather than changed rows), so a failing row is reported with its error
+// instead of failing the whole batch.
+// Inside a PostgreSQL transaction, a failing row aborts the transaction.
+// The returned error is only set when the report could not be produced.
+func (ib *InsertBuilder[T]) ExecuteReport(ctx context.Context) (*InsertReport, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+	if len(ib.values) == 0 {
+		return nil, ErrEmptySet
+	}
+
+	report := &InsertReport{Rows: make([]RowResult, len(ib.values))}
+	for i := range report.Rows {
+		report.Rows[i].Index = i
+	}
+
+	if ib.dialect.Name() == dialectPostgres && ib.conflict != nil && len(ib.conflict.target) > 0 && ib.reportKeysMatch() {
+		size, err := ib.chunkSize()
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			size = len(ib.values)
+		}
+		// Rows beyond the bind parameter limit are reported per chunk
+		for start := 0; start < len(ib.values); start += size {
+			end := min(start+size, len(ib.values))
+			chunk := *ib
+			chunk.values = ib.values[start:end:end]
+			if err := chunk.reportBatch(ctx, &InsertReport{Rows: report.Rows[start:end]}); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		ib.reportRows(ctx, report)
+	}
+
+	report.count()
+	return report, nil
+}
+
+// ContinueOnError inserts the rows in a transaction, or in the builder's
+// transaction, running each chunk of rows in a savepoint so that a bad row
+// doesn't abort the whole import. When a chunk fails it is rolled back and
+// its rows are inserted one at a time, each in its own savepoint; the rows
+// that still fail are reported with their error and left out. With
+// OnConflict, every row is inserted in its own savepoint to report its
+// outcome. The returned error is only set when the transaction itself fails.
+func (ib *InsertBuilder[T]) ContinueOnError(ctx context.Context) (*InsertReport, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+	if len(ib.values) == 0 {
+		return nil, ErrEmptySet
+	}
+
+	report := &InsertReport{Rows: make([]RowResult, len(ib.values))}
+	for i := range report.Rows {
+		report.Rows[i].Index = i
+	}
+
+	size, err := ib.chunkSize()
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		size = len(ib.values)
+	}
+
+	start := 0
+	err = ib.inChunks(ctx, size, func(chunk *InsertBuilder[T]) error {
+		tx := &Tx{tx: chunk.tx, client: ib.client}
+		rows := report.Rows[start : start+len(chunk.values)]
+		start += len(chunk.values)
+
+		if ib.conflict == nil {
+			err := tx.WithSavepoint(ctx, func(*Tx) error {
+				_, err := chunk.Execute(ctx)
+				return err
+			})
+			if err == nil {
+				for i := range rows {
+					rows[i].Status = RowInserted
+				}
+				return nil
+			}
+		}
+
+		for i := range chunk.values {
+			var status RowStatus
+			var rowErr error
+			err := tx.WithSavepoint(ctx, func(*Tx) error {
+				status, rowErr = chunk.reportRow(ctx, i)
+				return rowErr
+			})
+			switch {
+			case rowErr != nil:
+				rows[i].Status, rows[i].Err = RowFailed, rowErr
+			case err != nil:
+				return err
+			default:
+				rows[i].Status = status
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.count()
+	return report, nil
+}
+
+// FailedRows returns the results of the rows that failed
+func (r *InsertReport) FailedRows() []RowResult {
+	var failed []RowResult
+	for _, row := range r.Rows {
+		if row.Status == RowFailed {
+			failed = append(failed, row)
+		}
+	}
+	return failed
+}
+
+// count tallies the outcomes of the rows
+func (r *InsertReport) count() {
+	for _, row := range r.Rows {
+		switch row.Status {
+		case RowInserted:
+			r.Inserted++
+		case RowUpdated:
+			r.Updated++
+		case RowUpserted:
+			r.Upserted++
+		case RowSkipped:
+			r.Skipped++
+		case RowFailed:
+			r.Failed++
+		}
+	}
+}
+
+// reportBatch inserts all rows in one statement returning the conflict target
+// and whether each returned row was inserted; rows not returned were skipped
+func (ib *InsertBuilder[T]) reportBatch(ctx context.Context, report *InsertReport) error {
+	info, err := getStructInfo(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return err
+	}
+
+	target := ib.conflict.target
+	sqlStr, args := ib.buildInsertSQL(info, ib.resolveColumns(info), nil)
+	sqlStr += " RETURNING " + ib.quoteColumns(target) + ", (xmax = 0)"
+
+	keys := make(map[string][]int, len(ib.values))
+	for i := range ib.values {
+		key := conflictKey(info, reflect.ValueOf(ib.values[i]), target)
+		keys[key] = append(keys[key], i)
+		report.Rows[i].Status = RowSkipped
+	}
+
+	return ib.runReportStatement(ctx, sqlStr, args, func(ctx context.Context, stmt *Statement) error {
+		rows, err := ib.client.queryContext(ctx, ib.tx, true, stmt.SQL, stmt.Args)
+		if err != nil {
+			return wrapQueryError(err, stmt.SQL, stmt.Args)
+		}
+		defer closeRows(rows)
+
+		values := make([]interface{}, len(target))
+		var inserted bool
+		dest := make([]interface{}, len(target)+1)
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		dest[len(target)] = &inserted
+
+		for rows.Next() {
+			if err := rows.Scan(dest...); err != nil {
+				return fmt.Errorf("sqlblade: failed to scan row: %w", err)
+			}
+
+			indexes := keys[formatKey(values)]
+			if len(indexes) == 0 {
+				continue
+			}
+			status := RowUpdated
+			if inserted {
+				status = RowInserted
+			}
+			for _, i := range indexes {
+				report.Rows[i].Status = status
+			}
+		}
+		return rows.Err()
+	})
+}
+
+// reportKeysMatch reports whether the rows returned by reportBatch can be
+// matched back to the values by their conflict target: the target columns are
+// inserted, not left out for the database to generate, and hold values whose
+// formatted form the database returns unchanged
+func (ib *InsertBuilder[T]) reportKeysMatch() bool {
+	info, err := getStructInfo(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return false
+	}
+	inserted := ib.resolveColumns(info)
+	for _, col := range ib.conflict.target {
+		if !containsString(inserted, col) {
+			return false
+		}
+		matched := false
+		for _, field := range info.fields {
+			if field.dbColumn == col {
+				matched = exactKeyField(field)
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// exactKeyField reports whether the values of a field compare equal to the
+// values the database returns for it once formatted by formatKey
+func exactKeyField(field fieldInfo) bool {
+	if field.isJSON || field.codec != nil || field.isScanner {
+		return false
+	}
+	typ := field.fieldType
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.Slice:
+		return typ.Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+// reportRows inserts the rows one at a time, reporting each outcome
+func (ib *InsertBuilder[T]) reportRows(ctx context.Context, report *InsertReport) {
+	for i := range ib.values {
+		status, err := ib.reportRow(ctx, i)
+		if err != nil {
+			report.Rows[i].Status, report.Rows[i].Err = RowFailed, err
+			continue
+		}
+		report.Rows[i].Status = status
+	}
+}
+
+// reportRow inserts the row at index i, deriving its outcome from the
+// affected row count or, on PostgreSQL, from RETURNING (xmax = 0)
+func (ib *InsertBuilder[T]) reportRow(ctx context.Context, i int) (RowStatus, error) {
+	postgres := ib.dialect.Name() == dialectPostgres
+	upsert := ib.conflict != nil && ib.conflict.doUpdate
+
+	row := *ib
+	row.values = ib.values[i : i+1]
+	row.returning = nil
+
+	sqlStr, args, err := row.buildSQL(nil)
+	if err != nil {
+		return RowFailed, err
+	}
+
+	var status RowStatus
+	if postgres {
+		sqlStr += " RETURNING (xmax = 0)"
+		err = ib.runReportStatement(ctx, sqlStr, args, func(ctx context.Context, stmt *Statement) error {
+			rows, queryErr := ib.client.queryContext(ctx, ib.tx, true, stmt.SQL, stmt.Args)
+			if queryErr != nil {
+				return wrapQueryError(queryErr, stmt.SQL, stmt.Args)
+			}
+			defer closeRows(rows)
+
+			if !rows.Next() {
+				if rowsErr := rows.Err(); rowsErr != nil {
+					return wrapQueryError(rowsErr, stmt.SQL, stmt.Args)
+				}
+				status = RowSkipped
+				return nil
+			}
+			var inserted bool
+			if scanErr := rows.Scan(&inserted); scanErr != nil {
+				return wrapQueryError(scanErr, stmt.SQL, stmt.Args)
+			}
+			if inserted {
+				status = RowInserted
+			} else {
+				status = RowUpdated
+			}
+			return nil
+		})
+	} else {
+		err = ib.runReportStatement(ctx, sqlStr, args, func(ctx context.Context, stmt *Statement) error {
+			result, execErr := ib.client.execContext(ctx, ib.tx, true, stmt.SQL, stmt.Args)
+			if execErr != nil {
+				return wrapQueryError(execErr, stmt.SQL, stmt.Args)
+			}
+			affected, execErr := result.RowsAffected()
+			if execErr != nil {
+				return execErr
+			}
+			status = statusFromRowsAffected(affected, upsert, ib.dialect.Name())
+			return nil
+		})
+	}
+
+	if err != nil {
+		return RowFailed, err
+	}
+	return status, nil
+}
+
+// statusFromRowsAffected maps the affected row count of a single-row INSERT to its outcome
+func statusFromRowsAffected(affected int64, upsert bool, dialectName string) RowStatus {
+	switch {
+	case affected == 0:
+		return RowSkipped
+	case dialectName == dialectMySQL && affected == 2:
+		return RowUpdated
+	case upsert && dialectName != dialectMySQL:
+		return RowUpserted
+	default:
+		return RowInserted
+	}
+}
+
+// runReportStatement runs a statement of ExecuteReport through the hooks and middleware
+func (ib *InsertBuilder[T]) runReportStatement(ctx context.Context, sqlStr string, args []interface{}, fn func(ctx context.Context, stmt *Statement) error) error {
+	stmt := &Statement{Operation: "INSERT", Table: ib.tableName, SQL: sqlStr, Args: args, Primary: true, Tags: ib.tags}
+	event := ib.client.newHookEvent(stmt)
+	if err := ib.client.executeBeforeHooks(ctx, event); err != nil {
+		return err
+	}
+
+	err := ib.client.execute(ctx, stmt, func(ctx context.Context) error {
+		return fn(ctx, stmt)
+	})
+
+	event.finish(err, nil)
+	ib.client.executeAfterHooks(ctx, event)
+	return err
+}
+
+func (ib *InsertBuilder[T]) quoteColumns(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = ib.dialect.QuoteIdentifier(col)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// conflictKey returns the key of a value's conflict target columns
+func conflictKey(info *structInfo, val reflect.Value, target []string) string {
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	values := make([]interface{}, len(target))
+	for i, col := range target {
+		for _, field := range info.fields {
+			if field.dbColumn == col {
+				values[i] = keyValue(field.value(val))
+				break
+			}
+		}
+	}
+	return formatKey(values)
+}
+
+// keyValue returns a key field's value as its underlying type, so that named
+// types with a String method format as the database returns them
+func keyValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes()
+		}
+	case reflect.Invalid:
+		return nil
+	}
+	return v.Interface()
+}
+
+// formatKey formats key values so that database and Go representations of the
+// same value, e.g. []byte and string, produce the same key
+func formatKey(values []interface{}) string {
+	var buf strings.Builder
+	for i, v := range values {
+		if i > 0 {
+			buf.WriteByte(0)
+		}
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		fmt.Fprint(&buf, v)
+	}
+	return buf.String()
+}
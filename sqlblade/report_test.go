@@ -0,0 +1,59 @@
+package sqlblade_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+func TestExecuteReportMatchesByInsertedKeys(t *testing.T) {
+	tests := []struct {
+		name       string
+		users      []txUser
+		statements int
+	}{
+		{"inserted key", []txUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}, 1},
+		{"generated key", []txUser{{Name: "a"}, {Name: "b"}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := openRecorder(t)
+			client := sqlblade.Open(db, sqlblade.WithDialect(dialect.NewPostgreSQL()))
+
+			_, err := sqlblade.InsertBatch(client, tt.users).
+				OnConflict("id").DoNothing().
+				ExecuteReport(context.Background())
+			if err != nil {
+				t.Fatalf("ExecuteReport: %v", err)
+			}
+			if statements := rec.statements(); len(statements) != tt.statements {
+				t.Errorf("statements = %q, want %d", statements, tt.statements)
+			}
+		})
+	}
+}
+
+func TestMySQLDoNothingKeepsErrors(t *testing.T) {
+	db, _ := openRecorder(t)
+	client := sqlblade.Open(db, sqlblade.WithDialect(dialect.NewMySQL()))
+	ctx, capture := sqlblade.WithCapture(context.Background())
+
+	_, err := sqlblade.Insert(client, txUser{ID: 1, Name: "a"}).
+		OnConflict("id").DoNothing().
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	statements := capture.Statements()
+	if len(statements) != 1 {
+		t.Fatalf("expected 1 statement, got %d", len(statements))
+	}
+	got := statements[0].SQL
+	if strings.Contains(got, "IGNORE") || !strings.HasSuffix(got, " ON DUPLICATE KEY UPDATE `id` = `id`") {
+		t.Errorf("SQL = %q, want a no-op ON DUPLICATE KEY UPDATE", got)
+	}
+}
@@ -0,0 +1,243 @@
+package sqlblade
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheStore stores the encoded rows cached by a CachedRepository, e.g. in
+// memory or in Redis. A ttl of zero means no expiry.
+type CacheStore interface {
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// CachedRepository reads rows of T by primary key through a cache. Writes of
+// the client to the table of T (INSERT, UPDATE, DELETE) invalidate every
+// cached row of the table, so rows are never served stale after a write made
+// through the client; the invalidation is shared by the processes using the
+// same store. Raw statements and writes of other clients are not seen: call
+// Invalidate or InvalidateAll after them. Writes in a transaction invalidate
+// when they are executed, so a Find racing the commit may cache the previous
+// row until the ttl expires.
+type CachedRepository[T any] struct {
+	client *Client
+	store  CacheStore
+	ttl    time.Duration
+	table  string
+	pk     string
+
+	mu    sync.Mutex
+	calls map[string]*cacheCall[T]
+}
+
+// cacheCall is a database read shared by concurrent Finds of the same key
+type cacheCall[T any] struct {
+	done chan struct{}
+	row  T
+	err  error
+}
+
+// NewCachedRepository returns a repository caching the rows of T read by Find
+// in store for ttl, and installs the middleware invalidating them on writes.
+// T must have a single primary key column. Keep ttl above zero, as the rows
+// cached before an invalidation are only removed by expiring.
+func NewCachedRepository[T any](client *Client, store CacheStore, ttl time.Duration) (*CachedRepository[T], error) {
+	meta, err := metadataOf(modelType[T]())
+	if err != nil {
+		return nil, err
+	}
+	if len(meta.PrimaryKey) != 1 {
+		return nil, fmt.Errorf("%w: %s needs a single primary key column", ErrInvalidModel, meta.Table)
+	}
+
+	r := &CachedRepository[T]{
+		client: client,
+		store:  store,
+		ttl:    ttl,
+		table:  meta.Table,
+		pk:     meta.PrimaryKey[0],
+		calls:  make(map[string]*cacheCall[T]),
+	}
+	client.UseMiddleware(r.invalidateOnWrite)
+	return r, nil
+}
+
+// Find returns the row with the given primary key from the cache, reading it
+// from the database on a miss. Concurrent misses of the same row share one
+// query. Missing rows give ErrNoRows and are not cached; failing stores fall
+// back to the database.
+func (r *CachedRepository[T]) Find(ctx context.Context, pk interface{}) (T, error) {
+	var zero T
+	if ctx == nil {
+		return zero, ErrNilContext
+	}
+
+	key, err := r.key(ctx, pk)
+	if err != nil {
+		return Query[T](r.client).Where(r.pk, "=", pk).First(ctx)
+	}
+	if data, found, err := r.store.Get(ctx, key); err == nil && found {
+		var row T
+		if err := json.Unmarshal(data, &row); err == nil {
+			return row, nil
+		}
+	}
+
+	r.mu.Lock()
+	if call, ok := r.calls[key]; ok {
+		r.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.row, call.err
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+	call := &cacheCall[T]{done: make(chan struct{})}
+	r.calls[key] = call
+	r.mu.Unlock()
+
+	call.row, call.err = Query[T](r.client).Where(r.pk, "=", pk).First(ctx)
+	if call.err == nil {
+		if data, err := json.Marshal(call.row); err == nil {
+			if err := r.store.Set(ctx, key, data, r.ttl); err != nil {
+				log.Printf("sqlblade: caching %s %v: %v", r.table, pk, err)
+			}
+		}
+	}
+
+	r.mu.Lock()
+	delete(r.calls, key)
+	r.mu.Unlock()
+	close(call.done)
+	return call.row, call.err
+}
+
+// Invalidate drops the cached row with the given primary key
+func (r *CachedRepository[T]) Invalidate(ctx context.Context, pk interface{}) error {
+	key, err := r.key(ctx, pk)
+	if err != nil {
+		return err
+	}
+	return r.store.Delete(ctx, key)
+}
+
+// InvalidateAll drops every cached row of the table, by moving the cache to a
+// new generation of keys; the rows of the old one expire with their ttl
+func (r *CachedRepository[T]) InvalidateAll(ctx context.Context) error {
+	return r.store.Set(ctx, r.generationKey(), []byte(newGeneration()), 0)
+}
+
+// invalidateOnWrite is the middleware invalidating the cache after writes to
+// the table
+func (r *CachedRepository[T]) invalidateOnWrite(ctx context.Context, stmt *Statement, next func(ctx context.Context) error) error {
+	err := next(ctx)
+	if err == nil && stmt.Table == r.table && isWriteOperation(stmt.Operation) {
+		if invalidateErr := r.InvalidateAll(context.WithoutCancel(ctx)); invalidateErr != nil {
+			log.Printf("sqlblade: invalidating the cache of %s: %v", r.table, invalidateErr)
+		}
+	}
+	return err
+}
+
+// key returns the cache key of a row in the current generation
+func (r *CachedRepository[T]) key(ctx context.Context, pk interface{}) (string, error) {
+	generation, found, err := r.store.Get(ctx, r.generationKey())
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		generation = []byte(newGeneration())
+		if err := r.store.Set(ctx, r.generationKey(), generation, 0); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("sqlblade:%s:%s:%v", r.table, generation, pk), nil
+}
+
+// generationKey returns the key of the current generation of the table's keys
+func (r *CachedRepository[T]) generationKey() string {
+	return "sqlblade:" + r.table + ":generation"
+}
+
+var generationSeq atomic.Uint64
+
+// newGeneration returns a generation distinct from those of other processes
+// and of earlier calls
+func newGeneration() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + "." + strconv.FormatUint(generationSeq.Add(1), 36)
+}
+
+// MemoryCacheStore is a CacheStore keeping the values in process memory.
+// Expired values are dropped when they are read, and swept as the store grows.
+type MemoryCacheStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryCacheEntry
+	nextSweep int
+}
+
+// memoryCacheSweepSize is the number of entries from which a MemoryCacheStore
+// sweeps its expired values
+const memoryCacheSweepSize = 1024
+
+type memoryCacheEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCacheStore returns an empty in-memory cache store
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{entries: make(map[string]memoryCacheEntry)}
+}
+
+// Get implements CacheStore
+func (s *MemoryCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+// Set implements CacheStore
+func (s *MemoryCacheStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := memoryCacheEntry{value: value}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = entry
+	if len(s.entries) >= s.nextSweep {
+		now := time.Now()
+		for k, e := range s.entries {
+			if !e.expires.IsZero() && now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.nextSweep = max(2*len(s.entries), memoryCacheSweepSize)
+	}
+	return nil
+}
+
+// Delete implements CacheStore
+func (s *MemoryCacheStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.entries, key)
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,96 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// supportsReturning reports whether the dialect supports RETURNING clauses
+func supportsReturning(d dialect.Dialect) bool {
+	return dialect.CapabilitiesOf(d).Returning
+}
+
+// writeReturning writes the RETURNING clause for the columns, if the dialect supports it
+func writeReturning(buf *strings.Builder, d dialect.Dialect, columns []string) {
+	if len(columns) == 0 || !supportsReturning(d) {
+		return
+	}
+
+	buf.WriteString(" RETURNING ")
+	for i, col := range columns {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if col == "*" {
+			buf.WriteString(col)
+			continue
+		}
+		buf.WriteString(d.QuoteIdentifier(col))
+	}
+}
+
+// returningColumns returns the RETURNING columns of ExecuteReturning, all by default
+func returningColumns(columns []string) []string {
+	if len(columns) == 0 {
+		return []string{"*"}
+	}
+	return columns
+}
+
+// executeReturning runs a statement with a RETURNING clause and scans the returned rows into T
+func executeReturning[T any](ctx context.Context, c *Client, tx *sql.Tx, stmt *Statement) ([]T, error) {
+	sqlStr, args := stmt.SQL, stmt.Args
+	event := c.newHookEvent(stmt)
+	startTime := event.StartTime
+
+	if err := c.executeBeforeHooks(ctx, event); err != nil {
+		return nil, err
+	}
+
+	var result []T
+	if debugger := c.queryDebugger(); debugger.enabled.Load() {
+		debugQuery := &DebugQuery{
+			SQL:       sqlStr,
+			Args:      args,
+			Tags:      stmt.Tags,
+			Table:     stmt.Table,
+			Operation: stmt.Operation,
+			Timestamp: startTime,
+		}
+		defer func() {
+			debugQuery.Duration = c.since(startTime)
+			debugQuery.RowsAffected = int64(len(result))
+			debugger.Log(debugQuery)
+		}()
+	}
+
+	err := c.execute(ctx, stmt, func(ctx context.Context) error {
+		rows, err := c.queryContext(ctx, tx, true, stmt.SQL, stmt.Args)
+		if err != nil {
+			return wrapQueryError(err, stmt.SQL, stmt.Args)
+		}
+		defer closeRows(rows)
+
+		scanStart := event.clock.Now()
+		result, err = scanRowsOptimized[T](rows, c.timeLocation(ctx))
+		event.ScanDuration = c.since(scanStart)
+		return err
+	})
+
+	event.finish(err, nil)
+	if err == nil {
+		event.RowsAffected = int64(len(result))
+	}
+	c.executeAfterHooks(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyScanHooks(ctx, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
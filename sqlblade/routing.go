@@ -0,0 +1,43 @@
+package sqlblade
+
+import "context"
+
+const (
+	readOnlyMarker    = "/* read-only */ "
+	primaryOnlyMarker = "/* master-only */ "
+)
+
+type forcePrimaryKey struct{}
+
+// SetRoutingHints enables routing markers for the client's statements. Each
+// statement is prefixed with /* read-only */ or /* master-only */ so that
+// proxies such as ProxySQL can route it to a replica or the primary. SELECTs
+// are read-only unless they run in a transaction or are forced to the primary
+// with ForcePrimary or WithForcePrimary; all other statements are master-only.
+// SELECTs with PriorityLow (see WithPriority) ignore WithForcePrimary, so
+// batch jobs read from replicas.
+func (c *Client) SetRoutingHints(enabled bool) {
+	c.routingHints = enabled
+}
+
+// WithForcePrimary returns a context whose statements are all marked to run on the primary
+func WithForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcePrimaryKey{}, true)
+}
+
+func forcePrimaryFromContext(ctx context.Context) bool {
+	force, _ := ctx.Value(forcePrimaryKey{}).(bool)
+	return force
+}
+
+// routingMarker returns the routing marker for a statement. Low-priority
+// reads stay on replicas even when the context forces the primary.
+func routingMarker(ctx context.Context, stmt *Statement) string {
+	if stmt.Primary {
+		return primaryOnlyMarker
+	}
+	if forcePrimaryFromContext(ctx) && PriorityFromContext(ctx) >= PriorityNormal {
+		return primaryOnlyMarker
+	}
+	return readOnlyMarker
+}
@@ -0,0 +1,170 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var mapRowType = reflect.TypeOf(map[string]interface{}(nil))
+
+// ScanInto executes the query and scans its rows into dest, for ad-hoc
+// result shapes such as joins with computed columns that don't fit T:
+//
+//   - *[]S or *[]*S scans every row into a struct S, by db tags
+//   - *S scans the first row, or returns ErrNoRows
+//   - *[]map[string]interface{} and *map[string]interface{} key the values
+//     by column name, as returned by the driver
+//   - *[]V and *V for any other type scan a single column, e.g. *[]int64
+//
+// Scan hooks of T are not run.
+func (qb *QueryBuilder[T]) ScanInto(ctx context.Context, dest interface{}) error {
+	if ctx == nil {
+		return ErrNilContext
+	}
+
+	sqlStr, args := qb.buildSQL()
+	return qb.query(ctx, sqlStr, args, func(rows *sql.Rows) error {
+		return scanRowsInto(rows, dest, qb.client.timeLocation(ctx))
+	})
+}
+
+// ScanInto executes the raw query and scans its rows into dest, see
+// QueryBuilder.ScanInto
+func (rq *RawQuery[T]) ScanInto(ctx context.Context, dest interface{}) error {
+	if ctx == nil {
+		return ErrNilContext
+	}
+
+	stmt := &Statement{Operation: "RAW", SQL: rq.query, Args: rq.args, Primary: true, Tags: rq.tags}
+	return rq.client.queryHooked(ctx, rq.tx, stmt, func(rows *sql.Rows) error {
+		return scanRowsInto(rows, dest, rq.client.timeLocation(ctx))
+	})
+}
+
+// scanRowsInto scans rows into dest, see QueryBuilder.ScanInto
+func scanRowsInto(rows *sql.Rows, dest interface{}, loc *time.Location) error {
+	ptr := reflect.ValueOf(dest)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return fmt.Errorf("%w: %T is not a non-nil pointer", ErrInvalidDestination, dest)
+	}
+	target := ptr.Elem()
+	many := target.Kind() == reflect.Slice && target.Type().Elem().Kind() != reflect.Uint8
+	elemType := target.Type()
+	if many {
+		elemType = elemType.Elem()
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	scan, release, err := newDestScanner(rows, elemType, columns, loc)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if many {
+		result := reflect.MakeSlice(target.Type(), 0, resultInitialCapacity)
+		for rows.Next() {
+			elem := reflect.New(elemType).Elem()
+			if err := scan(elem); err != nil {
+				return err
+			}
+			result = reflect.Append(result, elem)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		target.Set(result)
+		return nil
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return ErrNoRows
+	}
+	return scan(target)
+}
+
+// newDestScanner returns a function scanning the current row into a value of
+// typ, with a function releasing its buffer
+func newDestScanner(rows *sql.Rows, typ reflect.Type, columns []string, loc *time.Location) (func(v reflect.Value) error, func(), error) {
+	structType := typ
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	isStruct := structType.Kind() == reflect.Struct && structType != timeType &&
+		!reflect.PointerTo(structType).Implements(scannerType)
+
+	if !isStruct && typ != mapRowType {
+		if len(columns) != 1 {
+			return nil, nil, fmt.Errorf("%w: %s needs a single column, got %d", ErrInvalidDestination, typ, len(columns))
+		}
+		scan := func(v reflect.Value) error {
+			if err := rows.Scan(v.Addr().Interface()); err != nil {
+				return fmt.Errorf("sqlblade: failed to scan row: %w", err)
+			}
+			return nil
+		}
+		return scan, func() {}, nil
+	}
+
+	buf := globalScanBufferPool.Get(len(columns))
+	release := func() { globalScanBufferPool.Put(buf) }
+
+	if typ == mapRowType {
+		scan := func(v reflect.Value) error {
+			if err := rows.Scan(buf.ptrs...); err != nil {
+				return fmt.Errorf("sqlblade: failed to scan row: %w", err)
+			}
+			row := make(map[string]interface{}, len(columns))
+			for i, col := range columns {
+				value := buf.values[i]
+				if t, ok := value.(time.Time); ok && loc != nil {
+					value = t.In(loc)
+				}
+				row[col] = value
+			}
+			v.Set(reflect.ValueOf(row))
+			return nil
+		}
+		return scan, release, nil
+	}
+
+	info, err := getStructInfo(structType)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	columnMap := columnMapCacheInst.getColumnMap(aliasedColumns(structType, info, columns))
+	var times []fieldInfo
+	if loc != nil {
+		times = timeFields(info)
+	}
+
+	scan := func(v reflect.Value) error {
+		if err := rows.Scan(buf.ptrs...); err != nil {
+			return fmt.Errorf("sqlblade: failed to scan row: %w", err)
+		}
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(structType))
+			}
+			v = v.Elem()
+		}
+		if err := setFields(v, info, columnMap, buf.values); err != nil {
+			return err
+		}
+		if len(times) > 0 {
+			localizeTimes(v, times, loc)
+		}
+		return nil
+	}
+	return scan, release, nil
+}
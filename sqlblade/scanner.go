@@ -4,8 +4,10 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 // structInfo caches reflection information for structs
@@ -18,9 +20,71 @@ type structInfo struct {
 type fieldInfo struct {
 	name      string
 	dbColumn  string
-	index     int
+	index     []int // field index path, through embedded and prefixed structs
 	isPtr     bool
 	fieldType reflect.Type
+	options   []string
+	isScanner bool  // *fieldType implements sql.Scanner
+	isJSON    bool  // stored as a JSON document (json tag option)
+	codec     Codec // registered with RegisterCodec or RegisterColumnCodec
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// value returns the field of the struct v, or an invalid Value when a nil
+// embedded pointer is on its path
+func (f fieldInfo) value(v reflect.Value) reflect.Value {
+	if len(f.index) == 1 {
+		return v.Field(f.index[0])
+	}
+	field, err := v.FieldByIndexErr(f.index)
+	if err != nil {
+		return reflect.Value{}
+	}
+	return field
+}
+
+// interfaceOf returns the value of the field of the struct v, nil when a nil
+// embedded pointer is on its path
+func (f fieldInfo) interfaceOf(v reflect.Value) interface{} {
+	field := f.value(v)
+	if !field.IsValid() {
+		return nil
+	}
+	return field.Interface()
+}
+
+// settable returns the field of the struct v for setting, allocating nil
+// embedded pointers on its path
+func (f fieldInfo) settable(v reflect.Value) reflect.Value {
+	for i, x := range f.index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// hasOption reports whether the field's db tag has the given option
+func (f fieldInfo) hasOption(option string) bool {
+	return hasTagOption(f.options, option)
+}
+
+// hasTagOption reports whether the options of a db tag include option
+func hasTagOption(options []string, option string) bool {
+	for _, opt := range options {
+		if opt == option {
+			return true
+		}
+	}
+	return false
 }
 
 var structCache sync.Map // map[reflect.Type]*structInfo
@@ -43,12 +107,12 @@ func getStructInfo(typ reflect.Type) (*structInfo, error) {
 		return cachedInfo, nil
 	}
 
-	info := &structInfo{
-		fields: make([]fieldInfo, 0),
-	}
+	info := &structInfo{}
 
 	structTypeName := typ.String()
-	if cachedTableName, ok := globalTableNameCache.get(structTypeName); ok {
+	if meta := registeredModel(typ); meta != nil && meta.Table != "" {
+		info.tableName = meta.Table
+	} else if cachedTableName, ok := globalTableNameCache.get(structTypeName); ok {
 		info.tableName = cachedTableName
 	} else if _, ok := typ.MethodByName("TableName"); ok {
 		val := reflect.New(typ).Interface()
@@ -65,21 +129,51 @@ func getStructInfo(typ reflect.Type) (*structInfo, error) {
 		globalTableNameCache.set(structTypeName, tableName)
 	}
 
+	info.fields = dedupeFields(collectFields(typ, nil, "", "", map[reflect.Type]bool{typ: true}))
+	setCodecs(typ, info.fields)
+
+	structCache.Store(typ, info)
+	return info, nil
+}
+
+// collectFields returns the mapped fields of a struct type. Embedded structs
+// without a column name, and struct fields tagged with the prefix option, e.g.
+// `db:"address_,prefix"`, are traversed and their columns added with the
+// prefix. seen holds the struct types on the path, to stop on cycles.
+func collectFields(typ reflect.Type, index []int, prefix, path string, seen map[reflect.Type]bool) []fieldInfo {
+	var fields []fieldInfo
 	for i := 0; i < typ.NumField(); i++ {
 		field := typ.Field(i)
 
-		if !field.IsExported() {
+		dbTag, tagged := field.Tag.Lookup("db")
+		if dbTag == "-" {
 			continue
 		}
-
-		dbTag := field.Tag.Get("db")
-		if dbTag == "" || dbTag == "-" {
+		parts := strings.Split(dbTag, ",")
+		fieldIndex := append(append(make([]int, 0, len(index)+1), index...), i)
+
+		if embedded, ok := nestedStruct(field, parts); ok {
+			if seen[embedded] {
+				continue
+			}
+			seen[embedded] = true
+			nestedPath := path
+			if !field.Anonymous {
+				nestedPath = path + field.Name + "."
+			}
+			fields = append(fields, collectFields(embedded, fieldIndex, prefix+parts[0], nestedPath, seen)...)
+			delete(seen, embedded)
 			continue
 		}
 
-		parts := strings.Split(dbTag, ",")
-		columnName := parts[0]
-		columnNameLower := strings.ToLower(columnName)
+		if !tagged {
+			if column, ok := protoColumn(field); ok {
+				dbTag, parts = column, []string{column}
+			}
+		}
+		if !field.IsExported() || dbTag == "" {
+			continue
+		}
 
 		fieldType := field.Type
 		isPtr := fieldType.Kind() == reflect.Ptr
@@ -87,17 +181,62 @@ func getStructInfo(typ reflect.Type) (*structInfo, error) {
 			fieldType = fieldType.Elem()
 		}
 
-		info.fields = append(info.fields, fieldInfo{
-			name:      field.Name,
-			dbColumn:  columnNameLower,
-			index:     i,
+		fields = append(fields, fieldInfo{
+			name:      path + field.Name,
+			dbColumn:  strings.ToLower(prefix + parts[0]),
+			index:     fieldIndex,
 			isPtr:     isPtr,
 			fieldType: fieldType,
+			options:   parts[1:],
+			isScanner: reflect.PointerTo(fieldType).Implements(scannerType),
+			isJSON:    hasTagOption(parts[1:], "json"),
 		})
 	}
+	return fields
+}
 
-	structCache.Store(typ, info)
-	return info, nil
+// nestedStruct returns the struct type of a field whose columns are mapped
+// from its own fields: an embedded struct without a column name, or a struct
+// field with the prefix option. Embedded pointers to unexported types are
+// skipped, as they can't be allocated when scanning.
+func nestedStruct(field reflect.StructField, parts []string) (reflect.Type, bool) {
+	typ := field.Type
+	isPtr := typ.Kind() == reflect.Ptr
+	if isPtr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || reflect.PointerTo(typ).Implements(scannerType) {
+		return nil, false
+	}
+
+	if hasTagOption(parts[1:], "prefix") {
+		return typ, field.IsExported()
+	}
+	if !field.Anonymous || parts[0] != "" || hasTagOption(parts[1:], "json") {
+		return nil, false
+	}
+	return typ, field.IsExported() || !isPtr
+}
+
+// dedupeFields drops fields whose column is also mapped by a less deeply
+// nested field, as outer fields shadow promoted ones, keeping the first of
+// equally nested fields
+func dedupeFields(fields []fieldInfo) []fieldInfo {
+	depth := make(map[string]int, len(fields))
+	for _, field := range fields {
+		if d, ok := depth[field.dbColumn]; !ok || len(field.index) < d {
+			depth[field.dbColumn] = len(field.index)
+		}
+	}
+
+	result := fields[:0]
+	for _, field := range fields {
+		if d, ok := depth[field.dbColumn]; ok && len(field.index) == d {
+			result = append(result, field)
+			delete(depth, field.dbColumn)
+		}
+	}
+	return result
 }
 
 // toSnakeCase converts CamelCase to snake_case
@@ -112,8 +251,8 @@ func toSnakeCase(s string) string {
 	return strings.ToLower(result.String())
 }
 
-func scanRows[T any](rows *sql.Rows) ([]T, error) {
-	return scanRowsOptimized[T](rows)
+func scanRows[T any](rows *sql.Rows, loc *time.Location) ([]T, error) {
+	return scanRowsOptimized[T](rows, loc)
 }
 
 func setFieldValue(field reflect.Value, value interface{}, fieldType reflect.Type) error {
@@ -184,10 +323,41 @@ func setFieldValueSlow(field reflect.Value, value interface{}, fieldType reflect
 		field = field.Elem()
 	}
 
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+	if ok, err := scanDecimal(field, value); ok {
+		return err
+	}
+	if ok, err := scanProtoValue(field, value); ok {
+		return err
+	}
+
 	return convertAndSet(field, val, fieldType)
 }
 
+// scanScanner scans src into a field whose type implements sql.Scanner with a
+// pointer receiver. Pointer fields are set to nil for NULL and allocated
+// otherwise; other fields are passed NULL as is.
+func scanScanner(field reflect.Value, isPtr bool, src interface{}) error {
+	if !isPtr {
+		return field.Addr().Interface().(sql.Scanner).Scan(scannerSource(field.Type(), src))
+	}
+	if src == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	if field.IsNil() {
+		field.Set(reflect.New(field.Type().Elem()))
+	}
+	return field.Interface().(sql.Scanner).Scan(scannerSource(field.Type().Elem(), src))
+}
+
 func convertAndSet(field reflect.Value, val reflect.Value, fieldType reflect.Type) error {
+	if fieldType == timeType {
+		return setTimeField(field, val)
+	}
+
 	switch fieldType.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return setIntField(field, val)
@@ -196,11 +366,22 @@ func convertAndSet(field reflect.Value, val reflect.Value, fieldType reflect.Typ
 	case reflect.Float32, reflect.Float64:
 		return setFloatField(field, val)
 	case reflect.String:
-		field.SetString(val.String())
+		if text, ok := textValue(val); ok {
+			field.SetString(text)
+			return nil
+		}
+		field.SetString(fmt.Sprint(val.Interface()))
 		return nil
 	case reflect.Bool:
-		field.SetBool(val.Bool())
-		return nil
+		return setBoolField(field, val)
+	case reflect.Slice:
+		if text, ok := textValue(val); ok && fieldType.Elem().Kind() == reflect.Uint8 {
+			field.SetBytes([]byte(text))
+			return nil
+		}
+		if val.Type().ConvertibleTo(fieldType) {
+			field.Set(val.Convert(fieldType))
+		}
 	default:
 		if val.Type().ConvertibleTo(fieldType) {
 			field.Set(val.Convert(fieldType))
@@ -215,6 +396,14 @@ func setIntField(field reflect.Value, val reflect.Value) error {
 		field.SetInt(val.Int())
 		return nil
 	}
+	if text, ok := textValue(val); ok {
+		n, err := strconv.ParseInt(text, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("sqlblade: cannot convert %q to %s", text, field.Type())
+		}
+		field.SetInt(n)
+		return nil
+	}
 	if val.Kind() == reflect.Float64 {
 		field.SetInt(int64(val.Float()))
 		return nil
@@ -223,6 +412,14 @@ func setIntField(field reflect.Value, val reflect.Value) error {
 }
 
 func setUintField(field reflect.Value, val reflect.Value) error {
+	if text, ok := textValue(val); ok {
+		n, err := strconv.ParseUint(text, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("sqlblade: cannot convert %q to %s", text, field.Type())
+		}
+		field.SetUint(n)
+		return nil
+	}
 	if val.Kind() == reflect.Int64 || val.Kind() == reflect.Int {
 		intVal := val.Int()
 		if intVal >= 0 {
@@ -249,5 +446,109 @@ func setFloatField(field reflect.Value, val reflect.Value) error {
 		field.SetFloat(float64(val.Int()))
 		return nil
 	}
+	if text, ok := textValue(val); ok {
+		f, err := strconv.ParseFloat(text, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("sqlblade: cannot convert %q to %s", text, field.Type())
+		}
+		field.SetFloat(f)
+		return nil
+	}
+	return nil
+}
+
+// setTimeField sets a time.Time field from a textual timestamp, e.g. a MySQL
+// DATETIME read without parseTime, or from Unix seconds
+func setTimeField(field reflect.Value, val reflect.Value) error {
+	if val.Kind() == reflect.Int64 {
+		field.Set(reflect.ValueOf(time.Unix(val.Int(), 0).UTC()))
+		return nil
+	}
+	text, ok := textValue(val)
+	if !ok {
+		return fmt.Errorf("sqlblade: cannot convert %s to %s", val.Type(), field.Type())
+	}
+	t, err := parseTime(text)
+	if err != nil {
+		return err
+	}
+	field.Set(reflect.ValueOf(t))
 	return nil
 }
+
+// timeLayouts are the textual timestamp formats returned by the drivers
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// parseTime parses a textual timestamp. Timestamps without a zone are UTC, and
+// MySQL zero dates give the zero time.
+func parseTime(text string) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "0000-00-00") {
+		return time.Time{}, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("sqlblade: cannot convert %q to time.Time", text)
+}
+
+// nullTimeType is the type of sql.NullTime, whose Scan only accepts time.Time
+var nullTimeType = reflect.TypeOf(sql.NullTime{})
+
+// scannerSource converts a textual timestamp scanned into a sql.NullTime to
+// time.Time; other sources are returned as is
+func scannerSource(typ reflect.Type, src interface{}) interface{} {
+	if typ != nullTimeType {
+		return src
+	}
+	text, ok := textValue(reflect.ValueOf(src))
+	if !ok {
+		return src
+	}
+	if t, err := parseTime(text); err == nil {
+		return t
+	}
+	return src
+}
+
+func setBoolField(field reflect.Value, val reflect.Value) error {
+	switch val.Kind() {
+	case reflect.Bool:
+		field.SetBool(val.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetBool(val.Int() != 0)
+	default:
+		text, ok := textValue(val)
+		if !ok {
+			return nil
+		}
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("sqlblade: cannot convert %q to %s", text, field.Type())
+		}
+		field.SetBool(b)
+	}
+	return nil
+}
+
+// textValue returns the text of a string or []byte value, as returned by
+// drivers using a text protocol (e.g. MySQL) for numeric columns
+func textValue(val reflect.Value) (string, bool) {
+	switch {
+	case val.Kind() == reflect.String:
+		return val.String(), true
+	case val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Uint8:
+		return string(val.Bytes()), true
+	default:
+		return "", false
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 )
 
 type columnMapCache struct {
@@ -76,8 +77,44 @@ func (cmc *columnMapCache) getColumnMap(columns []string) map[string]int {
 	return columnMap
 }
 
-func scanRowsOptimized[T any](rows *sql.Rows) ([]T, error) {
-	var result []T
+// scanRowsOptimized scans all rows into T, converting their times to loc when
+// not nil. On error the rows scanned so far are returned with it, for partial
+// results.
+func scanRowsOptimized[T any](rows *sql.Rows, loc *time.Location) ([]T, error) {
+	rs, err := newRowScanner[T](rows, loc)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.release()
+
+	result := make([]T, 0, resultInitialCapacity)
+	for rows.Next() {
+		var val T
+		if err := rs.scan(&val); err != nil {
+			return result, err
+		}
+		result = append(result, val)
+	}
+
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// rowScanner scans the rows of a result set into T one row at a time
+type rowScanner[T any] struct {
+	rows      *sql.Rows
+	info      *structInfo
+	columnMap map[string]int
+	buf       *scanBuffer
+	generated *generatedScan[T]
+	loc       *time.Location // location of the scanned times, nil to keep them
+	times     []fieldInfo    // time fields converted to loc
+}
+
+func newRowScanner[T any](rows *sql.Rows, loc *time.Location) (*rowScanner[T], error) {
 	typ := reflect.TypeOf((*T)(nil)).Elem()
 
 	info, err := getStructInfo(typ)
@@ -89,52 +126,121 @@ func scanRowsOptimized[T any](rows *sql.Rows) ([]T, error) {
 	if err != nil {
 		return nil, err
 	}
+	columns = aliasedColumns(modelType[T](), info, columns)
 
-	columnMap := columnMapCacheInst.getColumnMap(columns)
+	rs := &rowScanner[T]{
+		rows:      rows,
+		info:      info,
+		columnMap: columnMapCacheInst.getColumnMap(columns),
+		buf:       globalScanBufferPool.Get(len(columns)),
+	}
+	if loc != nil {
+		rs.loc, rs.times = loc, timeFields(info)
+	}
+	if model := generatedFor[T](); model != nil && !info.hasCodecs() {
+		rs.generated = newGeneratedScan(model, columns, rs.buf)
+	}
+	return rs, nil
+}
 
-	result = make([]T, 0, resultInitialCapacity)
+// release returns the scan buffer to the pool
+func (rs *rowScanner[T]) release() {
+	if rs.buf != nil {
+		globalScanBufferPool.Put(rs.buf)
+		rs.buf = nil
+	}
+}
 
-	scanBuf := globalScanBufferPool.Get(len(columns))
-	defer globalScanBufferPool.Put(scanBuf)
+// column returns the value of a column of the last scanned row
+func (rs *rowScanner[T]) column(name string) (interface{}, bool) {
+	idx, ok := rs.columnMap[name]
+	if !ok {
+		return nil, false
+	}
+	return rs.buf.values[idx], true
+}
 
-	for rows.Next() {
-		var val T
-		ptrVal := reflect.ValueOf(&val).Elem()
+// scan reads the current row into dest
+func (rs *rowScanner[T]) scan(dest *T) error {
+	if err := rs.scanRow(dest); err != nil {
+		return err
+	}
+	if len(rs.times) > 0 {
+		v := reflect.ValueOf(dest).Elem()
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		localizeTimes(v, rs.times, rs.loc)
+	}
+	return nil
+}
+
+// scanRow reads the current row into dest, as the driver returns it
+func (rs *rowScanner[T]) scanRow(dest *T) error {
+	if rs.generated != nil {
+		return rs.generated.scan(rs.rows, dest)
+	}
+
+	if err := rs.rows.Scan(rs.buf.ptrs...); err != nil {
+		return fmt.Errorf("sqlblade: failed to scan row: %w", err)
+	}
 
-		if err := rows.Scan(scanBuf.ptrs...); err != nil {
-			return nil, fmt.Errorf("sqlblade: failed to scan row: %w", err)
+	ptrVal := reflect.ValueOf(dest).Elem()
+	if ptrVal.Kind() == reflect.Ptr {
+		if ptrVal.IsNil() {
+			ptrVal.Set(reflect.New(ptrVal.Type().Elem()))
 		}
+		ptrVal = ptrVal.Elem()
+	}
 
-		for _, field := range info.fields {
-			colIdx, ok := columnMap[field.dbColumn]
-			if !ok {
-				continue
-			}
+	return setFields(ptrVal, rs.info, rs.columnMap, rs.buf.values)
+}
 
-			fieldVal := ptrVal.Field(field.index)
-			if !fieldVal.IsValid() || !fieldVal.CanSet() {
-				continue
-			}
+// setFields sets the fields of the struct v from the values of a scanned
+// row, found through the column positions of columnMap
+func setFields(v reflect.Value, info *structInfo, columnMap map[string]int, values []interface{}) error {
+	for _, field := range info.fields {
+		colIdx, ok := columnMap[field.dbColumn]
+		if !ok {
+			continue
+		}
 
-			scanVal := scanBuf.values[colIdx]
-			if scanVal == nil {
-				if field.isPtr {
-					fieldVal.Set(reflect.Zero(fieldVal.Type()))
-				}
-				continue
-			}
+		fieldVal := field.settable(v)
+		if !fieldVal.IsValid() || !fieldVal.CanSet() {
+			continue
+		}
 
-			if err := setFieldValue(fieldVal, scanVal, field.fieldType); err != nil {
-				return nil, fmt.Errorf("sqlblade: failed to set field %s: %w", field.name, err)
+		scanVal := values[colIdx]
+		if field.codec != nil {
+			if err := scanCodec(fieldVal, field, scanVal); err != nil {
+				return fmt.Errorf("sqlblade: failed to set field %s: %w", field.name, err)
+			}
+			continue
+		}
+		if field.isJSON {
+			if err := scanJSON(fieldVal, scanVal); err != nil {
+				return fmt.Errorf("sqlblade: failed to set field %s: %w", field.name, err)
 			}
+			continue
+		}
+		if field.isScanner {
+			if err := scanScanner(fieldVal, field.isPtr, scanVal); err != nil {
+				return fmt.Errorf("sqlblade: failed to set field %s: %w", field.name, err)
+			}
+			continue
 		}
 
-		result = append(result, val)
-	}
+		if scanVal == nil {
+			if field.isPtr {
+				fieldVal.Set(reflect.Zero(fieldVal.Type()))
+			}
+			continue
+		}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+		if err := setFieldValue(fieldVal, scanVal, field.fieldType); err != nil {
+			return fmt.Errorf("sqlblade: failed to set field %s: %w", field.name, err)
+		}
 	}
 
-	return result, nil
+	return nil
 }
@@ -0,0 +1,145 @@
+package sqlblade
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WindowMode is what happens to statements held back by a maintenance window
+type WindowMode int
+
+const (
+	// WindowReject fails the statements with ErrMaintenanceWindow
+	WindowReject WindowMode = iota
+	// WindowDelay holds the statements until the window ends or their context is done
+	WindowDelay
+)
+
+// MaintenanceWindow is a period during which statements below MinPriority are
+// rejected or delayed, e.g. to protect the database during a migration. The
+// zero MinPriority holds back PriorityLow statements only; PriorityHigh holds
+// back everything but high-priority statements.
+type MaintenanceWindow struct {
+	Name        string
+	Start       time.Time
+	End         time.Time
+	MinPriority Priority
+	Mode        WindowMode
+}
+
+// active reports whether the window is open at t
+func (w MaintenanceWindow) active(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// Scheduler holds back non-essential statements during maintenance windows.
+// It is a Plugin, so it is installed in the middleware chain with Use:
+//
+//	scheduler := sqlblade.NewScheduler()
+//	scheduler.AddWindow(sqlblade.MaintenanceWindow{Name: "reindex", Start: start, End: end})
+//	client.Use(scheduler)
+//
+//	// rejected with ErrMaintenanceWindow between start and end
+//	sqlblade.Query[Report](client).Execute(sqlblade.WithPriority(ctx, sqlblade.PriorityLow))
+//
+// Windows are checked against the clock of the client the scheduler was
+// installed on with Use.
+type Scheduler struct {
+	mu      sync.RWMutex
+	windows []MaintenanceWindow
+	clock   Clock
+}
+
+// NewScheduler creates a scheduler without windows
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// AddWindow registers a maintenance window
+func (s *Scheduler) AddWindow(w MaintenanceWindow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.windows = append(s.windows, w)
+}
+
+// RemoveWindow removes the windows with the given name
+func (s *Scheduler) RemoveWindow(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	windows := s.windows[:0]
+	for _, w := range s.windows {
+		if w.Name != name {
+			windows = append(windows, w)
+		}
+	}
+	s.windows = windows
+}
+
+// Windows returns the registered windows
+func (s *Scheduler) Windows() []MaintenanceWindow {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]MaintenanceWindow(nil), s.windows...)
+}
+
+// blocking returns an active window that holds back statements of priority p
+func (s *Scheduler) blocking(t time.Time, p Priority) (MaintenanceWindow, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, w := range s.windows {
+		if w.active(t) && p < w.MinPriority {
+			return w, true
+		}
+	}
+	return MaintenanceWindow{}, false
+}
+
+// Init implements Plugin, adopting the client's clock
+func (s *Scheduler) Init(c *Client) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = c.Clock()
+	return nil
+}
+
+// now returns the current time on the scheduler's clock
+func (s *Scheduler) now() (time.Time, Clock) {
+	s.mu.RLock()
+	clock := s.clock
+	s.mu.RUnlock()
+	if clock == nil {
+		clock = SystemClock{}
+	}
+	return clock.Now(), clock
+}
+
+// Hooks implements Plugin
+func (s *Scheduler) Hooks() *Hooks {
+	return nil
+}
+
+// Middleware implements Plugin, rejecting or delaying statements held back by
+// an active window
+func (s *Scheduler) Middleware() Middleware {
+	return func(ctx context.Context, stmt *Statement, next func(ctx context.Context) error) error {
+		p := PriorityFromContext(ctx)
+		for {
+			now, clock := s.now()
+			w, ok := s.blocking(now, p)
+			if !ok {
+				return next(ctx)
+			}
+			if w.Mode == WindowReject {
+				return fmt.Errorf("%w: %s", ErrMaintenanceWindow, w.Name)
+			}
+
+			if err := clock.Sleep(ctx, w.End.Sub(now)); err != nil {
+				return err
+			}
+		}
+	}
+}
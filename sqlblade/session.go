@@ -0,0 +1,93 @@
+package sqlblade
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// WithStrictSession makes every connection opened by the client enforce the
+// SQL modes the generated SQL relies on, so it behaves the same on servers
+// configured differently: STRICT_TRANS_TABLES and ANSI_QUOTES on MySQL, added
+// to the server's sql_mode, and standard_conforming_strings on PostgreSQL.
+// The modes are set when a connection is opened, so the option only applies
+// to clients created with OpenConnector; connections of a *sql.DB given to
+// Open are opened by database/sql and keep the server's modes.
+func WithStrictSession() ClientOption {
+	return func(c *Client) {
+		c.strictSession = true
+	}
+}
+
+// OpenConnector creates a new Client whose database opens its connections
+// through connector, e.g. the connector of a driver's config, running the
+// session setup of options such as WithStrictSession on each new connection
+func OpenConnector(connector driver.Connector, opts ...ClientOption) *Client {
+	if connector == nil {
+		panic(ErrNilDB)
+	}
+	sc := &sessionConnector{Connector: connector}
+	c := Open(sql.OpenDB(sc), opts...)
+	sc.client = c
+	return c
+}
+
+// sessionConnector opens connections through a driver connector and sets up
+// their session for the client
+type sessionConnector struct {
+	driver.Connector
+	client *Client
+}
+
+// Connect implements driver.Connector
+func (sc *sessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := sc.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if sc.client == nil || !sc.client.strictSession {
+		return conn, nil
+	}
+
+	for _, stmt := range strictSessionSQL(sc.client.dialect) {
+		if err := execSession(ctx, conn, stmt); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("sqlblade: setting up session: %w", wrapQueryError(err, stmt, nil))
+		}
+	}
+	return conn, nil
+}
+
+// strictSessionSQL returns the statements enforcing strict SQL modes for the dialect
+func strictSessionSQL(d dialect.Dialect) []string {
+	switch d.Name() {
+	case dialectMySQL:
+		return []string{"SET SESSION sql_mode = TRIM(BOTH ',' FROM CONCAT(@@SESSION.sql_mode, ',STRICT_TRANS_TABLES,ANSI_QUOTES'))"}
+	case dialectPostgres:
+		return []string{"SET standard_conforming_strings = on"}
+	default:
+		return nil
+	}
+}
+
+// execSession executes a statement without arguments on a driver connection
+func execSession(ctx context.Context, conn driver.Conn, sqlStr string) error {
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, sqlStr, nil)
+		if !errors.Is(err, driver.ErrSkip) {
+			return err
+		}
+	}
+
+	stmt, err := conn.Prepare(sqlStr)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(nil) //nolint:staticcheck // drivers without ExecerContext only implement Exec
+	return err
+}
@@ -0,0 +1,85 @@
+package sqlblade
+
+import (
+	"strings"
+)
+
+// setOpAlias is the alias of derived tables wrapping set operations
+const setOpAlias = "sqlblade_set"
+
+// setOp is a query combined with a builder by a set operation
+type setOp[T any] struct {
+	kind  string // UNION, UNION ALL, INTERSECT or EXCEPT
+	query *QueryBuilder[T]
+}
+
+// Union combines the rows of the query with those of other, removing
+// duplicates. The ORDER BY, LIMIT and OFFSET of the builder apply to the
+// combined rows; a combined query with its own ordering or limit is wrapped in
+// a derived table so that they keep applying to it alone. Placeholders are
+// numbered across all the queries. Count and the other aggregates run over
+// the combined rows; cursor pagination does not support set operations.
+func (qb *QueryBuilder[T]) Union(other *QueryBuilder[T]) *QueryBuilder[T] {
+	return qb.combine("UNION", other)
+}
+
+// UnionAll combines the rows of the query with those of other, keeping
+// duplicates; see Union
+func (qb *QueryBuilder[T]) UnionAll(other *QueryBuilder[T]) *QueryBuilder[T] {
+	return qb.combine("UNION ALL", other)
+}
+
+// Intersect keeps the rows of the query that other also returns; see Union.
+// MySQL supports it from 8.0.31.
+func (qb *QueryBuilder[T]) Intersect(other *QueryBuilder[T]) *QueryBuilder[T] {
+	return qb.combine("INTERSECT", other)
+}
+
+// Except keeps the rows of the query that other doesn't return; see Union.
+// MySQL supports it from 8.0.31.
+func (qb *QueryBuilder[T]) Except(other *QueryBuilder[T]) *QueryBuilder[T] {
+	return qb.combine("EXCEPT", other)
+}
+
+// combine appends a set operation with a copy of other, so that later
+// changes to other don't affect the query
+func (qb *QueryBuilder[T]) combine(kind string, other *QueryBuilder[T]) *QueryBuilder[T] {
+	if other == nil {
+		return qb
+	}
+	frozen := *other
+	qb.setOps = append(qb.setOps, setOp[T]{kind: kind, query: &frozen})
+	return qb
+}
+
+// writeCompound writes the query without ORDER BY and LIMIT, followed by its
+// set operations
+func (qb *QueryBuilder[T]) writeCompound(buf *strings.Builder, paramIndex *int, args *[]interface{}) {
+	qb.writeSelectCore(buf, "", paramIndex, args)
+	for _, op := range qb.setOps {
+		buf.WriteString(" ")
+		buf.WriteString(op.kind)
+		buf.WriteString(" ")
+		op.query.writeSetOperand(buf, paramIndex, args)
+	}
+}
+
+// writeSetOperand writes a query combined by a set operation. Queries with
+// their own ordering, limit or set operations are wrapped in a derived table,
+// as SQLite doesn't accept parenthesized operands.
+func (qb *QueryBuilder[T]) writeSetOperand(buf *strings.Builder, paramIndex *int, args *[]interface{}) {
+	if !qb.wrapsAsOperand() {
+		qb.writeSelectCore(buf, "", paramIndex, args)
+		return
+	}
+	buf.WriteString("SELECT * FROM (")
+	qb.writeSelect(buf, "", paramIndex, args)
+	buf.WriteString(") AS ")
+	buf.WriteString(setOpAlias)
+}
+
+// wrapsAsOperand reports whether the query is wrapped in a derived table when
+// combined by a set operation
+func (qb *QueryBuilder[T]) wrapsAsOperand() bool {
+	return len(qb.orderBy) > 0 || qb.limit != nil || qb.offset != nil || len(qb.setOps) > 0
+}
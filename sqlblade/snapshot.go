@@ -0,0 +1,136 @@
+package sqlblade
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatSnapshot renders a statement and its arguments canonically, for
+// golden-file snapshot tests of generated SQL. Runs of whitespace outside
+// quoted strings and identifiers are collapsed to single spaces, and each
+// argument is written on its own line with its normalized driver type, as in
+// HashQuery, so the output doesn't change with map iteration, time zones or
+// integer widths:
+//
+//	UPDATE "users" SET "name" = $1, "status" = $2 WHERE "id" = $3
+//	[1] string("Ada")
+//	[2] string("active")
+//	[3] int64(42)
+//
+// The builders always write SET and INSERT columns in a stable order.
+func FormatSnapshot(sqlStr string, args []interface{}) string {
+	var buf strings.Builder
+	buf.WriteString(normalizeWhitespace(sqlStr))
+	for i, arg := range args {
+		buf.WriteString("\n[")
+		buf.WriteString(strconv.Itoa(i + 1))
+		buf.WriteString("] ")
+		buf.WriteString(formatSnapshotArg(arg))
+	}
+	return buf.String()
+}
+
+// normalizeWhitespace collapses runs of whitespace outside quotes into single
+// spaces and trims the statement
+func normalizeWhitespace(sqlStr string) string {
+	var buf strings.Builder
+	buf.Grow(len(sqlStr))
+
+	var quote rune
+	space := false
+	for _, r := range sqlStr {
+		if quote != 0 {
+			buf.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch r {
+		case ' ', '\t', '\n', '\r', '\f', '\v':
+			space = true
+			continue
+		case '\'', '"', '`':
+			quote = r
+		}
+		if space && buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		space = false
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// formatSnapshotArg renders an argument as its normalized type and value
+func formatSnapshotArg(arg interface{}) string {
+	arg, _ = bindValue(arg)
+	v, err := driver.DefaultParameterConverter.ConvertValue(arg)
+	if err != nil {
+		// Values the driver converts itself (e.g. slices) are printed as is
+		return fmt.Sprintf("%T(%#v)", arg, arg)
+	}
+
+	switch x := v.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return "int64(" + strconv.FormatInt(x, 10) + ")"
+	case float64:
+		return "float64(" + strconv.FormatFloat(x, 'g', -1, 64) + ")"
+	case bool:
+		return "bool(" + strconv.FormatBool(x) + ")"
+	case string:
+		return "string(" + strconv.Quote(x) + ")"
+	case []byte:
+		return "bytes(" + hex.EncodeToString(x) + ")"
+	case time.Time:
+		return "time(" + x.UTC().Format(time.RFC3339Nano) + ")"
+	default:
+		return fmt.Sprintf("%T(%#v)", x, x)
+	}
+}
+
+// Snapshot returns the query's SQL and arguments formatted by FormatSnapshot
+func (qb *QueryBuilder[T]) Snapshot() string {
+	sqlStr, args := qb.buildSQL()
+	return FormatSnapshot(sqlStr, args)
+}
+
+// Snapshot returns the query's SQL and arguments formatted by FormatSnapshot
+func (qp *QueryPreview[T]) Snapshot() string {
+	return qp.builder.Snapshot()
+}
+
+// Snapshot returns the statement's SQL and arguments formatted by FormatSnapshot
+func (ib *InsertBuilder[T]) Snapshot() (string, error) {
+	sqlStr, args, err := ib.buildSQL(ib.returning)
+	if err != nil {
+		return "", err
+	}
+	return FormatSnapshot(sqlStr, args), nil
+}
+
+// Snapshot returns the statement's SQL and arguments formatted by FormatSnapshot
+func (ub *UpdateBuilder[T]) Snapshot() (string, error) {
+	sqlStr, args, err := ub.buildSQL(ub.returning)
+	if err != nil {
+		return "", err
+	}
+	return FormatSnapshot(sqlStr, args), nil
+}
+
+// Snapshot returns the statement's SQL and arguments formatted by FormatSnapshot
+func (db *DeleteBuilder[T]) Snapshot() string {
+	sqlStr, args := db.buildSQL(db.returning)
+	return FormatSnapshot(sqlStr, args)
+}
+
+// Snapshot returns the query and its arguments formatted by FormatSnapshot
+func (rq *RawQuery[T]) Snapshot() string {
+	return FormatSnapshot(rq.query, rq.args)
+}
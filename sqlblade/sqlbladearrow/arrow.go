@@ -0,0 +1,146 @@
+// Package sqlbladearrow encodes SQLBlade query results as Apache Arrow
+// record batches and Parquet files, for handing them to analytics tools and
+// data lakes without a CSV round trip.
+//
+// It is a separate module, so that applications not using it don't depend
+// on Arrow. Rows are streamed with ExecuteBatches and converted batch by
+// batch, so large exports never have to be held in memory:
+//
+//	query := sqlblade.Query[Order](db).Where("created_at", ">=", since)
+//	err := sqlbladearrow.ExportParquet(ctx, query, file)
+//
+// Columns are nullable and typed after their ColumnKind: string, int64,
+// float64, boolean, binary, or timestamp[us, UTC].
+package sqlbladearrow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+// DefaultBatchSize is the number of rows of each record batch when none is given
+const DefaultBatchSize = 10000
+
+// Source is a query whose rows can be streamed in column batches:
+// a *sqlblade.QueryBuilder or a *sqlblade.RawQuery
+type Source interface {
+	ExecuteBatches(ctx context.Context, size int, fn func(*sqlblade.ColumnBatch) error) error
+}
+
+// ExecuteArrow executes the query and passes its rows to fn as Arrow records
+// of up to batchSize rows. A record is released when fn returns; fn must
+// Retain it to keep it. A query without rows yields one empty record,
+// carrying the schema.
+func ExecuteArrow(ctx context.Context, src Source, batchSize int, fn func(arrow.Record) error) error {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	var schema *arrow.Schema
+	return src.ExecuteBatches(ctx, batchSize, func(batch *sqlblade.ColumnBatch) error {
+		if schema == nil {
+			schema = Schema(batch.Columns)
+		}
+		rec, err := newRecord(schema, batch)
+		if err != nil {
+			return err
+		}
+		defer rec.Release()
+		return fn(rec)
+	})
+}
+
+// ExportParquet executes the query and writes its rows to w as a Parquet
+// file, one row group per batch of DefaultBatchSize rows
+func ExportParquet(ctx context.Context, src Source, w io.Writer) error {
+	var fw *pqarrow.FileWriter
+	err := ExecuteArrow(ctx, src, DefaultBatchSize, func(rec arrow.Record) error {
+		if fw == nil {
+			var err error
+			fw, err = pqarrow.NewFileWriter(rec.Schema(), w, parquet.NewWriterProperties(), pqarrow.DefaultWriterProps())
+			if err != nil {
+				return fmt.Errorf("sqlbladearrow: failed to create parquet writer: %w", err)
+			}
+		}
+		if rec.NumRows() == 0 {
+			return nil
+		}
+		return fw.Write(rec)
+	})
+	if fw == nil {
+		return err
+	}
+	if closeErr := fw.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("sqlbladearrow: failed to close parquet writer: %w", closeErr)
+	}
+	return err
+}
+
+// Schema returns the Arrow schema of batches of the columns
+func Schema(columns []sqlblade.BatchColumn) *arrow.Schema {
+	fields := make([]arrow.Field, len(columns))
+	for i, column := range columns {
+		fields[i] = arrow.Field{Name: column.Name, Type: arrowType(column.Kind), Nullable: true}
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+// arrowType returns the Arrow type of the values of a column kind
+func arrowType(kind sqlblade.ColumnKind) arrow.DataType {
+	switch kind {
+	case sqlblade.ColumnInt:
+		return arrow.PrimitiveTypes.Int64
+	case sqlblade.ColumnFloat:
+		return arrow.PrimitiveTypes.Float64
+	case sqlblade.ColumnBool:
+		return arrow.FixedWidthTypes.Boolean
+	case sqlblade.ColumnBytes:
+		return arrow.BinaryTypes.Binary
+	case sqlblade.ColumnTime:
+		return arrow.FixedWidthTypes.Timestamp_us
+	default:
+		return arrow.BinaryTypes.String
+	}
+}
+
+// newRecord builds the Arrow record of a batch
+func newRecord(schema *arrow.Schema, batch *sqlblade.ColumnBatch) (arrow.Record, error) {
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+
+	for c, values := range batch.Values {
+		field := b.Field(c)
+		field.Reserve(len(values))
+		for _, value := range values {
+			if value == nil {
+				field.AppendNull()
+				continue
+			}
+			switch fb := field.(type) {
+			case *array.Int64Builder:
+				fb.Append(value.(int64))
+			case *array.Float64Builder:
+				fb.Append(value.(float64))
+			case *array.BooleanBuilder:
+				fb.Append(value.(bool))
+			case *array.BinaryBuilder:
+				fb.Append(value.([]byte))
+			case *array.TimestampBuilder:
+				fb.Append(arrow.Timestamp(value.(time.Time).UnixMicro()))
+			case *array.StringBuilder:
+				fb.Append(value.(string))
+			default:
+				return nil, fmt.Errorf("sqlbladearrow: column %s: unsupported builder %T", batch.Columns[c].Name, field)
+			}
+		}
+	}
+	return b.NewRecord(), nil
+}
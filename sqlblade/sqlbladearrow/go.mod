@@ -0,0 +1,10 @@
+module github.com/alicanli1995/sqlblade/sqlblade/sqlbladearrow
+
+go 1.22.7
+
+require (
+	github.com/alicanli1995/sqlblade v0.0.0
+	github.com/apache/arrow-go/v18 v18.1.0
+)
+
+replace github.com/alicanli1995/sqlblade => ../../
@@ -0,0 +1,36 @@
+package sqlbladetest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+)
+
+// AssertMaxQueries returns a context that captures the statements executed
+// with it and fails the test when it finishes if more than n were issued.
+// It catches code paths that regress into N+1 query patterns:
+//
+//	ctx := sqlbladetest.AssertMaxQueries(t, context.Background(), 2)
+//	orders, err := repo.OrdersWithItems(ctx, userID)
+func AssertMaxQueries(t testing.TB, ctx context.Context, n int) context.Context {
+	t.Helper()
+
+	ctx, capture := sqlblade.WithCapture(ctx)
+	t.Cleanup(func() {
+		if statements := capture.Statements(); len(statements) > n {
+			t.Errorf("sqlbladetest: expected at most %d queries, got %d:\n%s", n, len(statements), formatStatements(statements))
+		}
+	})
+	return ctx
+}
+
+func formatStatements(statements []sqlblade.Statement) string {
+	var buf strings.Builder
+	for i, stmt := range statements {
+		fmt.Fprintf(&buf, "  %d. %s\n", i+1, stmt.SQL)
+	}
+	return buf.String()
+}
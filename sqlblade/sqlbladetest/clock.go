@@ -0,0 +1,101 @@
+package sqlbladetest
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock is a sqlblade.Clock that only moves when told to, for deterministic
+// tests of time-based features such as maintenance windows:
+//
+//	clock := sqlbladetest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//	client := sqlblade.Open(db, sqlblade.WithClock(clock))
+//	...
+//	clock.Advance(time.Hour) // wakes statements delayed by a window ending within the hour
+type Clock struct {
+	mu       sync.Mutex
+	now      time.Time
+	sleepers []*sleeper
+}
+
+// sleeper is a goroutine waiting in Sleep
+type sleeper struct {
+	until time.Time
+	done  chan struct{}
+}
+
+// NewClock creates a clock frozen at now
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now implements sqlblade.Clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep implements sqlblade.Clock, waiting until the clock is advanced past d
+// or ctx is done
+func (c *Clock) Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	s := &sleeper{until: c.now.Add(d), done: make(chan struct{})}
+	c.sleepers = append(c.sleepers, s)
+	c.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		c.remove(s)
+		c.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Set moves the clock to t, waking the sleepers whose time has come
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = t
+	sleepers := c.sleepers[:0]
+	for _, s := range c.sleepers {
+		if s.until.After(t) {
+			sleepers = append(sleepers, s)
+		} else {
+			close(s.done)
+		}
+	}
+	c.sleepers = sleepers
+}
+
+// Advance moves the clock forward by d
+func (c *Clock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Sleepers returns the number of goroutines waiting in Sleep, so tests can
+// wait for a statement to be delayed before advancing the clock
+func (c *Clock) Sleepers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.sleepers)
+}
+
+// remove drops a sleeper whose context is done
+func (c *Clock) remove(s *sleeper) {
+	for i, other := range c.sleepers {
+		if other == s {
+			c.sleepers = append(c.sleepers[:i], c.sleepers[i+1:]...)
+			return
+		}
+	}
+}
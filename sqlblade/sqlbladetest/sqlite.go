@@ -0,0 +1,98 @@
+// Package sqlbladetest provides helpers for testing code built on SQLBlade.
+//
+// The package has no driver dependency of its own; import a SQLite driver
+// (e.g. github.com/mattn/go-sqlite3 or modernc.org/sqlite) in your tests:
+//
+//	func TestUserRepository(t *testing.T) {
+//	    client := sqlbladetest.NewSQLiteMemory(t, User{}, Order{})
+//	    repo := NewUserRepository(client)
+//	    ...
+//	}
+package sqlbladetest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+)
+
+// SQLiteDriver is the database/sql driver name used by NewSQLiteMemory.
+// When empty, the first registered driver named "sqlite3" or "sqlite" is used.
+var SQLiteDriver = ""
+
+var memoryDBCounter int64
+
+// NewSQLiteMemory returns a Client backed by a private in-memory SQLite database
+// using a shared-cache DSN, so every connection of the pool sees the same data.
+// The given models are created with AutoMigrate and the database is closed when
+// the test finishes.
+func NewSQLiteMemory(t testing.TB, models ...interface{}) *sqlblade.Client {
+	t.Helper()
+
+	driver := sqliteDriver()
+	if driver == "" {
+		t.Fatalf("sqlbladetest: no SQLite driver registered, import one such as github.com/mattn/go-sqlite3")
+	}
+
+	dsn := fmt.Sprintf("file:%s_%d?mode=memory&cache=shared", sanitizeName(t.Name()), atomic.AddInt64(&memoryDBCounter, 1))
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		t.Fatalf("sqlbladetest: failed to open SQLite database: %v", err)
+	}
+
+	// An in-memory database only lives as long as one of its connections is
+	// open, so keep one pinned for the lifetime of the test.
+	ctx := context.Background()
+	keepAlive, err := db.Conn(ctx)
+	if err != nil {
+		_ = db.Close()
+		t.Fatalf("sqlbladetest: failed to connect to SQLite database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = keepAlive.Close()
+		_ = db.Close()
+	})
+
+	client := sqlblade.Open(db)
+	if len(models) > 0 {
+		if err := client.AutoMigrate(ctx, models...); err != nil {
+			t.Fatalf("sqlbladetest: failed to migrate models: %v", err)
+		}
+	}
+
+	return client
+}
+
+func sqliteDriver() string {
+	if SQLiteDriver != "" {
+		return SQLiteDriver
+	}
+
+	registered := sql.Drivers()
+	for _, name := range []string{"sqlite3", "sqlite"} {
+		for _, driver := range registered {
+			if driver == name {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// sanitizeName turns a test name into a string usable as a database name
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
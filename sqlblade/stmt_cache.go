@@ -1,69 +1,307 @@
 package sqlblade
 
 import (
+	"container/list"
 	"context"
-	"crypto/sha256"
 	"database/sql"
-	"encoding/hex"
+	"errors"
+	"strings"
 	"sync"
+	"sync/atomic"
 )
 
+// defaultStmtCacheSize is the default maximum number of prepared statements kept per database
+const defaultStmtCacheSize = 256
+
+// stmtCache is an LRU cache of the prepared statements of one database
 type stmtCache struct {
-	mu    sync.RWMutex
-	store map[string]*sql.Stmt
-	db    *sql.DB
+	mu      sync.Mutex
+	store   map[string]*list.Element
+	lru     *list.List // front = most recently used
+	db      *sql.DB
+	maxSize int
+
+	// disabled is set when prepared statements were found not to work,
+	// e.g. behind a transaction-pooling proxy
+	disabled atomic.Bool
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
 }
 
-var (
-	globalStmtCache *stmtCache
-	stmtCacheOnce   sync.Once
-)
+// stmtCacheEntry is a cached prepared statement
+type stmtCacheEntry struct {
+	sql  string
+	stmt *sql.Stmt
+}
+
+// StmtCacheOption configures the prepared statement cache of a database
+type StmtCacheOption func(*stmtCache)
 
-func initStmtCache(db *sql.DB) *stmtCache {
-	stmtCacheOnce.Do(func() {
-		globalStmtCache = &stmtCache{
-			store: make(map[string]*sql.Stmt),
-			db:    db,
+// WithStmtCacheSize sets the maximum number of prepared statements kept for
+// the database; the least recently used statement is closed beyond it
+func WithStmtCacheSize(n int) StmtCacheOption {
+	return func(sc *stmtCache) {
+		if n > 0 {
+			sc.maxSize = n
 		}
+	}
+}
+
+// StmtCacheStats reports the usage of the prepared statement cache
+type StmtCacheStats struct {
+	Hits      uint64
+	Misses    uint64 // statements that had to be prepared
+	Evictions uint64
+	Size      int
+}
+
+// HitRate returns the share of lookups served from the cache, 0 without lookups
+func (s StmtCacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// stmtCaches holds the prepared statement caches enabled per database
+var stmtCaches sync.Map // map[*sql.DB]*stmtCache
+
+// PreparedStatementCache enables the prepared statement cache for a database.
+// Each database has its own cache, holding up to 256 statements unless
+// configured otherwise; calling it again reconfigures the existing cache.
+func PreparedStatementCache(db *sql.DB, opts ...StmtCacheOption) {
+	if db == nil {
+		panic(ErrNilDB)
+	}
+
+	sc := &stmtCache{
+		store:   make(map[string]*list.Element),
+		lru:     list.New(),
+		db:      db,
+		maxSize: defaultStmtCacheSize,
+	}
+	if existing, loaded := stmtCaches.LoadOrStore(db, sc); loaded {
+		sc = existing.(*stmtCache)
+	}
+
+	sc.mu.Lock()
+	for _, opt := range opts {
+		opt(sc)
+	}
+	sc.evictLocked()
+	sc.mu.Unlock()
+}
+
+// CloseStmtCache closes the cached statements of a database and disables its cache
+func CloseStmtCache(db *sql.DB) {
+	if sc, ok := stmtCaches.LoadAndDelete(db); ok {
+		sc.(*stmtCache).clear()
+	}
+}
+
+// ClearStmtCache closes the cached statements of all databases, keeping the caches enabled
+func ClearStmtCache() {
+	stmtCaches.Range(func(_, sc interface{}) bool {
+		sc.(*stmtCache).clear()
+		return true
 	})
-	return globalStmtCache
 }
 
-func (sc *stmtCache) getStmt(ctx context.Context, sqlStr string) (*sql.Stmt, error) {
-	hash := hashSQL(sqlStr)
+// stmtCacheFor returns the prepared statement cache of a database, or nil
+func stmtCacheFor(db *sql.DB) *stmtCache {
+	if sc, ok := stmtCaches.Load(db); ok {
+		return sc.(*stmtCache)
+	}
+	return nil
+}
+
+// StatementCacheStats returns the usage of the prepared statement caches of all databases
+func StatementCacheStats() StmtCacheStats {
+	var total StmtCacheStats
+	stmtCaches.Range(func(_, sc interface{}) bool {
+		stats := sc.(*stmtCache).stats()
+		total.Hits += stats.Hits
+		total.Misses += stats.Misses
+		total.Evictions += stats.Evictions
+		total.Size += stats.Size
+		return true
+	})
+	return total
+}
 
-	sc.mu.RLock()
-	if stmt, ok := sc.store[hash]; ok {
-		sc.mu.RUnlock()
-		return stmt, nil
+// StatementCacheStatsFor returns the usage of the prepared statement cache of a database
+func StatementCacheStatsFor(db *sql.DB) StmtCacheStats {
+	if sc := stmtCacheFor(db); sc != nil {
+		return sc.stats()
 	}
-	sc.mu.RUnlock()
+	return StmtCacheStats{}
+}
 
+func (sc *stmtCache) stats() StmtCacheStats {
 	sc.mu.Lock()
-	defer sc.mu.Unlock()
+	size := sc.lru.Len()
+	sc.mu.Unlock()
+	return StmtCacheStats{
+		Hits:      sc.hits.Load(),
+		Misses:    sc.misses.Load(),
+		Evictions: sc.evictions.Load(),
+		Size:      size,
+	}
+}
 
-	if stmt, ok := sc.store[hash]; ok {
-		return stmt, nil
+func (sc *stmtCache) getStmt(ctx context.Context, sqlStr string) (*sql.Stmt, error) {
+	sc.mu.Lock()
+	if elem, ok := sc.store[sqlStr]; ok {
+		sc.lru.MoveToFront(elem)
+		sc.mu.Unlock()
+		sc.hits.Add(1)
+		return elem.Value.(*stmtCacheEntry).stmt, nil
 	}
+	sc.mu.Unlock()
 
+	sc.misses.Add(1)
 	stmt, err := sc.db.PrepareContext(ctx, sqlStr)
 	if err != nil {
 		return nil, err
 	}
 
-	sc.store[hash] = stmt
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if elem, ok := sc.store[sqlStr]; ok {
+		// Prepared concurrently by another caller
+		_ = stmt.Close()
+		sc.lru.MoveToFront(elem)
+		return elem.Value.(*stmtCacheEntry).stmt, nil
+	}
+	sc.store[sqlStr] = sc.lru.PushFront(&stmtCacheEntry{sql: sqlStr, stmt: stmt})
+	sc.evictLocked()
 	return stmt, nil
 }
 
-func hashSQL(sqlStr string) string {
-	h := sha256.Sum256([]byte(sqlStr))
-	return hex.EncodeToString(h[:])
+// evictLocked closes the least recently used statements beyond the maximum size
+func (sc *stmtCache) evictLocked() {
+	for sc.lru.Len() > sc.maxSize {
+		sc.removeLocked(sc.lru.Back())
+		sc.evictions.Add(1)
+	}
 }
 
-// ClearStmtCache clears all cached statements
-func ClearStmtCache() {
-	if globalStmtCache != nil {
-		globalStmtCache.clear()
+// removeLocked closes a cached statement and removes it from the cache.
+// Statements still in use are closed once their queries complete.
+func (sc *stmtCache) removeLocked(elem *list.Element) {
+	entry := sc.lru.Remove(elem).(*stmtCacheEntry)
+	delete(sc.store, entry.sql)
+	_ = entry.stmt.Close()
+}
+
+// invalidate removes the cached statement of a query, if it is still stmt
+func (sc *stmtCache) invalidate(sqlStr string, stmt *sql.Stmt) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if elem, ok := sc.store[sqlStr]; ok && elem.Value.(*stmtCacheEntry).stmt == stmt {
+		sc.removeLocked(elem)
+	}
+}
+
+// errStmtUnavailable is returned when a statement is not cached and cannot be
+// prepared without blocking, so it has to be sent unprepared
+var errStmtUnavailable = errors.New("sqlblade: prepared statement unavailable")
+
+// txStmt returns the cached statement of a query for use in tx. Statements
+// missing from the cache are only prepared when the pool has a connection to
+// spare: the transaction holds one, and waiting for another could deadlock
+// pools limited to a single connection.
+func (sc *stmtCache) txStmt(ctx context.Context, tx *sql.Tx, sqlStr string) (*sql.Stmt, *sql.Stmt, error) {
+	stmt, ok := sc.lookup(sqlStr)
+	if !ok {
+		if !sc.canPrepare() {
+			return nil, nil, errStmtUnavailable
+		}
+		var err error
+		if stmt, err = sc.getStmt(ctx, sqlStr); err != nil {
+			return nil, nil, err
+		}
+	}
+	return stmt, tx.StmtContext(ctx, stmt), nil
+}
+
+// lookup returns the cached statement of a query without preparing it
+func (sc *stmtCache) lookup(sqlStr string) (*sql.Stmt, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	elem, ok := sc.store[sqlStr]
+	if !ok {
+		return nil, false
+	}
+	sc.lru.MoveToFront(elem)
+	sc.hits.Add(1)
+	return elem.Value.(*stmtCacheEntry).stmt, true
+}
+
+// canPrepare reports whether the database has a connection available for preparing
+func (sc *stmtCache) canPrepare() bool {
+	stats := sc.db.Stats()
+	return stats.MaxOpenConnections == 0 || stats.Idle > 0 || stats.OpenConnections < stats.MaxOpenConnections
+}
+
+// stmt returns the statement to run a query with: the cached statement, bound
+// to tx when set
+func (sc *stmtCache) stmt(ctx context.Context, tx *sql.Tx, sqlStr string) (cached, bound *sql.Stmt, err error) {
+	if tx != nil {
+		return sc.txStmt(ctx, tx, sqlStr)
+	}
+	cached, err = sc.getStmt(ctx, sqlStr)
+	return cached, cached, err
+}
+
+// retryable reports whether a statement failing with err is prepared again.
+// Inside a transaction only statements closed on the client are retried, as
+// a server error may have aborted the transaction.
+func retryable(tx *sql.Tx, err error) bool {
+	if tx != nil {
+		return isClosedStatementError(err)
+	}
+	return isInvalidStatementError(err)
+}
+
+func (sc *stmtCache) queryContext(ctx context.Context, tx *sql.Tx, sqlStr string, args []interface{}) (*sql.Rows, error) {
+	for attempt := 0; ; attempt++ {
+		cached, stmt, err := sc.stmt(ctx, tx, sqlStr)
+		if err != nil {
+			return nil, err
+		}
+		// Statements bound to a transaction are closed when it ends
+		rows, err := stmt.QueryContext(ctx, args...)
+		if isInvalidStatementError(err) {
+			sc.invalidate(sqlStr, cached)
+			if attempt == 0 && retryable(tx, err) {
+				continue
+			}
+		}
+		return rows, err
+	}
+}
+
+func (sc *stmtCache) execContext(ctx context.Context, tx *sql.Tx, sqlStr string, args []interface{}) (sql.Result, error) {
+	for attempt := 0; ; attempt++ {
+		cached, stmt, err := sc.stmt(ctx, tx, sqlStr)
+		if err != nil {
+			return nil, err
+		}
+		result, err := stmt.ExecContext(ctx, args...)
+		if tx != nil {
+			_ = stmt.Close()
+		}
+		if isInvalidStatementError(err) {
+			sc.invalidate(sqlStr, cached)
+			if attempt == 0 && retryable(tx, err) {
+				continue
+			}
+		}
+		return result, err
 	}
 }
 
@@ -71,12 +309,44 @@ func (sc *stmtCache) clear() {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
 
-	for _, stmt := range sc.store {
-		_ = stmt.Close()
+	for sc.lru.Len() > 0 {
+		sc.removeLocked(sc.lru.Back())
 	}
-	sc.store = make(map[string]*sql.Stmt)
 }
 
-func PreparedStatementCache(db *sql.DB) {
-	initStmtCache(db)
+// isInvalidStatementError reports whether a prepared statement can no longer
+// be used and has to be prepared again: it was closed by an eviction while
+// being handed out, or the schema changed under it (PostgreSQL "cached plan
+// must not change result type", MySQL error 1615)
+func isInvalidStatementError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return isClosedStatementError(err) ||
+		strings.Contains(msg, "cached plan must not change result type") ||
+		strings.Contains(msg, "needs to be re-prepared") ||
+		strings.Contains(msg, "Error 1615")
+}
+
+// isClosedStatementError reports whether a statement was closed on the client
+func isClosedStatementError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "statement is closed") ||
+		strings.Contains(msg, "statement is invalid")
+}
+
+// isUnpreparableError reports whether a statement cannot be prepared and has
+// to be sent unprepared, e.g. commands outside MySQL's prepared statement
+// protocol (error 1295)
+func isUnpreparableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "Error 1295") ||
+		strings.Contains(msg, "not supported in the prepared statement protocol")
 }
@@ -0,0 +1,185 @@
+package sqlblade
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// WithStrictQueries makes every builder of the client strict, see
+// QueryBuilder.Strict
+func WithStrictQueries() ClientOption {
+	return func(c *Client) {
+		c.strictQueries = true
+	}
+}
+
+// Strict makes the query fail with ErrInvalidOperator for conditions whose
+// operator or value the builder can't render, which are otherwise left out
+// of the WHERE clause, and with ErrInvalidColumn for columns that are not
+// mapped by a field of T, instead of failing in the database. Qualified
+// columns such as "o.total" are not checked, and neither are the columns of
+// queries with joins, nor the ORDER BY of queries selecting expressions.
+// Executing the query and QueryPreview.Err report the error.
+func (qb *QueryBuilder[T]) Strict() *QueryBuilder[T] {
+	qb.strict = true
+	return qb
+}
+
+// Strict makes the update fail with ErrInvalidOperator or ErrInvalidColumn
+// for invalid conditions and unmapped columns, see QueryBuilder.Strict
+func (ub *UpdateBuilder[T]) Strict() *UpdateBuilder[T] {
+	ub.strict = true
+	return ub
+}
+
+// Strict makes the delete fail with ErrInvalidOperator or ErrInvalidColumn
+// for invalid conditions and unmapped columns, see QueryBuilder.Strict
+func (db *DeleteBuilder[T]) Strict() *DeleteBuilder[T] {
+	db.strict = true
+	return db
+}
+
+// Err returns the error the query fails with before reaching the database
+// when it is strict, nil when it is valid
+func (qp *QueryPreview[T]) Err() error {
+	return qp.builder.validate()
+}
+
+// validate checks the query when it is strict
+func (qb *QueryBuilder[T]) validate() error {
+	if !qb.strict && !qb.client.strictQueries {
+		return nil
+	}
+
+	columns := modelColumns(modelType[T]())
+	if len(qb.joins) > 0 {
+		columns = nil
+	}
+	if err := checkConditions(columns, qb.whereClauses); err != nil {
+		return err
+	}
+	if err := checkConditions(nil, qb.having); err != nil {
+		return err
+	}
+	for _, col := range qb.selectCols {
+		if err := checkColumn(columns, col); err != nil {
+			return err
+		}
+	}
+	for _, item := range qb.groupBy {
+		if !item.raw {
+			if err := checkColumn(columns, item.expr); err != nil {
+				return err
+			}
+		}
+	}
+
+	// ORDER BY may refer to the aliases of selected expressions
+	if len(qb.selectExprs) > 0 || len(qb.windows) > 0 {
+		columns = nil
+	}
+	for _, ob := range qb.orderBy {
+		if !ob.Raw {
+			if err := checkColumn(columns, ob.Column); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks the update when it is strict
+func (ub *UpdateBuilder[T]) validate() error {
+	if !ub.strict && !ub.client.strictQueries {
+		return nil
+	}
+
+	columns := modelColumns(modelType[T]())
+	for col := range ub.sets {
+		if err := checkColumn(columns, col); err != nil {
+			return err
+		}
+	}
+	return checkConditions(columns, ub.whereClauses)
+}
+
+// validate checks the delete when it is strict
+func (db *DeleteBuilder[T]) validate() error {
+	if !db.strict && !db.client.strictQueries {
+		return nil
+	}
+	return checkConditions(modelColumns(modelType[T]()), db.whereClauses)
+}
+
+// modelColumns returns the set of lower-cased columns mapped by the fields of
+// typ, nil when it is not a model
+func modelColumns(typ reflect.Type) map[string]bool {
+	info, err := getStructInfo(typ)
+	if err != nil || len(info.fields) == 0 {
+		return nil
+	}
+	columns := make(map[string]bool, len(info.fields))
+	for _, field := range info.fields {
+		columns[field.dbColumn] = true
+	}
+	return columns
+}
+
+// checkColumn returns ErrInvalidColumn when an unqualified column is not in
+// columns; nil columns accept any column
+func checkColumn(columns map[string]bool, column string) error {
+	if columns == nil || column == "*" || strings.Contains(column, ".") {
+		return nil
+	}
+	if !columns[strings.ToLower(column)] {
+		return fmt.Errorf("%w: %s", ErrInvalidColumn, column)
+	}
+	return nil
+}
+
+// checkConditions returns ErrInvalidOperator for conditions that are left
+// out of the SQL, and ErrInvalidColumn for the columns not in columns
+func checkConditions(columns map[string]bool, clauses []WhereClause) error {
+	for _, clause := range clauses {
+		if group, ok := clause.Value.(*ConditionGroup); ok {
+			if err := checkConditions(columns, group.clauses); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, ok := clause.Value.(rawExpr); ok {
+			continue
+		}
+
+		op := strings.ToUpper(strings.TrimSpace(clause.Operator))
+		if !isValidOperator(op) {
+			return fmt.Errorf("%w: %q on %s", ErrInvalidOperator, clause.Operator, clause.Column)
+		}
+		switch op {
+		case "IN", "NOT IN":
+			_, isList := inValues(clause.Value)
+			_, isSubquery := clause.Value.(*Subquery)
+			_, isParam := clause.Value.(NamedParam)
+			if !isList && !isSubquery && !isParam {
+				return fmt.Errorf("%w: %s on %s needs a slice or a subquery, got %T", ErrInvalidOperator, op, clause.Column, clause.Value)
+			}
+		case "BETWEEN", "NOT BETWEEN":
+			if values, ok := clause.Value.([]interface{}); !ok || len(values) != 2 {
+				return fmt.Errorf("%w: %s on %s needs two values", ErrInvalidOperator, op, clause.Column)
+			}
+		}
+
+		if clause.Column != "" {
+			if err := checkColumn(columns, clause.Column); err != nil {
+				return err
+			}
+		}
+		if ref, ok := clause.Value.(ColumnRef); ok {
+			if err := checkColumn(columns, string(ref)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package sqlblade
+
+import "strings"
+
+// Tag labels the query with tags such as "feature:checkout", to attribute
+// database load by feature rather than by table. Tags are passed to hooks
+// and middleware in HookEvent.Tags and Statement.Tags, shown by the query
+// debugger, counted apart by MetricsCollector in MetricKey.Tag, and sent in
+// a leading SQL comment, e.g. /* feature:checkout */, that shows up in
+// pg_stat_activity and the slow query log. Keep their number small: each
+// combination is a separate statement cache entry and metric key.
+func (qb *QueryBuilder[T]) Tag(tags ...string) *QueryBuilder[T] {
+	qb.tags = append(qb.tags, tags...)
+	return qb
+}
+
+// Tag labels the insert with tags, see QueryBuilder.Tag
+func (ib *InsertBuilder[T]) Tag(tags ...string) *InsertBuilder[T] {
+	ib.tags = append(ib.tags, tags...)
+	return ib
+}
+
+// Tag labels the update with tags, see QueryBuilder.Tag
+func (ub *UpdateBuilder[T]) Tag(tags ...string) *UpdateBuilder[T] {
+	ub.tags = append(ub.tags, tags...)
+	return ub
+}
+
+// Tag labels the delete with tags, see QueryBuilder.Tag
+func (db *DeleteBuilder[T]) Tag(tags ...string) *DeleteBuilder[T] {
+	db.tags = append(db.tags, tags...)
+	return db
+}
+
+// Tag labels the raw query with tags, see QueryBuilder.Tag
+func (rq *RawQuery[T]) Tag(tags ...string) *RawQuery[T] {
+	rq.tags = append(rq.tags, tags...)
+	return rq
+}
+
+// tagComment returns the SQL comment carrying tags, with the sequences that
+// would end the comment or the line broken up
+func tagComment(tags []string) string {
+	text := strings.Join(tags, ", ")
+	text = strings.NewReplacer("*/", "* /", "/*", "/ *", "\n", " ", "\r", " ").Replace(text)
+	return "/* " + text + " */ "
+}
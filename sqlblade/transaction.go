@@ -5,14 +5,208 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"sync"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
 )
 
+// Tx is a transaction started from a Client. Builders created from a Tx use
+// the dialect of the Client that started it.
+type Tx struct {
+	tx         *sql.Tx
+	client     *Client
+	savepoints int
+}
+
+// TxConn is implemented by the transaction handles accepted by the Tx builders
+type TxConn interface {
+	*sql.Tx | *Tx
+}
+
+// txClients remembers the client of transactions started by WithTransaction
+// and WithTransactionContext, so builders created from a plain *sql.Tx inside
+// the callback don't have to guess the dialect.
+var txClients sync.Map // map[*sql.Tx]*Client
+
+// resolveTx returns the underlying transaction and the client it belongs to.
+// A *sql.Tx not started by WithTransaction or WithTransactionContext gets the
+// dialect of plainTxDialect.
+func resolveTx[X TxConn](tx X) (*sql.Tx, *Client) {
+	switch t := any(tx).(type) {
+	case *Tx:
+		if t == nil || t.tx == nil {
+			panic(ErrNilDB)
+		}
+		return t.tx, t.client
+	case *sql.Tx:
+		if t == nil {
+			panic(ErrNilDB)
+		}
+		if c, ok := txClients.Load(t); ok {
+			if client, ok := c.(*Client); ok {
+				return t, client
+			}
+		}
+		return t, &Client{dialect: plainTxDialect()}
+	default:
+		panic(ErrNilDB)
+	}
+}
+
+var plainTxWarning sync.Once
+
+// plainTxDialect returns the dialect of a *sql.Tx not started by sqlblade,
+// whose database can't be told from the transaction: the dialect of the
+// databases opened with Open when they all share one, or PostgreSQL as
+// before, with a warning logged once
+func plainTxDialect() dialect.Dialect {
+	var found dialect.Dialect
+	dbDialects.Range(func(_, value interface{}) bool {
+		d, ok := value.(dialect.Dialect)
+		if !ok {
+			return true
+		}
+		if found != nil && found.Name() != d.Name() {
+			found = nil
+			return false
+		}
+		found = d
+		return true
+	})
+	if found != nil {
+		return found
+	}
+
+	plainTxWarning.Do(func() {
+		log.Printf("sqlblade: dialect of a *sql.Tx not started by WithTransaction is unknown, using PostgreSQL; start transactions with Client.Begin to use the client's dialect")
+	})
+	return detectDialect(nil)
+}
+
+// Begin starts a new transaction
+func (c *Client) Begin(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+	tx, err := c.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: tx, client: c}, nil
+}
+
+// WithTransaction executes a function within a transaction, committing on
+// success and rolling back on error or panic
+func (c *Client) WithTransaction(ctx context.Context, fn func(*Tx) error) (err error) {
+	tx, err := c.Begin(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("transaction rollback failed: %v", rollbackErr)
+			}
+			panic(p)
+		} else if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				err = fmt.Errorf("transaction rollback failed: %w (original error: %w)", rbErr, err)
+			}
+		} else {
+			if commitErr := tx.Commit(); commitErr != nil {
+				err = fmt.Errorf("%w: %w", ErrTransactionCommit, commitErr)
+			}
+		}
+	}()
+
+	err = fn(tx)
+	return err
+}
+
+// Tx returns the underlying transaction
+func (t *Tx) Tx() *sql.Tx {
+	return t.tx
+}
+
+// Client returns the client that started the transaction
+func (t *Tx) Client() *Client {
+	return t.client
+}
+
+// Commit commits the transaction
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback aborts the transaction
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// Savepoint creates a savepoint with the given name
+func (t *Tx) Savepoint(ctx context.Context, name string) error {
+	return t.exec(ctx, "SAVEPOINT "+t.client.dialect.QuoteIdentifier(name))
+}
+
+// RollbackTo rolls the transaction back to the given savepoint
+func (t *Tx) RollbackTo(ctx context.Context, name string) error {
+	return t.exec(ctx, "ROLLBACK TO SAVEPOINT "+t.client.dialect.QuoteIdentifier(name))
+}
+
+// Release releases the given savepoint
+func (t *Tx) Release(ctx context.Context, name string) error {
+	return t.exec(ctx, "RELEASE SAVEPOINT "+t.client.dialect.QuoteIdentifier(name))
+}
+
+// WithSavepoint executes a function inside a savepoint, rolling back to it
+// on error or panic without aborting the surrounding transaction
+func (t *Tx) WithSavepoint(ctx context.Context, fn func(*Tx) error) (err error) {
+	t.savepoints++
+	name := fmt.Sprintf("sqlblade_sp_%d", t.savepoints)
+
+	if err = t.Savepoint(ctx, name); err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			if rollbackErr := t.RollbackTo(ctx, name); rollbackErr != nil {
+				log.Printf("savepoint rollback failed: %v", rollbackErr)
+			}
+			panic(p)
+		} else if err != nil {
+			if rbErr := t.RollbackTo(ctx, name); rbErr != nil {
+				err = fmt.Errorf("savepoint rollback failed: %w (original error: %w)", rbErr, err)
+			}
+		} else {
+			err = t.Release(ctx, name)
+		}
+	}()
+
+	err = fn(t)
+	return err
+}
+
+func (t *Tx) exec(ctx context.Context, sqlStr string) error {
+	if ctx == nil {
+		return ErrNilContext
+	}
+	if _, err := t.tx.ExecContext(ctx, sqlStr); err != nil {
+		return wrapQueryError(err, sqlStr, nil)
+	}
+	return nil
+}
+
 // WithTransaction executes a function within a database transaction
 func WithTransaction(db *sql.DB, fn func(*sql.Tx) error) error {
 	tx, err := db.Begin()
 	if err != nil {
 		return err
 	}
+	txClients.Store(tx, &Client{db: db, dialect: dialectFor(db)})
+	// Forgets the transaction after its commit or rollback, also on panic
+	defer txClients.Delete(tx)
 
 	defer func() {
 		if p := recover(); p != nil {
@@ -43,6 +237,9 @@ func WithTransactionContext(ctx context.Context, db *sql.DB, fn func(*sql.Tx) er
 	if err != nil {
 		return err
 	}
+	txClients.Store(tx, &Client{db: db, dialect: dialectFor(db)})
+	// Forgets the transaction after its commit or rollback, also on panic
+	defer txClients.Delete(tx)
 
 	defer func() {
 		if p := recover(); p != nil {
@@ -0,0 +1,52 @@
+package sqlblade
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// withDBDialects replaces the remembered dialects of databases for the test
+func withDBDialects(t *testing.T, dialects ...dialect.Dialect) {
+	t.Helper()
+	saved := map[interface{}]interface{}{}
+	dbDialects.Range(func(key, value interface{}) bool {
+		saved[key] = value
+		dbDialects.Delete(key)
+		return true
+	})
+	for _, d := range dialects {
+		dbDialects.Store(new(sql.DB), d)
+	}
+	t.Cleanup(func() {
+		dbDialects.Range(func(key, _ interface{}) bool {
+			dbDialects.Delete(key)
+			return true
+		})
+		for key, value := range saved {
+			dbDialects.Store(key, value)
+		}
+	})
+}
+
+func TestPlainTxDialect(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialects []dialect.Dialect
+		want     string
+	}{
+		{"none opened", nil, dialectPostgres},
+		{"one dialect", []dialect.Dialect{dialect.NewMySQL()}, dialectMySQL},
+		{"shared dialect", []dialect.Dialect{dialect.NewSQLite(), dialect.NewSQLite()}, dialectSQLite},
+		{"mixed dialects", []dialect.Dialect{dialect.NewMySQL(), dialect.NewSQLite()}, dialectPostgres},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withDBDialects(t, tt.dialects...)
+			if got := plainTxDialect().Name(); got != tt.want {
+				t.Errorf("plainTxDialect() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
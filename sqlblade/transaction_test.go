@@ -0,0 +1,191 @@
+package sqlblade_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicanli1995/sqlblade/sqlblade"
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+type txUser struct {
+	ID   int    `db:"id"`
+	Name string `db:"name"`
+}
+
+func (txUser) TableName() string { return "users" }
+
+func TestTxBuildersUseClientDialect(t *testing.T) {
+	tests := []struct {
+		dialect   dialect.Dialect
+		where     string
+		savepoint string
+		release   string
+	}{
+		{dialect.NewMySQL(), "WHERE `id` = ?", "SAVEPOINT `sqlblade_sp_1`", "RELEASE SAVEPOINT `sqlblade_sp_1`"},
+		{dialect.NewPostgreSQL(), `WHERE "id" = $1`, `SAVEPOINT "sqlblade_sp_1"`, `RELEASE SAVEPOINT "sqlblade_sp_1"`},
+		{dialect.NewSQLite(), `WHERE "id" = ?`, `SAVEPOINT "sqlblade_sp_1"`, `RELEASE SAVEPOINT "sqlblade_sp_1"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name(), func(t *testing.T) {
+			ctx := context.Background()
+			db, rec := openRecorder(t)
+			client := sqlblade.Open(db, sqlblade.WithDialect(tt.dialect))
+
+			err := client.WithTransaction(ctx, func(tx *sqlblade.Tx) error {
+				if _, err := sqlblade.QueryTx[txUser](tx).Where("id", "=", 1).Execute(ctx); err != nil {
+					return err
+				}
+				return tx.WithSavepoint(ctx, func(tx *sqlblade.Tx) error {
+					_, err := sqlblade.DeleteTx[txUser](tx).Where("id", "=", 1).Execute(ctx)
+					return err
+				})
+			})
+			if err != nil {
+				t.Fatalf("WithTransaction: %v", err)
+			}
+
+			statements := rec.statements()
+			if len(statements) != 4 {
+				t.Fatalf("expected 4 statements, got %d: %q", len(statements), statements)
+			}
+			if !strings.HasPrefix(statements[0], "SELECT") || !strings.Contains(statements[0], tt.where) {
+				t.Errorf("SELECT = %q, want it to contain %q", statements[0], tt.where)
+			}
+			if statements[1] != tt.savepoint {
+				t.Errorf("savepoint = %q, want %q", statements[1], tt.savepoint)
+			}
+			if !strings.HasPrefix(statements[2], "DELETE") || !strings.Contains(statements[2], tt.where) {
+				t.Errorf("DELETE = %q, want it to contain %q", statements[2], tt.where)
+			}
+			if statements[3] != tt.release {
+				t.Errorf("release = %q, want %q", statements[3], tt.release)
+			}
+		})
+	}
+}
+
+func TestPlainTxUsesConfiguredDialect(t *testing.T) {
+	ctx := context.Background()
+	db, rec := openRecorder(t)
+	sqlblade.Open(db, sqlblade.WithDialect(dialect.NewMySQL()))
+
+	err := sqlblade.WithTransaction(db, func(tx *sql.Tx) error {
+		_, err := sqlblade.QueryTx[txUser](tx).Where("id", "=", 1).Execute(ctx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTransaction: %v", err)
+	}
+
+	statements := rec.statements()
+	if len(statements) != 1 || !strings.Contains(statements[0], "WHERE `id` = ?") {
+		t.Errorf("statements = %q, want a MySQL SELECT", statements)
+	}
+}
+
+func TestPlainTxExecutes(t *testing.T) {
+	ctx := context.Background()
+	db, rec := openRecorder(t)
+	sqlblade.Open(db, sqlblade.WithDialect(dialect.NewMySQL()))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := sqlblade.QueryTx[txUser](tx).Where("id", "=", 1).Execute(ctx); err != nil {
+		t.Errorf("QueryTx: %v", err)
+	}
+	if _, err := sqlblade.DeleteTx[txUser](tx).Where("id", "=", 1).Execute(ctx); err != nil {
+		t.Errorf("DeleteTx: %v", err)
+	}
+	if statements := rec.statements(); len(statements) != 2 {
+		t.Errorf("statements = %q, want 2", statements)
+	}
+}
+
+// recorder is a database/sql driver that records the statements it is
+// given, returns no rows and reports one affected row
+type recorder struct {
+	mu   sync.Mutex
+	sqls []string
+}
+
+func openRecorder(t *testing.T) (*sql.DB, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	db := sql.OpenDB(rec)
+	t.Cleanup(func() { db.Close() })
+	return db, rec
+}
+
+func (r *recorder) record(query string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sqls = append(r.sqls, query)
+}
+
+func (r *recorder) statements() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.sqls...)
+}
+
+func (r *recorder) Connect(context.Context) (driver.Conn, error) { return &recordConn{r}, nil }
+func (r *recorder) Driver() driver.Driver                        { return recordDriver{r} }
+
+type recordDriver struct{ r *recorder }
+
+func (d recordDriver) Open(string) (driver.Conn, error) { return &recordConn{d.r}, nil }
+
+type recordConn struct{ r *recorder }
+
+func (c *recordConn) Prepare(query string) (driver.Stmt, error) { return &recordStmt{c.r, query}, nil }
+func (c *recordConn) Close() error                              { return nil }
+func (c *recordConn) Begin() (driver.Tx, error)                 { return recordTx{}, nil }
+
+func (c *recordConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.r.record(query)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *recordConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.r.record(query)
+	return recordRows{}, nil
+}
+
+type recordStmt struct {
+	r     *recorder
+	query string
+}
+
+func (s *recordStmt) Close() error  { return nil }
+func (s *recordStmt) NumInput() int { return -1 }
+
+func (s *recordStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.r.record(s.query)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *recordStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.r.record(s.query)
+	return recordRows{}, nil
+}
+
+type recordTx struct{}
+
+func (recordTx) Commit() error   { return nil }
+func (recordTx) Rollback() error { return nil }
+
+type recordRows struct{}
+
+func (recordRows) Columns() []string         { return []string{"id", "name"} }
+func (recordRows) Close() error              { return nil }
+func (recordRows) Next([]driver.Value) error { return io.EOF }
@@ -3,10 +3,10 @@ package sqlblade
 import (
 	"context"
 	"database/sql"
-	"log"
+	"fmt"
 	"reflect"
+	"sort"
 	"strings"
-	"time"
 
 	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
 )
@@ -15,20 +15,22 @@ import (
 type UpdateBuilder[T any] struct {
 	db           *sql.DB
 	tx           *sql.Tx
+	client       *Client
 	dialect      dialect.Dialect
 	tableName    string
 	sets         map[string]interface{}
 	whereClauses []WhereClause
+	joins        []dialect.Join
 	returning    []string
+	err          error
+	strict       bool
+	allowFull    bool
+	tags         []string
 }
 
 // Update creates a new UPDATE builder
-func Update[T any](db *sql.DB) *UpdateBuilder[T] {
-	if db == nil {
-		panic(ErrNilDB)
-	}
-
-	d := detectDialect(db.Driver())
+func Update[T any, C Conn](db C) *UpdateBuilder[T] {
+	client := resolveConn(db)
 	var zero T
 	typ := reflect.TypeOf(zero)
 	if typ.Kind() == reflect.Ptr {
@@ -43,8 +45,9 @@ func Update[T any](db *sql.DB) *UpdateBuilder[T] {
 	}
 
 	return &UpdateBuilder[T]{
-		db:           db,
-		dialect:      d,
+		db:           client.db,
+		client:       client,
+		dialect:      withColumnAliases(client.dialect, typ, info.tableName),
 		tableName:    info.tableName,
 		sets:         make(map[string]interface{}),
 		whereClauses: make([]WhereClause, 0),
@@ -53,12 +56,8 @@ func Update[T any](db *sql.DB) *UpdateBuilder[T] {
 }
 
 // UpdateTx creates a new UPDATE builder with transaction
-func UpdateTx[T any](tx *sql.Tx) *UpdateBuilder[T] {
-	if tx == nil {
-		panic(ErrNilDB)
-	}
-
-	d := detectDialect(nil)
+func UpdateTx[T any, X TxConn](tx X) *UpdateBuilder[T] {
+	sqlTx, client := resolveTx(tx)
 	var zero T
 	typ := reflect.TypeOf(zero)
 	if typ.Kind() == reflect.Ptr {
@@ -73,8 +72,9 @@ func UpdateTx[T any](tx *sql.Tx) *UpdateBuilder[T] {
 	}
 
 	return &UpdateBuilder[T]{
-		tx:           tx,
-		dialect:      d,
+		tx:           sqlTx,
+		client:       client,
+		dialect:      withColumnAliases(client.dialect, typ, info.tableName),
 		tableName:    info.tableName,
 		sets:         make(map[string]interface{}),
 		whereClauses: make([]WhereClause, 0),
@@ -82,12 +82,96 @@ func UpdateTx[T any](tx *sql.Tx) *UpdateBuilder[T] {
 	}
 }
 
-// Set sets a column value
+// Set sets a column value, or an Expr computing it, e.g.
+// Set("views", sqlblade.Expr("views + ?", 1))
 func (ub *UpdateBuilder[T]) Set(column string, value interface{}) *UpdateBuilder[T] {
 	ub.sets[column] = value
 	return ub
 }
 
+// SetExpr sets a column to a raw SQL expression with "?" placeholders, e.g.
+// SetExpr("slug", "lower(?)", title)
+func (ub *UpdateBuilder[T]) SetExpr(column, expr string, args ...interface{}) *UpdateBuilder[T] {
+	return ub.Set(column, Expr(expr, args...))
+}
+
+// Increment adds by to a column in the database, e.g. Increment("views", 1)
+// sets views = views + 1, without reading the row first
+func (ub *UpdateBuilder[T]) Increment(column string, by interface{}) *UpdateBuilder[T] {
+	return ub.Set(column, Expr(ub.dialect.QuoteIdentifier(column)+" + ?", by))
+}
+
+// Decrement subtracts by from a column in the database
+func (ub *UpdateBuilder[T]) Decrement(column string, by interface{}) *UpdateBuilder[T] {
+	return ub.Set(column, Expr(ub.dialect.QuoteIdentifier(column)+" - ?", by))
+}
+
+// SetModel sets columns from the fields of value. Without columns every
+// column except the primary key is set; otherwise only the given columns.
+// Unset Omit fields are left out.
+func (ub *UpdateBuilder[T]) SetModel(value T, columns ...string) *UpdateBuilder[T] {
+	return ub.setModel(value, false, columns)
+}
+
+// SetModelNonZero is like SetModel but also leaves out zero-valued fields,
+// e.g. to apply a partially filled struct
+func (ub *UpdateBuilder[T]) SetModelNonZero(value T, columns ...string) *UpdateBuilder[T] {
+	return ub.setModel(value, true, columns)
+}
+
+// setModel adds the SET values of a model's fields
+func (ub *UpdateBuilder[T]) setModel(value T, skipZero bool, columns []string) *UpdateBuilder[T] {
+	val := reflect.ValueOf(value)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			ub.err = ErrInvalidModel
+			return ub
+		}
+		val = val.Elem()
+	}
+
+	info, err := getStructInfo(val.Type())
+	if err != nil {
+		ub.err = err
+		return ub
+	}
+	meta, err := metadataOf(val.Type())
+	if err != nil {
+		ub.err = err
+		return ub
+	}
+
+	fields := make(map[string]fieldInfo, len(info.fields))
+	for _, field := range info.fields {
+		fields[field.dbColumn] = field
+	}
+
+	selected := info.fields
+	if len(columns) > 0 {
+		selected = make([]fieldInfo, 0, len(columns))
+		for _, col := range columns {
+			field, ok := fields[strings.ToLower(col)]
+			if !ok {
+				ub.err = fmt.Errorf("%w: %s", ErrInvalidColumn, col)
+				return ub
+			}
+			selected = append(selected, field)
+		}
+	}
+
+	for _, field := range selected {
+		if len(columns) == 0 && meta.IsPrimaryKey(field.dbColumn) {
+			continue
+		}
+		fieldVal := field.value(val)
+		if !fieldVal.IsValid() || skipZero && fieldVal.IsZero() {
+			continue
+		}
+		ub.sets[field.dbColumn] = fieldArg(field, fieldVal)
+	}
+	return ub
+}
+
 // Where adds a WHERE condition
 func (ub *UpdateBuilder[T]) Where(column string, operator string, value interface{}) *UpdateBuilder[T] {
 	ub.whereClauses = append(ub.whereClauses, WhereClause{
@@ -99,7 +183,52 @@ func (ub *UpdateBuilder[T]) Where(column string, operator string, value interfac
 	return ub
 }
 
-// Returning specifies columns to return (PostgreSQL)
+// OrWhere adds a WHERE condition (OR)
+func (ub *UpdateBuilder[T]) OrWhere(column string, operator string, value interface{}) *UpdateBuilder[T] {
+	ub.whereClauses = append(ub.whereClauses, WhereClause{
+		Column:   column,
+		Operator: operator,
+		Value:    value,
+		And:      false,
+	})
+	return ub
+}
+
+// WhereIn adds a WHERE column IN (...) condition. An empty list matches no rows.
+func (ub *UpdateBuilder[T]) WhereIn(column string, values ...interface{}) *UpdateBuilder[T] {
+	return ub.Where(column, "IN", values)
+}
+
+// OrWhereIn adds a WHERE column IN (...) condition (OR)
+func (ub *UpdateBuilder[T]) OrWhereIn(column string, values ...interface{}) *UpdateBuilder[T] {
+	return ub.OrWhere(column, "IN", values)
+}
+
+// WhereNotIn adds a WHERE column NOT IN (...) condition. An empty list matches all rows.
+func (ub *UpdateBuilder[T]) WhereNotIn(column string, values ...interface{}) *UpdateBuilder[T] {
+	return ub.Where(column, "NOT IN", values)
+}
+
+// UpdateWhereInSlice adds a WHERE column IN (...) condition for a typed
+// slice, e.g. UpdateWhereInSlice(sqlblade.Update[User](db), "id", ids) with
+// ids of type []int64
+func UpdateWhereInSlice[T any, V comparable](ub *UpdateBuilder[T], column string, values []V) *UpdateBuilder[T] {
+	return ub.Where(column, "IN", toInterfaces(values))
+}
+
+// UpdateWhereNotInSlice adds a WHERE column NOT IN (...) condition for a
+// typed slice
+func UpdateWhereNotInSlice[T any, V comparable](ub *UpdateBuilder[T], column string, values []V) *UpdateBuilder[T] {
+	return ub.Where(column, "NOT IN", toInterfaces(values))
+}
+
+// WhereSubquery adds a WHERE condition using a subquery, e.g.
+// WhereSubquery("id", "IN", sqlblade.NewSubquery(...))
+func (ub *UpdateBuilder[T]) WhereSubquery(column string, operator string, subquery *Subquery) *UpdateBuilder[T] {
+	return ub.Where(column, operator, subquery)
+}
+
+// Returning specifies columns to return (PostgreSQL, SQLite)
 func (ub *UpdateBuilder[T]) Returning(columns ...string) *UpdateBuilder[T] {
 	ub.returning = columns
 	return ub
@@ -111,98 +240,171 @@ func (ub *UpdateBuilder[T]) Execute(ctx context.Context) (sql.Result, error) {
 		return nil, ErrNilContext
 	}
 
-	if len(ub.sets) == 0 {
-		return nil, ErrEmptySet
-	}
-
-	var buf strings.Builder
-	buf.Grow(updateBufferSize)
-	paramIndex := 0
-	args := make([]interface{}, 0, len(ub.sets)+len(ub.whereClauses))
-
-	buf.WriteString("UPDATE ")
-	buf.WriteString(ub.dialect.QuoteIdentifier(ub.tableName))
-	buf.WriteString(" SET ")
-
-	setParts := make([]string, 0, len(ub.sets))
-	for col, val := range ub.sets {
-		paramIndex++
-		setParts = append(setParts, ub.dialect.QuoteIdentifier(col)+" = "+ub.dialect.Placeholder(paramIndex))
-		args = append(args, val)
-	}
-	buf.WriteString(strings.Join(setParts, ", "))
-
-	whereSQL, whereArgs := buildWhereClause(ub.dialect, ub.whereClauses, &paramIndex)
-	if whereSQL != "" {
-		buf.WriteString(" ")
-		buf.WriteString(whereSQL)
-		args = append(args, whereArgs...)
+	sqlStr, args, err := ub.buildSQL(ub.returning)
+	if err != nil {
+		return nil, err
 	}
-
-	if len(ub.returning) > 0 && ub.dialect.Name() == dialectPostgres {
-		buf.WriteString(" RETURNING ")
-		returningCols := make([]string, len(ub.returning))
-		for i, col := range ub.returning {
-			returningCols[i] = ub.dialect.QuoteIdentifier(col)
-		}
-		buf.WriteString(strings.Join(returningCols, ", "))
+	if err := ub.checkFullTable(); err != nil {
+		return nil, err
 	}
 
-	sqlStr := buf.String()
-	startTime := time.Now()
+	stmt := &Statement{Operation: "UPDATE", Table: ub.tableName, SQL: sqlStr, Args: args, Primary: true, Tags: ub.tags}
+	event := ub.client.newHookEvent(stmt)
+	startTime := event.StartTime
 
-	if err := DefaultHooks.ExecuteBeforeHooks(ctx, sqlStr, args); err != nil {
+	if err := ub.client.executeBeforeHooks(ctx, event); err != nil {
 		return nil, err
 	}
 
 	var result sql.Result
-	var err error
 
-	if globalDebugger.enabled {
+	if debugger := ub.client.queryDebugger(); debugger.enabled.Load() {
 		debugQuery := &DebugQuery{
 			SQL:       sqlStr,
 			Args:      args,
+			Tags:      stmt.Tags,
 			Table:     ub.tableName,
 			Operation: "UPDATE",
 			Timestamp: startTime,
 		}
 		defer func() {
-			debugQuery.Duration = time.Since(startTime)
+			debugQuery.Duration = ub.client.since(startTime)
 			if result != nil {
 				rowsAffected, err := result.RowsAffected()
 				if err == nil {
 					debugQuery.RowsAffected = rowsAffected
 				}
 			}
-			globalDebugger.Log(debugQuery)
+			debugger.Log(debugQuery)
 		}()
 	}
 
-	if ub.tx == nil && globalStmtCache != nil && globalStmtCache.db == ub.db {
-		stmt, stmtErr := globalStmtCache.getStmt(ctx, sqlStr)
-		if stmtErr == nil {
-			result, err = stmt.ExecContext(ctx, args...)
-			if err == nil {
-				return result, nil
-			}
-			return nil, wrapQueryError(err, sqlStr, args)
+	err = ub.client.execute(ctx, stmt, func(ctx context.Context) error {
+		var execErr error
+		result, execErr = ub.client.execContext(ctx, ub.tx, true, stmt.SQL, stmt.Args)
+		if execErr != nil {
+			return wrapQueryError(execErr, stmt.SQL, stmt.Args)
 		}
-		return nil, wrapQueryError(stmtErr, sqlStr, args)
+		return nil
+	})
+
+	event.finish(err, result)
+	ub.client.executeAfterHooks(ctx, event)
+	if err != nil {
+		return nil, err
 	}
 
-	if ub.tx != nil {
-		result, err = ub.tx.ExecContext(ctx, sqlStr, args...)
-	} else {
-		result, err = ub.db.ExecContext(ctx, sqlStr, args...)
+	return result, nil
+}
+
+// ExecuteReturning executes the UPDATE statement and scans the updated rows
+// returned by its RETURNING clause into T. Without Returning, all columns are
+// returned. Only dialects supporting RETURNING (PostgreSQL, SQLite) are allowed.
+func (ub *UpdateBuilder[T]) ExecuteReturning(ctx context.Context) ([]T, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+	if !supportsReturning(ub.dialect) {
+		return nil, ErrReturningUnsupported
 	}
 
+	sqlStr, args, err := ub.buildSQL(returningColumns(ub.returning))
 	if err != nil {
-		return nil, wrapQueryError(err, sqlStr, args)
+		return nil, err
+	}
+	if err := ub.checkFullTable(); err != nil {
+		return nil, err
 	}
 
-	if hookErr := DefaultHooks.ExecuteAfterHooks(ctx, sqlStr, args); hookErr != nil {
-		log.Printf("after query hook error: %v", hookErr)
+	stmt := &Statement{Operation: "UPDATE", Table: ub.tableName, SQL: sqlStr, Args: args, Primary: true, Tags: ub.tags}
+	return executeReturning[T](ctx, ub.client, ub.tx, stmt)
+}
+
+// buildSQL builds the UPDATE statement with the given RETURNING columns
+func (ub *UpdateBuilder[T]) buildSQL(returning []string) (string, []interface{}, error) {
+	if ub.err != nil {
+		return "", nil, ub.err
+	}
+	if len(ub.sets) == 0 {
+		return "", nil, ErrEmptySet
+	}
+	if err := ub.validate(); err != nil {
+		return "", nil, err
 	}
 
-	return result, nil
+	var buf strings.Builder
+	buf.Grow(updateBufferSize)
+	paramIndex := 0
+	args := make([]interface{}, 0, len(ub.sets)+len(ub.whereClauses))
+
+	// MySQL joins the other tables before SET, the others list them in FROM
+	// and match them in WHERE
+	joinInSet := len(ub.joins) > 0 && ub.dialect.Name() == dialectMySQL
+
+	buf.WriteString("UPDATE ")
+	buf.WriteString(ub.dialect.QuoteIdentifier(ub.tableName))
+	if joinInSet {
+		for _, join := range ub.joins {
+			buf.WriteString(" ")
+			buf.WriteString(ub.dialect.BuildJoin(join))
+		}
+	}
+	buf.WriteString(" SET ")
+
+	// Columns are sorted so that the same update always produces the same SQL
+	columns := make([]string, 0, len(ub.sets))
+	for col := range ub.sets {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+
+	info, err := getStructInfo(modelType[T]())
+	if err != nil {
+		info = &structInfo{tableName: ub.tableName}
+	}
+
+	setParts := make([]string, 0, len(ub.sets))
+	for _, col := range columns {
+		val := ub.sets[col]
+		if isOmitted(val) {
+			continue
+		}
+		target := ub.dialect.QuoteIdentifier(col)
+		if joinInSet && !strings.Contains(col, ".") {
+			// Columns of the joined tables could make col ambiguous
+			target = ub.dialect.QuoteIdentifier(ub.tableName + "." + col)
+		}
+		if expr, ok := val.(Expression); ok {
+			setParts = append(setParts, target+" = "+rebindPlaceholders(ub.dialect, expr.sql, &paramIndex))
+			args = append(args, expr.args...)
+			continue
+		}
+		if err := ub.client.checkDecimal(info, strings.ToLower(col), val); err != nil {
+			return "", nil, err
+		}
+		val = codecArg(info, strings.ToLower(col), val)
+		paramIndex++
+		setParts = append(setParts, target+" = "+placeholder(ub.dialect, paramIndex, val))
+		args = append(args, val)
+	}
+	if len(setParts) == 0 {
+		return "", nil, ErrEmptySet
+	}
+	buf.WriteString(strings.Join(setParts, ", "))
+
+	whereClauses := ub.whereClauses
+	if len(ub.joins) > 0 && !joinInSet {
+		whereClauses = ub.fromJoins(&buf)
+	}
+
+	whereSQL, whereArgs := buildWhereClause(ub.dialect, whereClauses, &paramIndex)
+	if whereSQL != "" {
+		buf.WriteString(" ")
+		buf.WriteString(whereSQL)
+		args = append(args, whereArgs...)
+	}
+
+	writeReturning(&buf, ub.dialect, returning)
+
+	return buf.String(), args, nil
 }
@@ -1,6 +1,7 @@
 package sqlblade
 
 import (
+	"reflect"
 	"strings"
 
 	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
@@ -14,6 +15,15 @@ type WhereClause struct {
 	And      bool // true = AND, false = OR
 }
 
+// ColumnRef marks a condition value as a column reference rather than a bind value
+type ColumnRef string
+
+// Cor references a column of an outer query from inside a correlated subquery,
+// e.g. Where("orders.user_id", "=", Cor("u.id"))
+func Cor(column string) ColumnRef {
+	return ColumnRef(column)
+}
+
 // Valid operators for WHERE clauses
 var validOperators = map[string]bool{
 	"=":           true,
@@ -42,57 +52,24 @@ func isValidOperator(op string) bool {
 
 // buildWhereClause builds WHERE clause SQL
 func buildWhereClause(d dialect.Dialect, clauses []WhereClause, paramIndex *int) (string, []interface{}) {
-	if len(clauses) == 0 {
+	var args []interface{}
+	conditions := buildConditions(d, clauses, paramIndex, &args)
+	if conditions == "" {
 		return "", nil
 	}
 
-	var parts []string
-	var args []interface{}
+	return "WHERE " + conditions, args
+}
 
-	for i, clause := range clauses {
-		var condition string
-		op := strings.ToUpper(strings.TrimSpace(clause.Operator))
+// buildConditions joins the conditions of the clauses with their connectors
+func buildConditions(d dialect.Dialect, clauses []WhereClause, paramIndex *int, args *[]interface{}) string {
+	var parts []string
 
-		if !isValidOperator(op) {
-			continue
-		}
-
-		switch op {
-		case "IS NULL", "IS NOT NULL":
-			condition = d.QuoteIdentifier(clause.Column) + " " + op
-		case "IN", "NOT IN":
-			if values, ok := clause.Value.([]interface{}); ok && len(values) > 0 {
-				placeholders := make([]string, len(values))
-				for j := range values {
-					*paramIndex++
-					placeholders[j] = d.Placeholder(*paramIndex)
-					args = append(args, values[j])
-				}
-				condition = d.QuoteIdentifier(clause.Column) + " " + op + " (" + strings.Join(placeholders, ", ") + ")"
-			}
-		case "BETWEEN", "NOT BETWEEN":
-			if values, ok := clause.Value.([]interface{}); ok && len(values) == 2 {
-				*paramIndex++
-				ph1 := d.Placeholder(*paramIndex)
-				*paramIndex++
-				ph2 := d.Placeholder(*paramIndex)
-				condition = d.QuoteIdentifier(clause.Column) + " " + op + " " + ph1 + " AND " + ph2
-				args = append(args, values[0], values[1])
-			}
-		default:
-			// Check if value is a subquery
-			if subquery, ok := clause.Value.(*Subquery); ok {
-				condition = d.QuoteIdentifier(clause.Column) + " " + op + " " + subquery.SQL()
-				args = append(args, subquery.Args()...)
-			} else {
-				*paramIndex++
-				condition = d.QuoteIdentifier(clause.Column) + " " + op + " " + d.Placeholder(*paramIndex)
-				args = append(args, clause.Value)
-			}
-		}
+	for _, clause := range clauses {
+		condition := buildCondition(d, clause, paramIndex, args)
 
 		if condition != "" {
-			if i > 0 {
+			if len(parts) > 0 {
 				if clause.And {
 					parts = append(parts, "AND")
 				} else {
@@ -103,9 +80,184 @@ func buildWhereClause(d dialect.Dialect, clauses []WhereClause, paramIndex *int)
 		}
 	}
 
-	if len(parts) == 0 {
-		return "", nil
+	return strings.Join(parts, " ")
+}
+
+// inValues returns the values of an IN list given as []interface{} or as any
+// other slice type, e.g. []int64 or []string. []byte is a single value.
+func inValues(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, true
+	case []byte, nil:
+		return nil, false
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	values := make([]interface{}, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values, true
+}
+
+// buildCondition builds the SQL of a single condition, appending its arguments
+func buildCondition(d dialect.Dialect, clause WhereClause, paramIndex *int, args *[]interface{}) string {
+	if group, ok := clause.Value.(*ConditionGroup); ok {
+		conditions := buildConditions(d, group.clauses, paramIndex, args)
+		switch {
+		case conditions == "" && group.not:
+			// An empty group matches all rows, so its negation none
+			return "FALSE"
+		case conditions == "":
+			return ""
+		case group.not:
+			return "NOT (" + conditions + ")"
+		}
+		return "(" + conditions + ")"
+	}
+
+	if raw, ok := clause.Value.(rawExpr); ok {
+		condition := "(" + rebindPlaceholders(d, raw.sql, paramIndex) + ")"
+		*args = append(*args, raw.args...)
+		return condition
+	}
+
+	op := strings.ToUpper(strings.TrimSpace(clause.Operator))
+	if !isValidOperator(op) {
+		return ""
+	}
+
+	var condition string
+	switch op {
+	case "IS NULL", "IS NOT NULL":
+		condition = d.QuoteIdentifier(clause.Column) + " " + op
+	case "IN", "NOT IN":
+		if values, ok := inValues(clause.Value); ok {
+			if len(values) == 0 {
+				// An empty list matches no rows, so NOT IN matches all of them
+				if op == "IN" {
+					return "FALSE"
+				}
+				return "TRUE"
+			}
+			values = normalizeInList(d, values)
+			placeholders := make([]string, len(values))
+			for j := range values {
+				*paramIndex++
+				placeholders[j] = d.Placeholder(*paramIndex)
+				*args = append(*args, values[j])
+			}
+			condition = d.QuoteIdentifier(clause.Column) + " " + op + " (" + strings.Join(placeholders, ", ") + ")"
+		} else if subquery, ok := clause.Value.(*Subquery); ok {
+			condition = subqueryCondition(d, clause.Column, op, subquery, paramIndex, args)
+		} else if param, ok := clause.Value.(NamedParam); ok {
+			// Left unbound, failing with ErrUnboundParam before it's sent
+			*paramIndex++
+			condition = d.QuoteIdentifier(clause.Column) + " " + op + " (" + d.Placeholder(*paramIndex) + ")"
+			*args = append(*args, param)
+		}
+	case "BETWEEN", "NOT BETWEEN":
+		if values, ok := clause.Value.([]interface{}); ok && len(values) == 2 {
+			*paramIndex++
+			ph1 := d.Placeholder(*paramIndex)
+			*paramIndex++
+			ph2 := d.Placeholder(*paramIndex)
+			condition = d.QuoteIdentifier(clause.Column) + " " + op + " " + ph1 + " AND " + ph2
+			*args = append(*args, values[0], values[1])
+		}
+	default:
+		// Check if value is a column reference or a subquery
+		if ref, ok := clause.Value.(ColumnRef); ok {
+			condition = d.QuoteIdentifier(clause.Column) + " " + op + " " + d.QuoteIdentifier(string(ref))
+		} else if subquery, ok := clause.Value.(*Subquery); ok {
+			condition = subqueryCondition(d, clause.Column, op, subquery, paramIndex, args)
+		} else if expr, ok := clause.Value.(Expression); ok {
+			condition = d.QuoteIdentifier(clause.Column) + " " + op + " (" + rebindPlaceholders(d, expr.sql, paramIndex) + ")"
+			*args = append(*args, expr.args...)
+		} else {
+			*paramIndex++
+			condition = d.QuoteIdentifier(clause.Column) + " " + op + " " + placeholder(d, *paramIndex, clause.Value)
+			*args = append(*args, clause.Value)
+		}
+	}
+
+	return condition
+}
+
+// subqueryCondition builds a condition comparing a column with a subquery,
+// or an EXISTS condition without column, numbering the placeholders of the
+// subquery on from those of the statement
+func subqueryCondition(d dialect.Dialect, column, op string, subquery *Subquery, paramIndex *int, args *[]interface{}) string {
+	var buf strings.Builder
+	if column != "" {
+		buf.WriteString(d.QuoteIdentifier(column) + " ")
+	}
+	buf.WriteString(op + " ")
+	subquery.writeTo(&buf, paramIndex, args)
+	return buf.String()
+}
+
+// groupByExpr is a GROUP BY entry, either a column or a raw expression
+type groupByExpr struct {
+	expr string
+	raw  bool
+}
+
+// buildGroupBy builds the comma separated GROUP BY list
+func buildGroupBy(d dialect.Dialect, items []groupByExpr) string {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		if item.raw {
+			parts[i] = item.expr
+		} else {
+			parts[i] = d.QuoteIdentifier(item.expr)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// rawExpr is a raw SQL fragment with "?" placeholders and its bound arguments
+type rawExpr struct {
+	sql  string
+	args []interface{}
+}
+
+// rebindPlaceholders replaces "?" placeholders in a raw SQL fragment with the
+// dialect's placeholders, continuing the numbering from paramIndex. Question
+// marks inside quoted strings or identifiers are left alone, and "??" can be
+// used for a literal question mark (e.g. the PostgreSQL jsonb operator).
+func rebindPlaceholders(d dialect.Dialect, sqlStr string, paramIndex *int) string {
+	var buf strings.Builder
+	buf.Grow(len(sqlStr) + 8)
+
+	var quote byte
+	for i := 0; i < len(sqlStr); i++ {
+		c := sqlStr[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			buf.WriteByte(c)
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			buf.WriteByte(c)
+		case c == '?':
+			if i+1 < len(sqlStr) && sqlStr[i+1] == '?' {
+				buf.WriteByte('?')
+				i++
+				continue
+			}
+			*paramIndex++
+			buf.WriteString(d.Placeholder(*paramIndex))
+		default:
+			buf.WriteByte(c)
+		}
 	}
 
-	return "WHERE " + strings.Join(parts, " "), args
+	return buf.String()
 }
@@ -0,0 +1,132 @@
+package sqlblade
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/alicanli1995/sqlblade/sqlblade/dialect"
+)
+
+// Window is the window of a window function, written as its OVER clause
+type Window struct {
+	partitionBy []string
+	orderBy     []dialect.OrderBy
+	frame       string
+}
+
+// Over starts a window specification; without partitions or ordering the
+// window is the whole result
+func Over() *Window {
+	return &Window{}
+}
+
+// PartitionBy splits the rows into partitions by the columns
+func (w *Window) PartitionBy(columns ...string) *Window {
+	w.partitionBy = append(w.partitionBy, columns...)
+	return w
+}
+
+// OrderBy orders the rows of each partition
+func (w *Window) OrderBy(column string, order dialect.OrderDirection) *Window {
+	w.orderBy = append(w.orderBy, dialect.OrderBy{Column: column, Order: order})
+	return w
+}
+
+// Frame sets the frame clause, emitted verbatim, e.g.
+// Frame("ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW") for running sums
+func (w *Window) Frame(frame string) *Window {
+	w.frame = frame
+	return w
+}
+
+// sql returns the OVER clause of the window
+func (w *Window) sql(d dialect.Dialect) string {
+	var buf strings.Builder
+	buf.WriteString("OVER (")
+	if len(w.partitionBy) > 0 {
+		buf.WriteString("PARTITION BY ")
+		for i, col := range w.partitionBy {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(d.QuoteIdentifier(col))
+		}
+	}
+	if len(w.orderBy) > 0 {
+		if len(w.partitionBy) > 0 {
+			buf.WriteString(" ")
+		}
+		buf.WriteString(d.BuildOrderBy(w.orderBy))
+	}
+	if w.frame != "" {
+		if len(w.partitionBy) > 0 || len(w.orderBy) > 0 {
+			buf.WriteString(" ")
+		}
+		buf.WriteString(w.frame)
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+// windowExpr is a window function selected by a query
+type windowExpr struct {
+	fn    string
+	over  *Window
+	alias string
+}
+
+// RowNumber numbers the rows of each partition from 1
+func RowNumber() string {
+	return "ROW_NUMBER()"
+}
+
+// Rank ranks the rows of each partition, with gaps after ties
+func Rank() string {
+	return "RANK()"
+}
+
+// DenseRank ranks the rows of each partition, without gaps after ties
+func DenseRank() string {
+	return "DENSE_RANK()"
+}
+
+// Lag returns the value of the column offset rows before the current row
+func Lag(column string, offset int) string {
+	return "LAG(" + column + ", " + strconv.Itoa(offset) + ")"
+}
+
+// Lead returns the value of the column offset rows after the current row
+func Lead(column string, offset int) string {
+	return "LEAD(" + column + ", " + strconv.Itoa(offset) + ")"
+}
+
+// SelectWindow adds a window function to the selected columns, after the
+// columns given to Select (or *), under the alias:
+//
+//	sqlblade.Query[PostRank](db).
+//	    Select("id", "author_id", "title").
+//	    SelectWindow(sqlblade.RowNumber(), sqlblade.Over().
+//	        PartitionBy("author_id").
+//	        OrderBy("created_at", dialect.DESC), "rn")
+//
+// The function is emitted verbatim; running totals use aggregates with a
+// frame, e.g. SelectWindow("SUM(amount)", Over().OrderBy("id", dialect.ASC).
+// Frame("ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW"), "total").
+// Map the alias to a field of T to scan it. Filtering on the result needs an
+// outer query, as window functions can't appear in WHERE.
+func (qb *QueryBuilder[T]) SelectWindow(fn string, over *Window, alias string) *QueryBuilder[T] {
+	if over == nil {
+		over = Over()
+	}
+	qb.windows = append(qb.windows, windowExpr{fn: fn, over: over, alias: alias})
+	return qb
+}
+
+// windowSQL returns the selected window functions, separated by commas
+func (qb *QueryBuilder[T]) windowSQL() string {
+	parts := make([]string, len(qb.windows))
+	for i, win := range qb.windows {
+		parts[i] = win.fn + " " + win.over.sql(qb.dialect) + " AS " + qb.dialect.QuoteIdentifier(win.alias)
+	}
+	return strings.Join(parts, ", ")
+}